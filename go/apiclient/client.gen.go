@@ -0,0 +1,3349 @@
+// Package apiclient provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.1 DO NOT EDIT.
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oapi-codegen/runtime"
+)
+
+// Assets defines model for Assets.
+type Assets struct {
+	// Data Keyed by asset.
+	Data map[string]map[string]interface{} `json:"data"`
+}
+
+// Breadth defines model for Breadth.
+type Breadth struct {
+	// Breadth Latest breadth, null until the first has been calculated.
+	Breadth *struct {
+		Buckets *map[string]struct {
+			AboveVwapPct float32             `json:"above_vwap_pct"`
+			Advancers    int                 `json:"advancers"`
+			Decliners    int                 `json:"decliners"`
+			Histogram    *[]int              `json:"histogram"`
+			NetVolume    *map[string]float32 `json:"net_volume"`
+			NetVolumeUsd float32             `json:"net_volume_usd"`
+			Unchanged    int                 `json:"unchanged"`
+		} `json:"buckets"`
+		HistogramEdges *[]float32 `json:"histogram_edges"`
+		Symbols        int        `json:"symbols"`
+		Timestamp      time.Time  `json:"timestamp"`
+	} `json:"breadth"`
+
+	// History Recent breadth, oldest first, if requested.
+	History *[]struct {
+		Buckets *map[string]struct {
+			AboveVwapPct float32             `json:"above_vwap_pct"`
+			Advancers    int                 `json:"advancers"`
+			Decliners    int                 `json:"decliners"`
+			Histogram    *[]int              `json:"histogram"`
+			NetVolume    *map[string]float32 `json:"net_volume"`
+			NetVolumeUsd float32             `json:"net_volume_usd"`
+			Unchanged    int                 `json:"unchanged"`
+		} `json:"buckets"`
+		HistogramEdges *[]float32 `json:"histogram_edges"`
+		Symbols        int        `json:"symbols"`
+		Timestamp      time.Time  `json:"timestamp"`
+	} `json:"history,omitempty"`
+}
+
+// Entry defines model for Entry.
+type Entry struct {
+	// Ask Best ask price.
+	Ask float32 `json:"ask"`
+
+	// BetaBtc1 Beta of the USD returns against BTCUSDT. Over 1 minutes.
+	BetaBtc1 *float32 `json:"beta_btc_1,omitempty"`
+
+	// BetaBtc10 Beta of the USD returns against BTCUSDT. Over 10 minutes.
+	BetaBtc10 *float32 `json:"beta_btc_10,omitempty"`
+
+	// BetaBtc15 Beta of the USD returns against BTCUSDT. Over 15 minutes.
+	BetaBtc15 *float32 `json:"beta_btc_15,omitempty"`
+
+	// BetaBtc2 Beta of the USD returns against BTCUSDT. Over 2 minutes.
+	BetaBtc2 *float32 `json:"beta_btc_2,omitempty"`
+
+	// BetaBtc3 Beta of the USD returns against BTCUSDT. Over 3 minutes.
+	BetaBtc3 *float32 `json:"beta_btc_3,omitempty"`
+
+	// BetaBtc5 Beta of the USD returns against BTCUSDT. Over 5 minutes.
+	BetaBtc5 *float32 `json:"beta_btc_5,omitempty"`
+
+	// BetaBtc60 Beta of the USD returns against BTCUSDT. Over 60 minutes.
+	BetaBtc60 *float32 `json:"beta_btc_60,omitempty"`
+
+	// BetaMkt1 Beta of the USD returns against the market average. Over 1 minutes.
+	BetaMkt1 *float32 `json:"beta_mkt_1,omitempty"`
+
+	// BetaMkt10 Beta of the USD returns against the market average. Over 10 minutes.
+	BetaMkt10 *float32 `json:"beta_mkt_10,omitempty"`
+
+	// BetaMkt15 Beta of the USD returns against the market average. Over 15 minutes.
+	BetaMkt15 *float32 `json:"beta_mkt_15,omitempty"`
+
+	// BetaMkt2 Beta of the USD returns against the market average. Over 2 minutes.
+	BetaMkt2 *float32 `json:"beta_mkt_2,omitempty"`
+
+	// BetaMkt3 Beta of the USD returns against the market average. Over 3 minutes.
+	BetaMkt3 *float32 `json:"beta_mkt_3,omitempty"`
+
+	// BetaMkt5 Beta of the USD returns against the market average. Over 5 minutes.
+	BetaMkt5 *float32 `json:"beta_mkt_5,omitempty"`
+
+	// BetaMkt60 Beta of the USD returns against the market average. Over 60 minutes.
+	BetaMkt60 *float32 `json:"beta_mkt_60,omitempty"`
+
+	// Bid Best bid price.
+	Bid float32 `json:"bid"`
+
+	// BuyRatio24h Share of the trades that were buys. Over the last 24h.
+	BuyRatio24h *float32 `json:"buy_ratio_24h,omitempty"`
+
+	// BuyRatio2h Share of the trades that were buys. Over the last 2h.
+	BuyRatio2h *float32 `json:"buy_ratio_2h,omitempty"`
+
+	// BuyRatio4h Share of the trades that were buys. Over the last 4h.
+	BuyRatio4h *float32 `json:"buy_ratio_4h,omitempty"`
+
+	// Bv1 Buy volume in the quote asset. Over 1 minutes.
+	Bv1 *float32 `json:"bv_1,omitempty"`
+
+	// Bv10 Buy volume in the quote asset. Over 10 minutes.
+	Bv10 *float32 `json:"bv_10,omitempty"`
+
+	// Bv15 Buy volume in the quote asset. Over 15 minutes.
+	Bv15 *float32 `json:"bv_15,omitempty"`
+
+	// Bv2 Buy volume in the quote asset. Over 2 minutes.
+	Bv2 *float32 `json:"bv_2,omitempty"`
+
+	// Bv24h Buy volume in the quote asset. Over the last 24h.
+	Bv24h *float32 `json:"bv_24h,omitempty"`
+
+	// Bv2h Buy volume in the quote asset. Over the last 2h.
+	Bv2h *float32 `json:"bv_2h,omitempty"`
+
+	// Bv3 Buy volume in the quote asset. Over 3 minutes.
+	Bv3 *float32 `json:"bv_3,omitempty"`
+
+	// Bv4h Buy volume in the quote asset. Over the last 4h.
+	Bv4h *float32 `json:"bv_4h,omitempty"`
+
+	// Bv5 Buy volume in the quote asset. Over 5 minutes.
+	Bv5 *float32 `json:"bv_5,omitempty"`
+
+	// Bv60 Buy volume in the quote asset. Over 60 minutes.
+	Bv60 *float32 `json:"bv_60,omitempty"`
+
+	// BvBtc1 Buy volume in BTC. Over 1 minutes.
+	BvBtc1 *float32 `json:"bv_btc_1,omitempty"`
+
+	// BvBtc10 Buy volume in BTC. Over 10 minutes.
+	BvBtc10 *float32 `json:"bv_btc_10,omitempty"`
+
+	// BvBtc15 Buy volume in BTC. Over 15 minutes.
+	BvBtc15 *float32 `json:"bv_btc_15,omitempty"`
+
+	// BvBtc2 Buy volume in BTC. Over 2 minutes.
+	BvBtc2 *float32 `json:"bv_btc_2,omitempty"`
+
+	// BvBtc3 Buy volume in BTC. Over 3 minutes.
+	BvBtc3 *float32 `json:"bv_btc_3,omitempty"`
+
+	// BvBtc5 Buy volume in BTC. Over 5 minutes.
+	BvBtc5 *float32 `json:"bv_btc_5,omitempty"`
+
+	// BvBtc60 Buy volume in BTC. Over 60 minutes.
+	BvBtc60 *float32 `json:"bv_btc_60,omitempty"`
+
+	// BvUsd1 Buy volume in USD. Over 1 minutes.
+	BvUsd1 *float32 `json:"bv_usd_1,omitempty"`
+
+	// BvUsd10 Buy volume in USD. Over 10 minutes.
+	BvUsd10 *float32 `json:"bv_usd_10,omitempty"`
+
+	// BvUsd15 Buy volume in USD. Over 15 minutes.
+	BvUsd15 *float32 `json:"bv_usd_15,omitempty"`
+
+	// BvUsd2 Buy volume in USD. Over 2 minutes.
+	BvUsd2 *float32 `json:"bv_usd_2,omitempty"`
+
+	// BvUsd3 Buy volume in USD. Over 3 minutes.
+	BvUsd3 *float32 `json:"bv_usd_3,omitempty"`
+
+	// BvUsd5 Buy volume in USD. Over 5 minutes.
+	BvUsd5 *float32 `json:"bv_usd_5,omitempty"`
+
+	// BvUsd60 Buy volume in USD. Over 60 minutes.
+	BvUsd60 *float32 `json:"bv_usd_60,omitempty"`
+
+	// Close Last price.
+	Close float32 `json:"close"`
+
+	// CorrBtc1 Correlation of the USD returns with BTCUSDT. Over 1 minutes.
+	CorrBtc1 *float32 `json:"corr_btc_1,omitempty"`
+
+	// CorrBtc10 Correlation of the USD returns with BTCUSDT. Over 10 minutes.
+	CorrBtc10 *float32 `json:"corr_btc_10,omitempty"`
+
+	// CorrBtc15 Correlation of the USD returns with BTCUSDT. Over 15 minutes.
+	CorrBtc15 *float32 `json:"corr_btc_15,omitempty"`
+
+	// CorrBtc2 Correlation of the USD returns with BTCUSDT. Over 2 minutes.
+	CorrBtc2 *float32 `json:"corr_btc_2,omitempty"`
+
+	// CorrBtc3 Correlation of the USD returns with BTCUSDT. Over 3 minutes.
+	CorrBtc3 *float32 `json:"corr_btc_3,omitempty"`
+
+	// CorrBtc5 Correlation of the USD returns with BTCUSDT. Over 5 minutes.
+	CorrBtc5 *float32 `json:"corr_btc_5,omitempty"`
+
+	// CorrBtc60 Correlation of the USD returns with BTCUSDT. Over 60 minutes.
+	CorrBtc60 *float32 `json:"corr_btc_60,omitempty"`
+
+	// CorrMkt1 Correlation of the USD returns with the market average. Over 1 minutes.
+	CorrMkt1 *float32 `json:"corr_mkt_1,omitempty"`
+
+	// CorrMkt10 Correlation of the USD returns with the market average. Over 10 minutes.
+	CorrMkt10 *float32 `json:"corr_mkt_10,omitempty"`
+
+	// CorrMkt15 Correlation of the USD returns with the market average. Over 15 minutes.
+	CorrMkt15 *float32 `json:"corr_mkt_15,omitempty"`
+
+	// CorrMkt2 Correlation of the USD returns with the market average. Over 2 minutes.
+	CorrMkt2 *float32 `json:"corr_mkt_2,omitempty"`
+
+	// CorrMkt3 Correlation of the USD returns with the market average. Over 3 minutes.
+	CorrMkt3 *float32 `json:"corr_mkt_3,omitempty"`
+
+	// CorrMkt5 Correlation of the USD returns with the market average. Over 5 minutes.
+	CorrMkt5 *float32 `json:"corr_mkt_5,omitempty"`
+
+	// CorrMkt60 Correlation of the USD returns with the market average. Over 60 minutes.
+	CorrMkt60 *float32 `json:"corr_mkt_60,omitempty"`
+
+	// Coverage24h Share of the window covered by history, less than 1 after a recent start or downtime. Over the last 24h.
+	Coverage24h *float32 `json:"coverage_24h,omitempty"`
+
+	// Coverage2h Share of the window covered by history, less than 1 after a recent start or downtime. Over the last 2h.
+	Coverage2h *float32 `json:"coverage_2h,omitempty"`
+
+	// Coverage4h Share of the window covered by history, less than 1 after a recent start or downtime. Over the last 4h.
+	Coverage4h *float32 `json:"coverage_4h,omitempty"`
+
+	// H1 High price. Over 1 minutes.
+	H1 float32 `json:"h_1"`
+
+	// H10 High price. Over 10 minutes.
+	H10 float32 `json:"h_10"`
+
+	// H15 High price. Over 15 minutes.
+	H15 float32 `json:"h_15"`
+
+	// H2 High price. Over 2 minutes.
+	H2 float32 `json:"h_2"`
+
+	// H3 High price. Over 3 minutes.
+	H3 float32 `json:"h_3"`
+
+	// H5 High price. Over 5 minutes.
+	H5 float32 `json:"h_5"`
+
+	// H60 High price. Over 60 minutes.
+	H60 float32 `json:"h_60"`
+
+	// High 24 hour high price.
+	High float32 `json:"high"`
+
+	// L1 Low price. Over 1 minutes.
+	L1 float32 `json:"l_1"`
+
+	// L10 Low price. Over 10 minutes.
+	L10 float32 `json:"l_10"`
+
+	// L15 Low price. Over 15 minutes.
+	L15 float32 `json:"l_15"`
+
+	// L2 Low price. Over 2 minutes.
+	L2 float32 `json:"l_2"`
+
+	// L3 Low price. Over 3 minutes.
+	L3 float32 `json:"l_3"`
+
+	// L5 Low price. Over 5 minutes.
+	L5 float32 `json:"l_5"`
+
+	// L60 Low price. Over 60 minutes.
+	L60 float32 `json:"l_60"`
+
+	// Low 24 hour low price.
+	Low float32 `json:"low"`
+
+	// Nv1 Buy volume less sell volume in the quote asset. Over 1 minutes.
+	Nv1 *float32 `json:"nv_1,omitempty"`
+
+	// Nv10 Buy volume less sell volume in the quote asset. Over 10 minutes.
+	Nv10 *float32 `json:"nv_10,omitempty"`
+
+	// Nv15 Buy volume less sell volume in the quote asset. Over 15 minutes.
+	Nv15 *float32 `json:"nv_15,omitempty"`
+
+	// Nv2 Buy volume less sell volume in the quote asset. Over 2 minutes.
+	Nv2 *float32 `json:"nv_2,omitempty"`
+
+	// Nv24h Buy volume less sell volume in the quote asset. Over the last 24h.
+	Nv24h *float32 `json:"nv_24h,omitempty"`
+
+	// Nv2h Buy volume less sell volume in the quote asset. Over the last 2h.
+	Nv2h *float32 `json:"nv_2h,omitempty"`
+
+	// Nv3 Buy volume less sell volume in the quote asset. Over 3 minutes.
+	Nv3 *float32 `json:"nv_3,omitempty"`
+
+	// Nv4h Buy volume less sell volume in the quote asset. Over the last 4h.
+	Nv4h *float32 `json:"nv_4h,omitempty"`
+
+	// Nv5 Buy volume less sell volume in the quote asset. Over 5 minutes.
+	Nv5 *float32 `json:"nv_5,omitempty"`
+
+	// Nv60 Buy volume less sell volume in the quote asset. Over 60 minutes.
+	Nv60 *float32 `json:"nv_60,omitempty"`
+
+	// NvBtc1 Buy volume less sell volume in BTC. Over 1 minutes.
+	NvBtc1 *float32 `json:"nv_btc_1,omitempty"`
+
+	// NvBtc10 Buy volume less sell volume in BTC. Over 10 minutes.
+	NvBtc10 *float32 `json:"nv_btc_10,omitempty"`
+
+	// NvBtc15 Buy volume less sell volume in BTC. Over 15 minutes.
+	NvBtc15 *float32 `json:"nv_btc_15,omitempty"`
+
+	// NvBtc2 Buy volume less sell volume in BTC. Over 2 minutes.
+	NvBtc2 *float32 `json:"nv_btc_2,omitempty"`
+
+	// NvBtc3 Buy volume less sell volume in BTC. Over 3 minutes.
+	NvBtc3 *float32 `json:"nv_btc_3,omitempty"`
+
+	// NvBtc5 Buy volume less sell volume in BTC. Over 5 minutes.
+	NvBtc5 *float32 `json:"nv_btc_5,omitempty"`
+
+	// NvBtc60 Buy volume less sell volume in BTC. Over 60 minutes.
+	NvBtc60 *float32 `json:"nv_btc_60,omitempty"`
+
+	// NvUsd1 Buy volume less sell volume in USD. Over 1 minutes.
+	NvUsd1 *float32 `json:"nv_usd_1,omitempty"`
+
+	// NvUsd10 Buy volume less sell volume in USD. Over 10 minutes.
+	NvUsd10 *float32 `json:"nv_usd_10,omitempty"`
+
+	// NvUsd15 Buy volume less sell volume in USD. Over 15 minutes.
+	NvUsd15 *float32 `json:"nv_usd_15,omitempty"`
+
+	// NvUsd2 Buy volume less sell volume in USD. Over 2 minutes.
+	NvUsd2 *float32 `json:"nv_usd_2,omitempty"`
+
+	// NvUsd3 Buy volume less sell volume in USD. Over 3 minutes.
+	NvUsd3 *float32 `json:"nv_usd_3,omitempty"`
+
+	// NvUsd5 Buy volume less sell volume in USD. Over 5 minutes.
+	NvUsd5 *float32 `json:"nv_usd_5,omitempty"`
+
+	// NvUsd60 Buy volume less sell volume in USD. Over 60 minutes.
+	NvUsd60 *float32 `json:"nv_usd_60,omitempty"`
+
+	// Poc15 Price with the most traded volume. Over 15 minutes.
+	Poc15 *float32 `json:"poc_15,omitempty"`
+
+	// Poc60 Price with the most traded volume. Over 60 minutes.
+	Poc60 *float32 `json:"poc_60,omitempty"`
+
+	// PriceChangePct Price change in percent keyed by bucket, for example 5m or 1h, and 24h.
+	PriceChangePct map[string]float32 `json:"price_change_pct"`
+
+	// PumpScore Pump score from 0 to 100 over the last minute.
+	PumpScore float32 `json:"pump_score"`
+
+	// R1 Price range. Over 1 minutes.
+	R1 float32 `json:"r_1"`
+
+	// R10 Price range. Over 10 minutes.
+	R10 float32 `json:"r_10"`
+
+	// R15 Price range. Over 15 minutes.
+	R15 float32 `json:"r_15"`
+
+	// R2 Price range. Over 2 minutes.
+	R2 float32 `json:"r_2"`
+
+	// R24 24 hour price range.
+	R24 float32 `json:"r_24"`
+
+	// R3 Price range. Over 3 minutes.
+	R3 float32 `json:"r_3"`
+
+	// R5 Price range. Over 5 minutes.
+	R5 float32 `json:"r_5"`
+
+	// R60 Price range. Over 60 minutes.
+	R60 float32 `json:"r_60"`
+
+	// Rp1 Price range in percent of the low. Over 1 minutes.
+	Rp1 float32 `json:"rp_1"`
+
+	// Rp10 Price range in percent of the low. Over 10 minutes.
+	Rp10 float32 `json:"rp_10"`
+
+	// Rp15 Price range in percent of the low. Over 15 minutes.
+	Rp15 float32 `json:"rp_15"`
+
+	// Rp2 Price range in percent of the low. Over 2 minutes.
+	Rp2 float32 `json:"rp_2"`
+
+	// Rp24 24 hour price range in percent of the low.
+	Rp24 float32 `json:"rp_24"`
+
+	// Rp3 Price range in percent of the low. Over 3 minutes.
+	Rp3 float32 `json:"rp_3"`
+
+	// Rp5 Price range in percent of the low. Over 5 minutes.
+	Rp5 float32 `json:"rp_5"`
+
+	// Rp60 Price range in percent of the low. Over 60 minutes.
+	Rp60 float32 `json:"rp_60"`
+
+	// RsBtc1 USD return less that of BTCUSDT, in percent. Over 1 minutes.
+	RsBtc1 *float32 `json:"rs_btc_1,omitempty"`
+
+	// RsBtc10 USD return less that of BTCUSDT, in percent. Over 10 minutes.
+	RsBtc10 *float32 `json:"rs_btc_10,omitempty"`
+
+	// RsBtc15 USD return less that of BTCUSDT, in percent. Over 15 minutes.
+	RsBtc15 *float32 `json:"rs_btc_15,omitempty"`
+
+	// RsBtc2 USD return less that of BTCUSDT, in percent. Over 2 minutes.
+	RsBtc2 *float32 `json:"rs_btc_2,omitempty"`
+
+	// RsBtc3 USD return less that of BTCUSDT, in percent. Over 3 minutes.
+	RsBtc3 *float32 `json:"rs_btc_3,omitempty"`
+
+	// RsBtc5 USD return less that of BTCUSDT, in percent. Over 5 minutes.
+	RsBtc5 *float32 `json:"rs_btc_5,omitempty"`
+
+	// RsBtc60 USD return less that of BTCUSDT, in percent. Over 60 minutes.
+	RsBtc60 *float32 `json:"rs_btc_60,omitempty"`
+
+	// RsMkt1 USD return less the market average, in percent. Over 1 minutes.
+	RsMkt1 *float32 `json:"rs_mkt_1,omitempty"`
+
+	// RsMkt10 USD return less the market average, in percent. Over 10 minutes.
+	RsMkt10 *float32 `json:"rs_mkt_10,omitempty"`
+
+	// RsMkt15 USD return less the market average, in percent. Over 15 minutes.
+	RsMkt15 *float32 `json:"rs_mkt_15,omitempty"`
+
+	// RsMkt2 USD return less the market average, in percent. Over 2 minutes.
+	RsMkt2 *float32 `json:"rs_mkt_2,omitempty"`
+
+	// RsMkt3 USD return less the market average, in percent. Over 3 minutes.
+	RsMkt3 *float32 `json:"rs_mkt_3,omitempty"`
+
+	// RsMkt5 USD return less the market average, in percent. Over 5 minutes.
+	RsMkt5 *float32 `json:"rs_mkt_5,omitempty"`
+
+	// RsMkt60 USD return less the market average, in percent. Over 60 minutes.
+	RsMkt60 *float32 `json:"rs_mkt_60,omitempty"`
+
+	// Rsi10m Relative strength index. Over 10 minutes.
+	Rsi10m *float32 `json:"rsi_10m,omitempty"`
+
+	// Rsi15m Relative strength index. Over 15 minutes.
+	Rsi15m *float32 `json:"rsi_15m,omitempty"`
+
+	// Rsi1h Relative strength index. Over 60 minutes.
+	Rsi1h *float32 `json:"rsi_1h,omitempty"`
+
+	// Rsi1m Relative strength index. Over 1 minutes.
+	Rsi1m *float32 `json:"rsi_1m,omitempty"`
+
+	// Rsi2m Relative strength index. Over 2 minutes.
+	Rsi2m *float32 `json:"rsi_2m,omitempty"`
+
+	// Rsi3m Relative strength index. Over 3 minutes.
+	Rsi3m *float32 `json:"rsi_3m,omitempty"`
+
+	// Rsi5m Relative strength index. Over 5 minutes.
+	Rsi5m *float32 `json:"rsi_5m,omitempty"`
+
+	// Sv1 Sell volume in the quote asset. Over 1 minutes.
+	Sv1 *float32 `json:"sv_1,omitempty"`
+
+	// Sv10 Sell volume in the quote asset. Over 10 minutes.
+	Sv10 *float32 `json:"sv_10,omitempty"`
+
+	// Sv15 Sell volume in the quote asset. Over 15 minutes.
+	Sv15 *float32 `json:"sv_15,omitempty"`
+
+	// Sv2 Sell volume in the quote asset. Over 2 minutes.
+	Sv2 *float32 `json:"sv_2,omitempty"`
+
+	// Sv24h Sell volume in the quote asset. Over the last 24h.
+	Sv24h *float32 `json:"sv_24h,omitempty"`
+
+	// Sv2h Sell volume in the quote asset. Over the last 2h.
+	Sv2h *float32 `json:"sv_2h,omitempty"`
+
+	// Sv3 Sell volume in the quote asset. Over 3 minutes.
+	Sv3 *float32 `json:"sv_3,omitempty"`
+
+	// Sv4h Sell volume in the quote asset. Over the last 4h.
+	Sv4h *float32 `json:"sv_4h,omitempty"`
+
+	// Sv5 Sell volume in the quote asset. Over 5 minutes.
+	Sv5 *float32 `json:"sv_5,omitempty"`
+
+	// Sv60 Sell volume in the quote asset. Over 60 minutes.
+	Sv60 *float32 `json:"sv_60,omitempty"`
+
+	// SvBtc1 Sell volume in BTC. Over 1 minutes.
+	SvBtc1 *float32 `json:"sv_btc_1,omitempty"`
+
+	// SvBtc10 Sell volume in BTC. Over 10 minutes.
+	SvBtc10 *float32 `json:"sv_btc_10,omitempty"`
+
+	// SvBtc15 Sell volume in BTC. Over 15 minutes.
+	SvBtc15 *float32 `json:"sv_btc_15,omitempty"`
+
+	// SvBtc2 Sell volume in BTC. Over 2 minutes.
+	SvBtc2 *float32 `json:"sv_btc_2,omitempty"`
+
+	// SvBtc3 Sell volume in BTC. Over 3 minutes.
+	SvBtc3 *float32 `json:"sv_btc_3,omitempty"`
+
+	// SvBtc5 Sell volume in BTC. Over 5 minutes.
+	SvBtc5 *float32 `json:"sv_btc_5,omitempty"`
+
+	// SvBtc60 Sell volume in BTC. Over 60 minutes.
+	SvBtc60 *float32 `json:"sv_btc_60,omitempty"`
+
+	// SvUsd1 Sell volume in USD. Over 1 minutes.
+	SvUsd1 *float32 `json:"sv_usd_1,omitempty"`
+
+	// SvUsd10 Sell volume in USD. Over 10 minutes.
+	SvUsd10 *float32 `json:"sv_usd_10,omitempty"`
+
+	// SvUsd15 Sell volume in USD. Over 15 minutes.
+	SvUsd15 *float32 `json:"sv_usd_15,omitempty"`
+
+	// SvUsd2 Sell volume in USD. Over 2 minutes.
+	SvUsd2 *float32 `json:"sv_usd_2,omitempty"`
+
+	// SvUsd3 Sell volume in USD. Over 3 minutes.
+	SvUsd3 *float32 `json:"sv_usd_3,omitempty"`
+
+	// SvUsd5 Sell volume in USD. Over 5 minutes.
+	SvUsd5 *float32 `json:"sv_usd_5,omitempty"`
+
+	// SvUsd60 Sell volume in USD. Over 60 minutes.
+	SvUsd60 *float32 `json:"sv_usd_60,omitempty"`
+
+	// Symbol Symbol, for example ETHBTC.
+	Symbol string `json:"symbol"`
+
+	// Timestamp Time of the last ticker update.
+	Timestamp time.Time `json:"timestamp"`
+
+	// TotalVolume1 Traded volume in the quote asset. Over 1 minutes.
+	TotalVolume1 *float32 `json:"total_volume_1,omitempty"`
+
+	// TotalVolume10 Traded volume in the quote asset. Over 10 minutes.
+	TotalVolume10 *float32 `json:"total_volume_10,omitempty"`
+
+	// TotalVolume15 Traded volume in the quote asset. Over 15 minutes.
+	TotalVolume15 *float32 `json:"total_volume_15,omitempty"`
+
+	// TotalVolume2 Traded volume in the quote asset. Over 2 minutes.
+	TotalVolume2 *float32 `json:"total_volume_2,omitempty"`
+
+	// TotalVolume24h Traded volume in the quote asset. Over the last 24h.
+	TotalVolume24h *float32 `json:"total_volume_24h,omitempty"`
+
+	// TotalVolume2h Traded volume in the quote asset. Over the last 2h.
+	TotalVolume2h *float32 `json:"total_volume_2h,omitempty"`
+
+	// TotalVolume3 Traded volume in the quote asset. Over 3 minutes.
+	TotalVolume3 *float32 `json:"total_volume_3,omitempty"`
+
+	// TotalVolume4h Traded volume in the quote asset. Over the last 4h.
+	TotalVolume4h *float32 `json:"total_volume_4h,omitempty"`
+
+	// TotalVolume5 Traded volume in the quote asset. Over 5 minutes.
+	TotalVolume5 *float32 `json:"total_volume_5,omitempty"`
+
+	// TotalVolume60 Traded volume in the quote asset. Over 60 minutes.
+	TotalVolume60 *float32 `json:"total_volume_60,omitempty"`
+
+	// TotalVolumeBtc1 Traded volume in BTC. Over 1 minutes.
+	TotalVolumeBtc1 *float32 `json:"total_volume_btc_1,omitempty"`
+
+	// TotalVolumeBtc10 Traded volume in BTC. Over 10 minutes.
+	TotalVolumeBtc10 *float32 `json:"total_volume_btc_10,omitempty"`
+
+	// TotalVolumeBtc15 Traded volume in BTC. Over 15 minutes.
+	TotalVolumeBtc15 *float32 `json:"total_volume_btc_15,omitempty"`
+
+	// TotalVolumeBtc2 Traded volume in BTC. Over 2 minutes.
+	TotalVolumeBtc2 *float32 `json:"total_volume_btc_2,omitempty"`
+
+	// TotalVolumeBtc3 Traded volume in BTC. Over 3 minutes.
+	TotalVolumeBtc3 *float32 `json:"total_volume_btc_3,omitempty"`
+
+	// TotalVolumeBtc5 Traded volume in BTC. Over 5 minutes.
+	TotalVolumeBtc5 *float32 `json:"total_volume_btc_5,omitempty"`
+
+	// TotalVolumeBtc60 Traded volume in BTC. Over 60 minutes.
+	TotalVolumeBtc60 *float32 `json:"total_volume_btc_60,omitempty"`
+
+	// TotalVolumeUsd1 Traded volume in USD. Over 1 minutes.
+	TotalVolumeUsd1 *float32 `json:"total_volume_usd_1,omitempty"`
+
+	// TotalVolumeUsd10 Traded volume in USD. Over 10 minutes.
+	TotalVolumeUsd10 *float32 `json:"total_volume_usd_10,omitempty"`
+
+	// TotalVolumeUsd15 Traded volume in USD. Over 15 minutes.
+	TotalVolumeUsd15 *float32 `json:"total_volume_usd_15,omitempty"`
+
+	// TotalVolumeUsd2 Traded volume in USD. Over 2 minutes.
+	TotalVolumeUsd2 *float32 `json:"total_volume_usd_2,omitempty"`
+
+	// TotalVolumeUsd3 Traded volume in USD. Over 3 minutes.
+	TotalVolumeUsd3 *float32 `json:"total_volume_usd_3,omitempty"`
+
+	// TotalVolumeUsd5 Traded volume in USD. Over 5 minutes.
+	TotalVolumeUsd5 *float32 `json:"total_volume_usd_5,omitempty"`
+
+	// TotalVolumeUsd60 Traded volume in USD. Over 60 minutes.
+	TotalVolumeUsd60 *float32 `json:"total_volume_usd_60,omitempty"`
+
+	// Trades24h Number of trades. Over the last 24h.
+	Trades24h *int `json:"trades_24h,omitempty"`
+
+	// Trades2h Number of trades. Over the last 2h.
+	Trades2h *int `json:"trades_2h,omitempty"`
+
+	// Trades4h Number of trades. Over the last 4h.
+	Trades4h *int `json:"trades_4h,omitempty"`
+
+	// Vah15 High of the value area around the point of control. Over 15 minutes.
+	Vah15 *float32 `json:"vah_15,omitempty"`
+
+	// Vah60 High of the value area around the point of control. Over 60 minutes.
+	Vah60 *float32 `json:"vah_60,omitempty"`
+
+	// Val15 Low of the value area around the point of control. Over 15 minutes.
+	Val15 *float32 `json:"val_15,omitempty"`
+
+	// Val60 Low of the value area around the point of control. Over 60 minutes.
+	Val60 *float32 `json:"val_60,omitempty"`
+
+	// Volume 24 hour volume in the quote asset.
+	Volume float32 `json:"volume"`
+
+	// VolumeBtc 24 hour volume in BTC, if a conversion rate for the quote asset is known.
+	VolumeBtc *float32 `json:"volume_btc,omitempty"`
+
+	// VolumeChangePct Volume change in percent keyed by bucket, for example 5m or 1h.
+	VolumeChangePct map[string]float32 `json:"volume_change_pct"`
+
+	// VolumeUsd 24 hour volume in USD, if a conversion rate for the quote asset is known.
+	VolumeUsd *float32 `json:"volume_usd,omitempty"`
+
+	// Vwap10m Volume weighted average price. Over 10 minutes.
+	Vwap10m *float32 `json:"vwap_10m,omitempty"`
+
+	// Vwap15m Volume weighted average price. Over 15 minutes.
+	Vwap15m *float32 `json:"vwap_15m,omitempty"`
+
+	// Vwap1m Volume weighted average price. Over 1 minutes.
+	Vwap1m *float32 `json:"vwap_1m,omitempty"`
+
+	// Vwap24h Volume weighted average price. Over the last 24h.
+	Vwap24h *float32 `json:"vwap_24h,omitempty"`
+
+	// Vwap2h Volume weighted average price. Over the last 2h.
+	Vwap2h *float32 `json:"vwap_2h,omitempty"`
+
+	// Vwap2m Volume weighted average price. Over 2 minutes.
+	Vwap2m *float32 `json:"vwap_2m,omitempty"`
+
+	// Vwap3m Volume weighted average price. Over 3 minutes.
+	Vwap3m *float32 `json:"vwap_3m,omitempty"`
+
+	// Vwap4h Volume weighted average price. Over the last 4h.
+	Vwap4h *float32 `json:"vwap_4h,omitempty"`
+
+	// Vwap5m Volume weighted average price. Over 5 minutes.
+	Vwap5m *float32 `json:"vwap_5m,omitempty"`
+
+	// Vwap60m Volume weighted average price. Over 60 minutes.
+	Vwap60m *float32 `json:"vwap_60m,omitempty"`
+
+	// VwapLower10m Lower VWAP band, two standard deviations below the VWAP. Over 10 minutes.
+	VwapLower10m *float32 `json:"vwap_lower_10m,omitempty"`
+
+	// VwapLower15m Lower VWAP band, two standard deviations below the VWAP. Over 15 minutes.
+	VwapLower15m *float32 `json:"vwap_lower_15m,omitempty"`
+
+	// VwapLower1m Lower VWAP band, two standard deviations below the VWAP. Over 1 minutes.
+	VwapLower1m *float32 `json:"vwap_lower_1m,omitempty"`
+
+	// VwapLower2m Lower VWAP band, two standard deviations below the VWAP. Over 2 minutes.
+	VwapLower2m *float32 `json:"vwap_lower_2m,omitempty"`
+
+	// VwapLower3m Lower VWAP band, two standard deviations below the VWAP. Over 3 minutes.
+	VwapLower3m *float32 `json:"vwap_lower_3m,omitempty"`
+
+	// VwapLower5m Lower VWAP band, two standard deviations below the VWAP. Over 5 minutes.
+	VwapLower5m *float32 `json:"vwap_lower_5m,omitempty"`
+
+	// VwapLower60m Lower VWAP band, two standard deviations below the VWAP. Over 60 minutes.
+	VwapLower60m *float32 `json:"vwap_lower_60m,omitempty"`
+
+	// VwapSd10m Volume weighted standard deviation of the price. Over 10 minutes.
+	VwapSd10m *float32 `json:"vwap_sd_10m,omitempty"`
+
+	// VwapSd15m Volume weighted standard deviation of the price. Over 15 minutes.
+	VwapSd15m *float32 `json:"vwap_sd_15m,omitempty"`
+
+	// VwapSd1m Volume weighted standard deviation of the price. Over 1 minutes.
+	VwapSd1m *float32 `json:"vwap_sd_1m,omitempty"`
+
+	// VwapSd2m Volume weighted standard deviation of the price. Over 2 minutes.
+	VwapSd2m *float32 `json:"vwap_sd_2m,omitempty"`
+
+	// VwapSd3m Volume weighted standard deviation of the price. Over 3 minutes.
+	VwapSd3m *float32 `json:"vwap_sd_3m,omitempty"`
+
+	// VwapSd5m Volume weighted standard deviation of the price. Over 5 minutes.
+	VwapSd5m *float32 `json:"vwap_sd_5m,omitempty"`
+
+	// VwapSd60m Volume weighted standard deviation of the price. Over 60 minutes.
+	VwapSd60m *float32 `json:"vwap_sd_60m,omitempty"`
+
+	// VwapUpper10m Upper VWAP band, two standard deviations above the VWAP. Over 10 minutes.
+	VwapUpper10m *float32 `json:"vwap_upper_10m,omitempty"`
+
+	// VwapUpper15m Upper VWAP band, two standard deviations above the VWAP. Over 15 minutes.
+	VwapUpper15m *float32 `json:"vwap_upper_15m,omitempty"`
+
+	// VwapUpper1m Upper VWAP band, two standard deviations above the VWAP. Over 1 minutes.
+	VwapUpper1m *float32 `json:"vwap_upper_1m,omitempty"`
+
+	// VwapUpper2m Upper VWAP band, two standard deviations above the VWAP. Over 2 minutes.
+	VwapUpper2m *float32 `json:"vwap_upper_2m,omitempty"`
+
+	// VwapUpper3m Upper VWAP band, two standard deviations above the VWAP. Over 3 minutes.
+	VwapUpper3m *float32 `json:"vwap_upper_3m,omitempty"`
+
+	// VwapUpper5m Upper VWAP band, two standard deviations above the VWAP. Over 5 minutes.
+	VwapUpper5m *float32 `json:"vwap_upper_5m,omitempty"`
+
+	// VwapUpper60m Upper VWAP band, two standard deviations above the VWAP. Over 60 minutes.
+	VwapUpper60m *float32 `json:"vwap_upper_60m,omitempty"`
+
+	// Wbv1 Buy volume of whale trades in the quote asset. Over 1 minutes.
+	Wbv1 *float32 `json:"wbv_1,omitempty"`
+
+	// Wbv10 Buy volume of whale trades in the quote asset. Over 10 minutes.
+	Wbv10 *float32 `json:"wbv_10,omitempty"`
+
+	// Wbv15 Buy volume of whale trades in the quote asset. Over 15 minutes.
+	Wbv15 *float32 `json:"wbv_15,omitempty"`
+
+	// Wbv2 Buy volume of whale trades in the quote asset. Over 2 minutes.
+	Wbv2 *float32 `json:"wbv_2,omitempty"`
+
+	// Wbv3 Buy volume of whale trades in the quote asset. Over 3 minutes.
+	Wbv3 *float32 `json:"wbv_3,omitempty"`
+
+	// Wbv5 Buy volume of whale trades in the quote asset. Over 5 minutes.
+	Wbv5 *float32 `json:"wbv_5,omitempty"`
+
+	// Wbv60 Buy volume of whale trades in the quote asset. Over 60 minutes.
+	Wbv60 *float32 `json:"wbv_60,omitempty"`
+
+	// Wsv1 Sell volume of whale trades in the quote asset. Over 1 minutes.
+	Wsv1 *float32 `json:"wsv_1,omitempty"`
+
+	// Wsv10 Sell volume of whale trades in the quote asset. Over 10 minutes.
+	Wsv10 *float32 `json:"wsv_10,omitempty"`
+
+	// Wsv15 Sell volume of whale trades in the quote asset. Over 15 minutes.
+	Wsv15 *float32 `json:"wsv_15,omitempty"`
+
+	// Wsv2 Sell volume of whale trades in the quote asset. Over 2 minutes.
+	Wsv2 *float32 `json:"wsv_2,omitempty"`
+
+	// Wsv3 Sell volume of whale trades in the quote asset. Over 3 minutes.
+	Wsv3 *float32 `json:"wsv_3,omitempty"`
+
+	// Wsv5 Sell volume of whale trades in the quote asset. Over 5 minutes.
+	Wsv5 *float32 `json:"wsv_5,omitempty"`
+
+	// Wsv60 Sell volume of whale trades in the quote asset. Over 60 minutes.
+	Wsv60 *float32 `json:"wsv_60,omitempty"`
+
+	// Wt1 Number of whale trades. Over 1 minutes.
+	Wt1 *int `json:"wt_1,omitempty"`
+
+	// Wt10 Number of whale trades. Over 10 minutes.
+	Wt10 *int `json:"wt_10,omitempty"`
+
+	// Wt15 Number of whale trades. Over 15 minutes.
+	Wt15 *int `json:"wt_15,omitempty"`
+
+	// Wt2 Number of whale trades. Over 2 minutes.
+	Wt2 *int `json:"wt_2,omitempty"`
+
+	// Wt3 Number of whale trades. Over 3 minutes.
+	Wt3 *int `json:"wt_3,omitempty"`
+
+	// Wt5 Number of whale trades. Over 5 minutes.
+	Wt5 *int `json:"wt_5,omitempty"`
+
+	// Wt60 Number of whale trades. Over 60 minutes.
+	Wt60 *int `json:"wt_60,omitempty"`
+
+	// Zp1 Anomaly score of the price change against earlier windows. Over 1 minutes.
+	Zp1 *float32 `json:"zp_1,omitempty"`
+
+	// Zp10 Anomaly score of the price change against earlier windows. Over 10 minutes.
+	Zp10 *float32 `json:"zp_10,omitempty"`
+
+	// Zp15 Anomaly score of the price change against earlier windows. Over 15 minutes.
+	Zp15 *float32 `json:"zp_15,omitempty"`
+
+	// Zp2 Anomaly score of the price change against earlier windows. Over 2 minutes.
+	Zp2 *float32 `json:"zp_2,omitempty"`
+
+	// Zp3 Anomaly score of the price change against earlier windows. Over 3 minutes.
+	Zp3 *float32 `json:"zp_3,omitempty"`
+
+	// Zp5 Anomaly score of the price change against earlier windows. Over 5 minutes.
+	Zp5 *float32 `json:"zp_5,omitempty"`
+
+	// Zp60 Anomaly score of the price change against earlier windows. Over 60 minutes.
+	Zp60 *float32 `json:"zp_60,omitempty"`
+
+	// Zt1 Anomaly score of the number of trades against earlier windows. Over 1 minutes.
+	Zt1 *float32 `json:"zt_1,omitempty"`
+
+	// Zt10 Anomaly score of the number of trades against earlier windows. Over 10 minutes.
+	Zt10 *float32 `json:"zt_10,omitempty"`
+
+	// Zt15 Anomaly score of the number of trades against earlier windows. Over 15 minutes.
+	Zt15 *float32 `json:"zt_15,omitempty"`
+
+	// Zt2 Anomaly score of the number of trades against earlier windows. Over 2 minutes.
+	Zt2 *float32 `json:"zt_2,omitempty"`
+
+	// Zt3 Anomaly score of the number of trades against earlier windows. Over 3 minutes.
+	Zt3 *float32 `json:"zt_3,omitempty"`
+
+	// Zt5 Anomaly score of the number of trades against earlier windows. Over 5 minutes.
+	Zt5 *float32 `json:"zt_5,omitempty"`
+
+	// Zt60 Anomaly score of the number of trades against earlier windows. Over 60 minutes.
+	Zt60 *float32 `json:"zt_60,omitempty"`
+
+	// Zv1 Anomaly score of the traded volume against earlier windows. Over 1 minutes.
+	Zv1 *float32 `json:"zv_1,omitempty"`
+
+	// Zv10 Anomaly score of the traded volume against earlier windows. Over 10 minutes.
+	Zv10 *float32 `json:"zv_10,omitempty"`
+
+	// Zv15 Anomaly score of the traded volume against earlier windows. Over 15 minutes.
+	Zv15 *float32 `json:"zv_15,omitempty"`
+
+	// Zv2 Anomaly score of the traded volume against earlier windows. Over 2 minutes.
+	Zv2 *float32 `json:"zv_2,omitempty"`
+
+	// Zv3 Anomaly score of the traded volume against earlier windows. Over 3 minutes.
+	Zv3 *float32 `json:"zv_3,omitempty"`
+
+	// Zv5 Anomaly score of the traded volume against earlier windows. Over 5 minutes.
+	Zv5 *float32 `json:"zv_5,omitempty"`
+
+	// Zv60 Anomaly score of the traded volume against earlier windows. Over 60 minutes.
+	Zv60 *float32 `json:"zv_60,omitempty"`
+}
+
+// Events defines model for Events.
+type Events struct {
+	// Events Stored events, most recent first.
+	Events *[]interface{} `json:"events"`
+}
+
+// Ping defines model for Ping.
+type Ping struct {
+	// BuildNumber Build number of the server.
+	BuildNumber int `json:"buildNumber"`
+
+	// Version Build number of the server.
+	Version int `json:"version"`
+}
+
+// ProxyStatus defines model for ProxyStatus.
+type ProxyStatus struct {
+	// Cached Number of cached responses.
+	Cached int `json:"cached"`
+
+	// UsedWeight Request weight used in the current minute.
+	UsedWeight int `json:"usedWeight"`
+
+	// WeightPerMinute Request weight per minute the proxy may use, 0 for no limit.
+	WeightPerMinute int `json:"weightPerMinute"`
+}
+
+// Screener defines model for Screener.
+type Screener struct {
+	// Count Number of entries returned.
+	Count   int     `json:"count"`
+	Limit   int     `json:"limit"`
+	Offset  int     `json:"offset"`
+	Results []Entry `json:"results"`
+
+	// Timestamp Time of the snapshot screened, for queries with at.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+
+	// Total Number of entries that matched.
+	Total int `json:"total"`
+}
+
+// Snapshot defines model for Snapshot.
+type Snapshot struct {
+	Tickers []Entry `json:"tickers"`
+
+	// Timestamp Time the snapshot was taken.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SnapshotList defines model for SnapshotList.
+type SnapshotList struct {
+	// Interval Seconds between snapshots, 0 if they are disabled.
+	Interval int `json:"interval"`
+
+	// Timestamps Times of the stored snapshots, oldest first.
+	Timestamps *[]time.Time `json:"timestamps"`
+}
+
+// Symbol defines model for Symbol.
+type Symbol struct {
+	Base       string                               `json:"base"`
+	Candles    *map[string][]map[string]interface{} `json:"candles,omitempty"`
+	Histograms *map[string]interface{}              `json:"histograms,omitempty"`
+	Metrics    *Entry                               `json:"metrics,omitempty"`
+	Quote      string                               `json:"quote"`
+	Symbol     string                               `json:"symbol"`
+	Ticks      *[]map[string]interface{}            `json:"ticks,omitempty"`
+	Trades     *[]map[string]interface{}            `json:"trades,omitempty"`
+}
+
+// Volume defines model for Volume.
+type Volume struct {
+	// Data Keyed by symbol.
+	Data *map[string]struct {
+		// Bvh Buy volume per minute in the quote asset.
+		Bvh *[]float32 `json:"bvh"`
+
+		// BvhBtc Buy volume per minute in BTC.
+		BvhBtc *[]float32 `json:"bvh_btc"`
+
+		// BvhUsd Buy volume per minute in USD.
+		BvhUsd *[]float32 `json:"bvh_usd"`
+
+		// Nv60 Net volume over the last hour in the quote asset.
+		Nv60 *float32 `json:"nv60,omitempty"`
+
+		// Nv60Btc Net volume over the last hour in BTC.
+		Nv60Btc *float32 `json:"nv60_btc,omitempty"`
+
+		// Nv60Usd Net volume over the last hour in USD.
+		Nv60Usd *float32 `json:"nv60_usd,omitempty"`
+
+		// Nvh Net volume per minute in the quote asset.
+		Nvh *[]float32 `json:"nvh"`
+
+		// NvhBtc Net volume per minute in BTC.
+		NvhBtc *[]float32 `json:"nvh_btc"`
+
+		// NvhUsd Net volume per minute in USD.
+		NvhUsd *[]float32 `json:"nvh_usd"`
+
+		// PriceChange1h Price change in percent over the last hour.
+		PriceChange1h *float32 `json:"priceChange1h,omitempty"`
+
+		// Quote Quote asset.
+		Quote string `json:"quote"`
+
+		// Rsi15 RSI of the 15 minute bucket, 0 if unavailable.
+		Rsi15 *float32 `json:"rsi15,omitempty"`
+
+		// T60 Number of trades over the last hour.
+		T60 *int `json:"t60,omitempty"`
+
+		// T60pb Share of the trades over the last hour that were buys, 0 to 1.
+		T60pb *float32 `json:"t60pb,omitempty"`
+
+		// V24h 24 hour volume in the quote asset at each minute.
+		V24h *[]float32 `json:"v24h"`
+
+		// V24hBtc 24 hour volume in BTC at each minute.
+		V24hBtc *[]float32 `json:"v24h_btc"`
+
+		// V24hUsd 24 hour volume in USD at each minute.
+		V24hUsd *[]float32 `json:"v24h_usd"`
+
+		// V60 Volume over the last hour in the quote asset.
+		V60 *float32 `json:"v60,omitempty"`
+
+		// V60Btc Volume over the last hour in BTC.
+		V60Btc *float32 `json:"v60_btc,omitempty"`
+
+		// V60Usd Volume over the last hour in USD.
+		V60Usd *float32 `json:"v60_usd,omitempty"`
+
+		// Vh Volume per minute in the quote asset.
+		Vh *[]float32 `json:"vh"`
+
+		// VhBtc Volume per minute in BTC.
+		VhBtc *[]float32 `json:"vh_btc"`
+
+		// VhUsd Volume per minute in USD.
+		VhUsd *[]float32 `json:"vh_usd"`
+
+		// Vol 24 hour volume in the quote asset.
+		Vol float32 `json:"vol"`
+
+		// VolBtc 24 hour volume in BTC, zero if no conversion rate is known.
+		VolBtc float32 `json:"vol_btc"`
+
+		// VolUsd 24 hour volume in USD, zero if no conversion rate is known.
+		VolUsd float32 `json:"vol_usd"`
+	} `json:"data"`
+}
+
+// VolumeProfiles defines model for VolumeProfiles.
+type VolumeProfiles struct {
+	// Profiles One profile for each window with trades.
+	Profiles *[]struct {
+		Bins *[]struct {
+			BuyVolume float32 `json:"buy_volume"`
+			High      float32 `json:"high"`
+			Low       float32 `json:"low"`
+			Volume    float32 `json:"volume"`
+		} `json:"bins"`
+		High   float32 `json:"high"`
+		Low    float32 `json:"low"`
+		Poc    float32 `json:"poc"`
+		Trades int     `json:"trades"`
+		Vah    float32 `json:"vah"`
+		Val    float32 `json:"val"`
+		Volume float32 `json:"volume"`
+		Vwap   float32 `json:"vwap"`
+		VwapSd float32 `json:"vwap_sd"`
+		Window int     `json:"window"`
+	} `json:"profiles"`
+	Symbol string `json:"symbol"`
+}
+
+// WebSocketsStatus defines model for WebSocketsStatus.
+type WebSocketsStatus struct {
+	// Clients Paths connected to by each client, keyed by a hash of the client address.
+	Clients *map[string][]string `json:"clients"`
+
+	// Connections Open connections, in the order they connected.
+	Connections *[]struct {
+		// Client Hash of the client address.
+		Client string `json:"client"`
+
+		// Disconnected Whether the connection is being closed for falling behind.
+		Disconnected bool `json:"disconnected"`
+
+		// Dropped Updates dropped as the queue was full.
+		Dropped int `json:"dropped"`
+
+		// Id Number of the connection.
+		Id int `json:"id"`
+
+		// Paths Paths the connection is subscribed to.
+		Paths *[]string `json:"paths"`
+
+		// Queued Updates waiting to be sent.
+		Queued int `json:"queued"`
+	} `json:"connections"`
+
+	// Disconnected Connections closed for falling behind since startup.
+	Disconnected int `json:"disconnected"`
+
+	// Dropped Updates dropped for all connections since startup.
+	Dropped int `json:"dropped"`
+
+	// Paths Number of connections by path.
+	Paths *map[string]int `json:"paths"`
+	Queue struct {
+		// Depth Number of updates queued per client subscription.
+		Depth int `json:"depth"`
+
+		// Policy What happens when a client queue is full: drop-oldest or disconnect.
+		Policy string `json:"policy"`
+	} `json:"queue"`
+}
+
+// GetAssetsParams defines parameters for GetAssets.
+type GetAssetsParams struct {
+	// Asset Comma separated assets, default all.
+	Asset *string `form:"asset,omitempty" json:"asset,omitempty"`
+}
+
+// GetBreadthParams defines parameters for GetBreadth.
+type GetBreadthParams struct {
+	// History Include the recent history.
+	History *bool `form:"history,omitempty" json:"history,omitempty"`
+}
+
+// GetEventsParams defines parameters for GetEvents.
+type GetEventsParams struct {
+	// Type Event type, for example pump.
+	Type *string `form:"type,omitempty" json:"type,omitempty"`
+
+	// Symbol Symbol, for example ETHBTC.
+	Symbol *string `form:"symbol,omitempty" json:"symbol,omitempty"`
+
+	// Since Unix time in seconds, default 24 hours ago.
+	Since *int `form:"since,omitempty" json:"since,omitempty"`
+
+	// Limit Number of events, default 100, at most 1000.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetVolumeProfileParams defines parameters for GetVolumeProfile.
+type GetVolumeProfileParams struct {
+	// Window Window in minutes, default the configured windows.
+	Window *int `form:"window,omitempty" json:"window,omitempty"`
+
+	// Bins Number of price levels.
+	Bins *int `form:"bins,omitempty" json:"bins,omitempty"`
+}
+
+// GetScreenerParams defines parameters for GetScreener.
+type GetScreenerParams struct {
+	// Sort Metric to rank by, default volume.
+	Sort *string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Order desc (default) or asc.
+	Order *string `form:"order,omitempty" json:"order,omitempty"`
+
+	// Limit Number of entries, default 50, at most 1000.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset Number of ranked entries to skip.
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Quote Comma separated quote assets.
+	Quote *string `form:"quote,omitempty" json:"quote,omitempty"`
+
+	// MinVolume Minimum 24 hour volume in the quote asset.
+	MinVolume *float32 `form:"min_volume,omitempty" json:"min_volume,omitempty"`
+
+	// Filter Metric conditions such as nv_15>1000, comma separated or repeated.
+	Filter *string `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Fields Comma separated metrics to include.
+	Fields *string `form:"fields,omitempty" json:"fields,omitempty"`
+
+	// At Screen the most recent snapshot taken at or before this time, unix seconds or RFC 3339.
+	At *string `form:"at,omitempty" json:"at,omitempty"`
+}
+
+// GetSnapshotParams defines parameters for GetSnapshot.
+type GetSnapshotParams struct {
+	// At The most recent snapshot taken at or before this time is returned, unix seconds or RFC 3339.
+	At string `form:"at" json:"at"`
+
+	// Fields Comma separated metrics to include.
+	Fields *string `form:"fields,omitempty" json:"fields,omitempty"`
+}
+
+// GetSnapshotsParams defines parameters for GetSnapshots.
+type GetSnapshotsParams struct {
+	// Since Unix seconds or RFC 3339, default 24 hours ago.
+	Since *string `form:"since,omitempty" json:"since,omitempty"`
+
+	// Until Unix seconds or RFC 3339, default now.
+	Until *string `form:"until,omitempty" json:"until,omitempty"`
+}
+
+// GetSymbolParams defines parameters for GetSymbol.
+type GetSymbolParams struct {
+	// Include Comma separated sections: metrics, histograms, ticks, candles and trades. Default all.
+	Include *string `form:"include,omitempty" json:"include,omitempty"`
+
+	// Fields Comma separated metrics to include.
+	Fields *string `form:"fields,omitempty" json:"fields,omitempty"`
+
+	// Ticks Number of the most recent ticks, default 60.
+	Ticks *int `form:"ticks,omitempty" json:"ticks,omitempty"`
+
+	// Candles Number of the most recent candles of each bucket, default 60.
+	Candles *int `form:"candles,omitempty" json:"candles,omitempty"`
+
+	// Trades Number of the most recent trades, default 60.
+	Trades *int `form:"trades,omitempty" json:"trades,omitempty"`
+}
+
+// RequestEditorFn  is the function signature for the RequestEditor callback function
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Doer performs HTTP requests.
+//
+// The standard http.Client implements this interface.
+type HttpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client which conforms to the OpenAPI3 specification for this service.
+type Client struct {
+	// The endpoint of the server conforming to this interface, with scheme,
+	// https://api.deepmap.com for example. This can contain a path relative
+	// to the server, such as https://api.deepmap.com/dev-test, and all the
+	// paths in the swagger spec will be appended to the server.
+	Server string
+
+	// Doer for performing requests, typically a *http.Client with any
+	// customized settings, such as certificate chains.
+	Client HttpRequestDoer
+
+	// A list of callbacks for modifying requests which are generated before sending over
+	// the network.
+	RequestEditors []RequestEditorFn
+}
+
+// ClientOption allows setting custom parameters during construction
+type ClientOption func(*Client) error
+
+// Creates a new Client, with reasonable defaults
+func NewClient(server string, opts ...ClientOption) (*Client, error) {
+	// create a client with sane default values
+	client := Client{
+		Server: server,
+	}
+	// mutate client and add all optional params
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+	// ensure the server URL always has a trailing slash
+	if !strings.HasSuffix(client.Server, "/") {
+		client.Server += "/"
+	}
+	// create httpClient, if not already present
+	if client.Client == nil {
+		client.Client = &http.Client{}
+	}
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer HttpRequestDoer) ClientOption {
+	return func(c *Client) error {
+		c.Client = doer
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+		return nil
+	}
+}
+
+// The interface specification for the client above.
+type ClientInterface interface {
+	// GetAssets request
+	GetAssets(ctx context.Context, params *GetAssetsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetBreadth request
+	GetBreadth(ctx context.Context, params *GetBreadthParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEvents request
+	GetEvents(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetVolumeProfile request
+	GetVolumeProfile(ctx context.Context, symbol string, params *GetVolumeProfileParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetProxy request
+	GetProxy(ctx context.Context, path string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetScreener request
+	GetScreener(ctx context.Context, params *GetScreenerParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSnapshot request
+	GetSnapshot(ctx context.Context, params *GetSnapshotParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSnapshots request
+	GetSnapshots(ctx context.Context, params *GetSnapshotsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSymbol request
+	GetSymbol(ctx context.Context, symbol string, params *GetSymbolParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetVolume request
+	GetVolume(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetOpenApi request
+	GetOpenApi(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetPing request
+	GetPing(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetFeedSchema request
+	GetFeedSchema(ctx context.Context, feed string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetProxyStatus request
+	GetProxyStatus(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetWebSocketsStatus request
+	GetWebSocketsStatus(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) GetAssets(ctx context.Context, params *GetAssetsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetAssetsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetBreadth(ctx context.Context, params *GetBreadthParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetBreadthRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetEvents(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEventsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetVolumeProfile(ctx context.Context, symbol string, params *GetVolumeProfileParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetVolumeProfileRequest(c.Server, symbol, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetProxy(ctx context.Context, path string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetProxyRequest(c.Server, path)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetScreener(ctx context.Context, params *GetScreenerParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetScreenerRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSnapshot(ctx context.Context, params *GetSnapshotParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSnapshotRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSnapshots(ctx context.Context, params *GetSnapshotsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSnapshotsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSymbol(ctx context.Context, symbol string, params *GetSymbolParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSymbolRequest(c.Server, symbol, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetVolume(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetVolumeRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetOpenApi(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetOpenApiRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetPing(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetPingRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetFeedSchema(ctx context.Context, feed string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetFeedSchemaRequest(c.Server, feed)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetProxyStatus(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetProxyStatusRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetWebSocketsStatus(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetWebSocketsStatusRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewGetAssetsRequest generates requests for GetAssets
+func NewGetAssetsRequest(server string, params *GetAssetsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/assets")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Asset != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "asset", runtime.ParamLocationQuery, *params.Asset); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetBreadthRequest generates requests for GetBreadth
+func NewGetBreadthRequest(server string, params *GetBreadthParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/breadth")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.History != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "history", runtime.ParamLocationQuery, *params.History); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetEventsRequest generates requests for GetEvents
+func NewGetEventsRequest(server string, params *GetEventsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/events")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Type != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "type", runtime.ParamLocationQuery, *params.Type); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Symbol != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "symbol", runtime.ParamLocationQuery, *params.Symbol); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Since != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "since", runtime.ParamLocationQuery, *params.Since); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetVolumeProfileRequest generates requests for GetVolumeProfile
+func NewGetVolumeProfileRequest(server string, symbol string, params *GetVolumeProfileParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "symbol", runtime.ParamLocationPath, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/profile/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Window != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "window", runtime.ParamLocationQuery, *params.Window); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Bins != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "bins", runtime.ParamLocationQuery, *params.Bins); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetProxyRequest generates requests for GetProxy
+func NewGetProxyRequest(server string, path string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "path", runtime.ParamLocationPath, path)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/proxy/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetScreenerRequest generates requests for GetScreener
+func NewGetScreenerRequest(server string, params *GetScreenerParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/screener")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Sort != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "sort", runtime.ParamLocationQuery, *params.Sort); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Order != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "order", runtime.ParamLocationQuery, *params.Order); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Offset != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "offset", runtime.ParamLocationQuery, *params.Offset); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Quote != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "quote", runtime.ParamLocationQuery, *params.Quote); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.MinVolume != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "min_volume", runtime.ParamLocationQuery, *params.MinVolume); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Filter != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "filter", runtime.ParamLocationQuery, *params.Filter); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Fields != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "fields", runtime.ParamLocationQuery, *params.Fields); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.At != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "at", runtime.ParamLocationQuery, *params.At); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetSnapshotRequest generates requests for GetSnapshot
+func NewGetSnapshotRequest(server string, params *GetSnapshotParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/snapshot")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "at", runtime.ParamLocationQuery, params.At); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.Fields != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "fields", runtime.ParamLocationQuery, *params.Fields); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetSnapshotsRequest generates requests for GetSnapshots
+func NewGetSnapshotsRequest(server string, params *GetSnapshotsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/snapshots")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Since != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "since", runtime.ParamLocationQuery, *params.Since); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Until != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "until", runtime.ParamLocationQuery, *params.Until); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetSymbolRequest generates requests for GetSymbol
+func NewGetSymbolRequest(server string, symbol string, params *GetSymbolParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "symbol", runtime.ParamLocationPath, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/symbol/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Include != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "include", runtime.ParamLocationQuery, *params.Include); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Fields != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "fields", runtime.ParamLocationQuery, *params.Fields); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Ticks != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "ticks", runtime.ParamLocationQuery, *params.Ticks); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Candles != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "candles", runtime.ParamLocationQuery, *params.Candles); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Trades != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "trades", runtime.ParamLocationQuery, *params.Trades); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetVolumeRequest generates requests for GetVolume
+func NewGetVolumeRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/binance/volume")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetOpenApiRequest generates requests for GetOpenApi
+func NewGetOpenApiRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/openapi.json")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetPingRequest generates requests for GetPing
+func NewGetPingRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/ping")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetFeedSchemaRequest generates requests for GetFeedSchema
+func NewGetFeedSchemaRequest(server string, feed string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "feed", runtime.ParamLocationPath, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/schema/%s.json", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetProxyStatusRequest generates requests for GetProxyStatus
+func NewGetProxyStatusRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/status/proxy")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetWebSocketsStatusRequest generates requests for GetWebSocketsStatus
+func NewGetWebSocketsStatusRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/1/status/websockets")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// GetAssetsWithResponse request
+	GetAssetsWithResponse(ctx context.Context, params *GetAssetsParams, reqEditors ...RequestEditorFn) (*GetAssetsResponse, error)
+
+	// GetBreadthWithResponse request
+	GetBreadthWithResponse(ctx context.Context, params *GetBreadthParams, reqEditors ...RequestEditorFn) (*GetBreadthResponse, error)
+
+	// GetEventsWithResponse request
+	GetEventsWithResponse(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*GetEventsResponse, error)
+
+	// GetVolumeProfileWithResponse request
+	GetVolumeProfileWithResponse(ctx context.Context, symbol string, params *GetVolumeProfileParams, reqEditors ...RequestEditorFn) (*GetVolumeProfileResponse, error)
+
+	// GetProxyWithResponse request
+	GetProxyWithResponse(ctx context.Context, path string, reqEditors ...RequestEditorFn) (*GetProxyResponse, error)
+
+	// GetScreenerWithResponse request
+	GetScreenerWithResponse(ctx context.Context, params *GetScreenerParams, reqEditors ...RequestEditorFn) (*GetScreenerResponse, error)
+
+	// GetSnapshotWithResponse request
+	GetSnapshotWithResponse(ctx context.Context, params *GetSnapshotParams, reqEditors ...RequestEditorFn) (*GetSnapshotResponse, error)
+
+	// GetSnapshotsWithResponse request
+	GetSnapshotsWithResponse(ctx context.Context, params *GetSnapshotsParams, reqEditors ...RequestEditorFn) (*GetSnapshotsResponse, error)
+
+	// GetSymbolWithResponse request
+	GetSymbolWithResponse(ctx context.Context, symbol string, params *GetSymbolParams, reqEditors ...RequestEditorFn) (*GetSymbolResponse, error)
+
+	// GetVolumeWithResponse request
+	GetVolumeWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetVolumeResponse, error)
+
+	// GetOpenApiWithResponse request
+	GetOpenApiWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetOpenApiResponse, error)
+
+	// GetPingWithResponse request
+	GetPingWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetPingResponse, error)
+
+	// GetFeedSchemaWithResponse request
+	GetFeedSchemaWithResponse(ctx context.Context, feed string, reqEditors ...RequestEditorFn) (*GetFeedSchemaResponse, error)
+
+	// GetProxyStatusWithResponse request
+	GetProxyStatusWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetProxyStatusResponse, error)
+
+	// GetWebSocketsStatusWithResponse request
+	GetWebSocketsStatusWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetWebSocketsStatusResponse, error)
+}
+
+type GetAssetsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Assets
+}
+
+// Status returns HTTPResponse.Status
+func (r GetAssetsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetAssetsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetBreadthResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Breadth
+}
+
+// Status returns HTTPResponse.Status
+func (r GetBreadthResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetBreadthResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEventsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Events
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEventsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEventsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetVolumeProfileResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *VolumeProfiles
+}
+
+// Status returns HTTPResponse.Status
+func (r GetVolumeProfileResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetVolumeProfileResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetProxyResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *interface{}
+}
+
+// Status returns HTTPResponse.Status
+func (r GetProxyResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetProxyResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetScreenerResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Screener
+}
+
+// Status returns HTTPResponse.Status
+func (r GetScreenerResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetScreenerResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSnapshotResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Snapshot
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSnapshotResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSnapshotResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSnapshotsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SnapshotList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSnapshotsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSnapshotsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSymbolResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Symbol
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSymbolResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSymbolResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetVolumeResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Volume
+}
+
+// Status returns HTTPResponse.Status
+func (r GetVolumeResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetVolumeResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetOpenApiResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *interface{}
+}
+
+// Status returns HTTPResponse.Status
+func (r GetOpenApiResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetOpenApiResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetPingResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Ping
+}
+
+// Status returns HTTPResponse.Status
+func (r GetPingResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetPingResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetFeedSchemaResponse struct {
+	Body                     []byte
+	HTTPResponse             *http.Response
+	ApplicationschemaJSON200 *interface{}
+}
+
+// Status returns HTTPResponse.Status
+func (r GetFeedSchemaResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetFeedSchemaResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetProxyStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ProxyStatus
+}
+
+// Status returns HTTPResponse.Status
+func (r GetProxyStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetProxyStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetWebSocketsStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *WebSocketsStatus
+}
+
+// Status returns HTTPResponse.Status
+func (r GetWebSocketsStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetWebSocketsStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// GetAssetsWithResponse request returning *GetAssetsResponse
+func (c *ClientWithResponses) GetAssetsWithResponse(ctx context.Context, params *GetAssetsParams, reqEditors ...RequestEditorFn) (*GetAssetsResponse, error) {
+	rsp, err := c.GetAssets(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetAssetsResponse(rsp)
+}
+
+// GetBreadthWithResponse request returning *GetBreadthResponse
+func (c *ClientWithResponses) GetBreadthWithResponse(ctx context.Context, params *GetBreadthParams, reqEditors ...RequestEditorFn) (*GetBreadthResponse, error) {
+	rsp, err := c.GetBreadth(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetBreadthResponse(rsp)
+}
+
+// GetEventsWithResponse request returning *GetEventsResponse
+func (c *ClientWithResponses) GetEventsWithResponse(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*GetEventsResponse, error) {
+	rsp, err := c.GetEvents(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEventsResponse(rsp)
+}
+
+// GetVolumeProfileWithResponse request returning *GetVolumeProfileResponse
+func (c *ClientWithResponses) GetVolumeProfileWithResponse(ctx context.Context, symbol string, params *GetVolumeProfileParams, reqEditors ...RequestEditorFn) (*GetVolumeProfileResponse, error) {
+	rsp, err := c.GetVolumeProfile(ctx, symbol, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetVolumeProfileResponse(rsp)
+}
+
+// GetProxyWithResponse request returning *GetProxyResponse
+func (c *ClientWithResponses) GetProxyWithResponse(ctx context.Context, path string, reqEditors ...RequestEditorFn) (*GetProxyResponse, error) {
+	rsp, err := c.GetProxy(ctx, path, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetProxyResponse(rsp)
+}
+
+// GetScreenerWithResponse request returning *GetScreenerResponse
+func (c *ClientWithResponses) GetScreenerWithResponse(ctx context.Context, params *GetScreenerParams, reqEditors ...RequestEditorFn) (*GetScreenerResponse, error) {
+	rsp, err := c.GetScreener(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetScreenerResponse(rsp)
+}
+
+// GetSnapshotWithResponse request returning *GetSnapshotResponse
+func (c *ClientWithResponses) GetSnapshotWithResponse(ctx context.Context, params *GetSnapshotParams, reqEditors ...RequestEditorFn) (*GetSnapshotResponse, error) {
+	rsp, err := c.GetSnapshot(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSnapshotResponse(rsp)
+}
+
+// GetSnapshotsWithResponse request returning *GetSnapshotsResponse
+func (c *ClientWithResponses) GetSnapshotsWithResponse(ctx context.Context, params *GetSnapshotsParams, reqEditors ...RequestEditorFn) (*GetSnapshotsResponse, error) {
+	rsp, err := c.GetSnapshots(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSnapshotsResponse(rsp)
+}
+
+// GetSymbolWithResponse request returning *GetSymbolResponse
+func (c *ClientWithResponses) GetSymbolWithResponse(ctx context.Context, symbol string, params *GetSymbolParams, reqEditors ...RequestEditorFn) (*GetSymbolResponse, error) {
+	rsp, err := c.GetSymbol(ctx, symbol, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSymbolResponse(rsp)
+}
+
+// GetVolumeWithResponse request returning *GetVolumeResponse
+func (c *ClientWithResponses) GetVolumeWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetVolumeResponse, error) {
+	rsp, err := c.GetVolume(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetVolumeResponse(rsp)
+}
+
+// GetOpenApiWithResponse request returning *GetOpenApiResponse
+func (c *ClientWithResponses) GetOpenApiWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetOpenApiResponse, error) {
+	rsp, err := c.GetOpenApi(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetOpenApiResponse(rsp)
+}
+
+// GetPingWithResponse request returning *GetPingResponse
+func (c *ClientWithResponses) GetPingWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetPingResponse, error) {
+	rsp, err := c.GetPing(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetPingResponse(rsp)
+}
+
+// GetFeedSchemaWithResponse request returning *GetFeedSchemaResponse
+func (c *ClientWithResponses) GetFeedSchemaWithResponse(ctx context.Context, feed string, reqEditors ...RequestEditorFn) (*GetFeedSchemaResponse, error) {
+	rsp, err := c.GetFeedSchema(ctx, feed, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetFeedSchemaResponse(rsp)
+}
+
+// GetProxyStatusWithResponse request returning *GetProxyStatusResponse
+func (c *ClientWithResponses) GetProxyStatusWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetProxyStatusResponse, error) {
+	rsp, err := c.GetProxyStatus(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetProxyStatusResponse(rsp)
+}
+
+// GetWebSocketsStatusWithResponse request returning *GetWebSocketsStatusResponse
+func (c *ClientWithResponses) GetWebSocketsStatusWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetWebSocketsStatusResponse, error) {
+	rsp, err := c.GetWebSocketsStatus(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetWebSocketsStatusResponse(rsp)
+}
+
+// ParseGetAssetsResponse parses an HTTP response from a GetAssetsWithResponse call
+func ParseGetAssetsResponse(rsp *http.Response) (*GetAssetsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetAssetsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Assets
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetBreadthResponse parses an HTTP response from a GetBreadthWithResponse call
+func ParseGetBreadthResponse(rsp *http.Response) (*GetBreadthResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetBreadthResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Breadth
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetEventsResponse parses an HTTP response from a GetEventsWithResponse call
+func ParseGetEventsResponse(rsp *http.Response) (*GetEventsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetEventsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Events
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetVolumeProfileResponse parses an HTTP response from a GetVolumeProfileWithResponse call
+func ParseGetVolumeProfileResponse(rsp *http.Response) (*GetVolumeProfileResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetVolumeProfileResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest VolumeProfiles
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetProxyResponse parses an HTTP response from a GetProxyWithResponse call
+func ParseGetProxyResponse(rsp *http.Response) (*GetProxyResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetProxyResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetScreenerResponse parses an HTTP response from a GetScreenerWithResponse call
+func ParseGetScreenerResponse(rsp *http.Response) (*GetScreenerResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetScreenerResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Screener
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetSnapshotResponse parses an HTTP response from a GetSnapshotWithResponse call
+func ParseGetSnapshotResponse(rsp *http.Response) (*GetSnapshotResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetSnapshotResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Snapshot
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetSnapshotsResponse parses an HTTP response from a GetSnapshotsWithResponse call
+func ParseGetSnapshotsResponse(rsp *http.Response) (*GetSnapshotsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetSnapshotsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SnapshotList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetSymbolResponse parses an HTTP response from a GetSymbolWithResponse call
+func ParseGetSymbolResponse(rsp *http.Response) (*GetSymbolResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetSymbolResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Symbol
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetVolumeResponse parses an HTTP response from a GetVolumeWithResponse call
+func ParseGetVolumeResponse(rsp *http.Response) (*GetVolumeResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetVolumeResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Volume
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetOpenApiResponse parses an HTTP response from a GetOpenApiWithResponse call
+func ParseGetOpenApiResponse(rsp *http.Response) (*GetOpenApiResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetOpenApiResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetPingResponse parses an HTTP response from a GetPingWithResponse call
+func ParseGetPingResponse(rsp *http.Response) (*GetPingResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetPingResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Ping
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetFeedSchemaResponse parses an HTTP response from a GetFeedSchemaWithResponse call
+func ParseGetFeedSchemaResponse(rsp *http.Response) (*GetFeedSchemaResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetFeedSchemaResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.ApplicationschemaJSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetProxyStatusResponse parses an HTTP response from a GetProxyStatusWithResponse call
+func ParseGetProxyStatusResponse(rsp *http.Response) (*GetProxyStatusResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetProxyStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ProxyStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetWebSocketsStatusResponse parses an HTTP response from a GetWebSocketsStatusWithResponse call
+func ParseGetWebSocketsStatusResponse(rsp *http.Response) (*GetWebSocketsStatusResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetWebSocketsStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest WebSocketsStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
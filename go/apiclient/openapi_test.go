@@ -0,0 +1,56 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"gitlab.com/crankykernel/cryptoxscanner/server"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestOpenApiDocument checks that the document the client was generated
+// from is the one the server serves; run "go generate ./apiclient" if not.
+func TestOpenApiDocument(t *testing.T) {
+	buf, err := os.ReadFile("openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generated, served interface{}
+	if err := json.Unmarshal(buf, &generated); err != nil {
+		t.Fatal(err)
+	}
+	buf, err = json.Marshal(server.OpenApiSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf, &served); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(generated, served) {
+		t.Errorf("openapi.json is out of date, run go generate ./apiclient")
+	}
+}
@@ -25,80 +25,387 @@
 package binance
 
 import (
-	"bytes"
+	"container/list"
 	"fmt"
 	"gitlab.com/crankykernel/cryptoxscanner/log"
-	"io"
 	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+// The request weight Binance allows per IP and minute is 1200. By default
+// the proxy leaves half of it for the scanner itself.
+const DefaultProxyWeightPerMinute = 600
+
+// DefaultProxyEndpoints are the Binance API endpoints the proxy forwards,
+// with how long responses are cached.
+var DefaultProxyEndpoints = map[string]time.Duration{
+	"/api/v1/klines":            time.Second * 5,
+	"/api/v3/klines":            time.Second * 5,
+	"/api/v1/depth":             time.Second,
+	"/api/v3/depth":             time.Second,
+	"/api/v1/aggTrades":         time.Second,
+	"/api/v3/aggTrades":         time.Second,
+	"/api/v1/trades":            time.Second,
+	"/api/v3/trades":            time.Second,
+	"/api/v3/ticker/price":      time.Second,
+	"/api/v3/ticker/bookTicker": time.Second,
+	"/api/v1/ticker/24hr":       time.Second * 5,
+	"/api/v3/ticker/24hr":       time.Second * 5,
+	"/api/v1/exchangeInfo":      time.Minute * 5,
+	"/api/v3/exchangeInfo":      time.Minute * 5,
+}
+
+type ProxyOptions struct {
+	// Request weight per minute the proxy may use, 0 for no limit.
+	WeightPerMinute int
+
+	// Maximum number of cached responses.
+	CacheSize int
+
+	// Endpoints that are forwarded, by path, with their cache TTL. Paths
+	// are matched without regard to case.
+	Endpoints map[string]time.Duration
+
+	// Timeout of requests to Binance.
+	Timeout time.Duration
+}
+
+var DefaultProxyOptions = ProxyOptions{
+	WeightPerMinute: DefaultProxyWeightPerMinute,
+	CacheSize:       256,
+	Endpoints:       DefaultProxyEndpoints,
+	Timeout:         time.Second * 10,
+}
+
+const proxyPrefix = "/api/1/binance/proxy"
+
+const proxyUpstream = "https://api.binance.com"
+
+// proxyEndpoint is an allowlisted endpoint, by the path as configured.
+type proxyEndpoint struct {
+	path string
+	ttl  time.Duration
+}
+
 type proxyCacheEntry struct {
-	timestamp time.Time
-	content   []byte
-	header    http.Header
+	key        string
+	timestamp  time.Time
+	statusCode int
+	content    []byte
+	header     http.Header
+}
+
+// proxyCache is a cache of responses bounded to the most recently used.
+type proxyCache struct {
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newProxyCache(size int) *proxyCache {
+	if size < 1 {
+		size = 1
+	}
+	return &proxyCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func (c *proxyCache) Get(key string) *proxyCacheEntry {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*proxyCacheEntry)
+}
+
+func (c *proxyCache) Add(entry *proxyCacheEntry) {
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*proxyCacheEntry).key)
+	}
+}
+
+// proxyBudget tracks the request weight used against the Binance API from
+// this IP, as reported by Binance, so the proxy can stop forwarding before
+// the IP is banned.
+type proxyBudget struct {
+	weightPerMinute int
+	minute          time.Time
+	usedWeight      int
+	blockedUntil    time.Time
+	lock            sync.Mutex
+}
+
+// Allow returns true if a request can be forwarded now, or the number of
+// seconds to wait.
+func (b *proxyBudget) Allow() (bool, int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := time.Now()
+	if now.Before(b.blockedUntil) {
+		return false, int(math.Ceil(b.blockedUntil.Sub(now).Seconds()))
+	}
+	if b.weightPerMinute <= 0 {
+		return true, 0
+	}
+	minute := now.Truncate(time.Minute)
+	if minute.After(b.minute) {
+		b.minute = minute
+		b.usedWeight = 0
+	}
+	if b.usedWeight >= b.weightPerMinute {
+		return false, int(math.Ceil(minute.Add(time.Minute).Sub(now).Seconds()))
+	}
+	return true, 0
+}
+
+// Update records the weight used as reported in the headers of a Binance
+// response, and backs off if Binance is rate limiting us.
+func (b *proxyBudget) Update(response *http.Response) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := time.Now()
+	minute := now.Truncate(time.Minute)
+	if minute.After(b.minute) {
+		b.minute = minute
+		b.usedWeight = 0
+	}
+	for _, header := range []string{"x-mbx-used-weight-1m", "x-mbx-used-weight"} {
+		if weight, err := strconv.Atoi(response.Header.Get(header)); err == nil {
+			b.usedWeight = weight
+			break
+		}
+	}
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode == 418 {
+		retryAfter, err := strconv.Atoi(response.Header.Get("retry-after"))
+		if err != nil || retryAfter <= 0 {
+			retryAfter = 60
+		}
+		b.blockedUntil = now.Add(time.Duration(retryAfter) * time.Second)
+		log.Warnf("Binance API rate limit hit (status %d), pausing proxy for %ds",
+			response.StatusCode, retryAfter)
+	}
+}
+
+// UsedWeight returns the weight used in the current minute.
+func (b *proxyBudget) UsedWeight() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if time.Now().Truncate(time.Minute).After(b.minute) {
+		return 0
+	}
+	return b.usedWeight
+}
+
+// proxyThrottledError is returned when the budget does not allow a request
+// to be forwarded.
+type proxyThrottledError struct {
+	retryAfter int
+}
+
+func (e *proxyThrottledError) Error() string {
+	return fmt.Sprintf("request weight budget exhausted, retry after %ds", e.retryAfter)
 }
 
+// proxyCall is an upstream request that concurrent identical requests
+// wait on rather than making their own.
+type proxyCall struct {
+	done  chan bool
+	entry *proxyCacheEntry
+	err   error
+}
+
+// ApiProxy forwards GET requests for an allowlist of Binance API endpoints,
+// caching the responses.
 type ApiProxy struct {
-	cache map[string]*proxyCacheEntry
-	lock  sync.RWMutex
+	options ProxyOptions
+
+	// The allowlisted endpoints keyed by lowercased path.
+	endpoints map[string]proxyEndpoint
+
+	upstream string
+	client   *http.Client
+	cache    *proxyCache
+	inflight map[string]*proxyCall
+	lock     sync.Mutex
+	budget   *proxyBudget
 }
 
-func NewApiProxy() *ApiProxy {
+func NewApiProxy(options ProxyOptions) *ApiProxy {
+	if options.Endpoints == nil {
+		options.Endpoints = DefaultProxyEndpoints
+	}
+	endpoints := map[string]proxyEndpoint{}
+	for path, ttl := range options.Endpoints {
+		endpoints[strings.ToLower(path)] = proxyEndpoint{path: path, ttl: ttl}
+	}
 	return &ApiProxy{
-		cache: make(map[string]*proxyCacheEntry),
+		options:   options,
+		endpoints: endpoints,
+		upstream:  proxyUpstream,
+		client:    &http.Client{Timeout: options.Timeout},
+		cache:     newProxyCache(options.CacheSize),
+		inflight:  map[string]*proxyCall{},
+		budget: &proxyBudget{
+			weightPerMinute: options.WeightPerMinute,
+		},
 	}
 }
 
-func (p *ApiProxy) AddToCache(key string, entry proxyCacheEntry) {
+// fetch returns the response for a request, from the cache if it is fresh
+// enough or by making the request to Binance. Only successful responses are
+// cached.
+func (p *ApiProxy) fetch(key string, ttl time.Duration) (*proxyCacheEntry, bool, error) {
 	p.lock.Lock()
-	defer p.lock.Unlock()
-	p.cache[key] = &entry
-}
+	if entry := p.cache.Get(key); entry != nil && time.Now().Sub(entry.timestamp) <= ttl {
+		p.lock.Unlock()
+		return entry, true, nil
+	}
+	if call, ok := p.inflight[key]; ok {
+		p.lock.Unlock()
+		<-call.done
+		return call.entry, true, call.err
+	}
+	call := &proxyCall{done: make(chan bool)}
+	p.inflight[key] = call
+	p.lock.Unlock()
 
-func (p *ApiProxy) GetFromCache(key string) *proxyCacheEntry {
-	p.lock.RLock()
-	defer p.lock.RUnlock()
-	return p.cache[key]
+	call.entry, call.err = p.forward(key)
+
+	p.lock.Lock()
+	if call.err == nil && call.entry.statusCode == http.StatusOK {
+		p.cache.Add(call.entry)
+	}
+	delete(p.inflight, key)
+	p.lock.Unlock()
+	close(call.done)
+
+	return call.entry, false, call.err
 }
 
-func (p *ApiProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	url := fmt.Sprintf("https://api.binance.com%s",
-		r.URL.RequestURI()[len("/api/1/binance/proxy"):])
-
-	cached := p.GetFromCache(url)
-	if cached != nil {
-		if time.Now().Sub(cached.timestamp) <= time.Second*1 {
-			w.Header().Add("content-type", cached.header.Get("content-type"))
-			w.Header().Add("access-control-allow-origin", "*")
-			io.Copy(w, bytes.NewReader(cached.content))
-			return
-		}
+func (p *ApiProxy) forward(key string) (*proxyCacheEntry, error) {
+	if ok, retryAfter := p.budget.Allow(); !ok {
+		return nil, &proxyThrottledError{retryAfter: retryAfter}
 	}
 
-	proxyRequest, err := http.NewRequest("GET", url, nil)
+	response, err := p.client.Get(p.upstream + key)
 	if err != nil {
-		log.Printf("error: %v\n", err)
+		return nil, err
 	}
+	defer response.Body.Close()
+	p.budget.Update(response)
 
-	proxyResponse, err := http.DefaultClient.Do(proxyRequest)
+	content, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return &proxyCacheEntry{
+		key:        key,
+		timestamp:  time.Now(),
+		statusCode: response.StatusCode,
+		content:    content,
+		header:     response.Header,
+	}, nil
+}
+
+func (p *ApiProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
 		return
 	}
-	defer proxyResponse.Body.Close()
 
-	w.Header().Add("content-type", proxyResponse.Header.Get("content-type"))
+	path := strings.TrimPrefix(r.URL.Path, proxyPrefix)
+	endpoint, ok := p.endpoints[strings.ToLower(path)]
+	if !ok {
+		http.Error(w, fmt.Sprintf("endpoint not allowed: %s", path),
+			http.StatusForbidden)
+		return
+	}
+
+	// The endpoint is forwarded by its allowlisted path, whatever the case
+	// it was requested in, and encoding the query sorts it, so equivalent
+	// requests share an entry.
+	key := endpoint.path
+	if query := r.URL.Query().Encode(); query != "" {
+		key = key + "?" + query
+	}
+
 	w.Header().Add("access-control-allow-origin", "*")
-	w.WriteHeader(proxyResponse.StatusCode)
-
-	content, _ := ioutil.ReadAll(proxyResponse.Body)
-	p.AddToCache(url, proxyCacheEntry{
-		timestamp: time.Now(),
-		content:   content,
-		header:    proxyResponse.Header,
-	})
-	w.Write(content)
+
+	entry, cached, err := p.fetch(key, endpoint.ttl)
+	if err != nil {
+		if throttled, ok := err.(*proxyThrottledError); ok {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", throttled.retryAfter))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests),
+				http.StatusTooManyRequests)
+			return
+		}
+		log.WithError(err).Errorf("Failed to proxy request for %s", key)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			http.Error(w, http.StatusText(http.StatusGatewayTimeout),
+				http.StatusGatewayTimeout)
+		} else {
+			http.Error(w, http.StatusText(http.StatusBadGateway),
+				http.StatusBadGateway)
+		}
+		return
+	}
+
+	// Errors of Binance itself are reported as a bad gateway, the client
+	// can not do anything about them.
+	if entry.statusCode >= 500 {
+		log.Errorf("Failed to proxy request for %s: status %d", key, entry.statusCode)
+		http.Error(w, http.StatusText(http.StatusBadGateway),
+			http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Add("content-type", entry.header.Get("content-type"))
+	if retryAfter := entry.header.Get("retry-after"); retryAfter != "" {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+	if cached {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+	w.WriteHeader(entry.statusCode)
+	w.Write(entry.content)
+}
+
+type ProxyStatus struct {
+	Cached          int `json:"cached" doc:"Number of cached responses."`
+	UsedWeight      int `json:"usedWeight" doc:"Request weight used in the current minute."`
+	WeightPerMinute int `json:"weightPerMinute" doc:"Request weight per minute the proxy may use, 0 for no limit."`
+}
+
+// Status returns the state of the proxy for the status API.
+func (p *ApiProxy) Status() ProxyStatus {
+	p.lock.Lock()
+	cached := p.cache.order.Len()
+	p.lock.Unlock()
+	return ProxyStatus{
+		Cached:          cached,
+		UsedWeight:      p.budget.UsedWeight(),
+		WeightPerMinute: p.options.WeightPerMinute,
+	}
 }
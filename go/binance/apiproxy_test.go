@@ -0,0 +1,106 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestApiProxy(t *testing.T) {
+	requests := []string{}
+	lock := sync.Mutex{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests = append(requests, r.URL.RequestURI())
+		lock.Unlock()
+		if r.URL.Path == "/api/v3/depth" {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer upstream.Close()
+
+	proxy := NewApiProxy(ProxyOptions{
+		CacheSize: 8,
+		Endpoints: map[string]time.Duration{
+			"/api/v3/exchangeInfo": time.Minute,
+			"/api/v3/depth":        time.Minute,
+		},
+		Timeout: time.Second,
+	})
+	proxy.upstream = upstream.URL
+
+	tests := []struct {
+		url      string
+		status   int
+		cache    string
+		upstream []string
+	}{
+		// Forwarded by the allowlisted path with the query sorted.
+		{"/API/V3/EXCHANGEINFO?symbol=ETHBTC&a=1", http.StatusOK, "MISS",
+			[]string{"/api/v3/exchangeInfo?a=1&symbol=ETHBTC"}},
+		{"/api/v3/exchangeinfo?a=1&symbol=ETHBTC", http.StatusOK, "HIT", nil},
+
+		// Server errors of Binance are a bad gateway and not cached.
+		{"/api/v3/depth?symbol=ETHBTC", http.StatusBadGateway, "",
+			[]string{"/api/v3/depth?symbol=ETHBTC"}},
+		{"/api/v3/depth?symbol=ETHBTC", http.StatusBadGateway, "",
+			[]string{"/api/v3/depth?symbol=ETHBTC"}},
+
+		{"/api/v3/account", http.StatusForbidden, "", nil},
+	}
+	for _, test := range tests {
+		lock.Lock()
+		requests = requests[:0]
+		lock.Unlock()
+
+		response := httptest.NewRecorder()
+		proxy.ServeHTTP(response, httptest.NewRequest("GET", proxyPrefix+test.url, nil))
+		if response.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.url, test.status, response.Code)
+		}
+		if cache := response.Header().Get("X-Cache"); cache != test.cache {
+			t.Errorf("%s: expected X-Cache %q, got %q", test.url, test.cache, cache)
+		}
+		lock.Lock()
+		if len(requests) != len(test.upstream) {
+			t.Errorf("%s: expected upstream requests %v, got %v", test.url, test.upstream, requests)
+		} else {
+			for i := range requests {
+				if requests[i] != test.upstream[i] {
+					t.Errorf("%s: expected upstream request %s, got %s",
+						test.url, test.upstream[i], requests[i])
+				}
+			}
+		}
+		lock.Unlock()
+	}
+}
@@ -0,0 +1,113 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const restApiBaseUrl = "https://api.binance.com"
+
+var restHttpClient = &http.Client{
+	Timeout: time.Second * 30,
+}
+
+// SymbolInfo is the subset of a symbol entry from the Binance exchangeInfo
+// endpoint needed to decide which markets to scan.
+type SymbolInfo struct {
+	Symbol     string `json:"symbol"`
+	Status     string `json:"status"`
+	BaseAsset  string `json:"baseAsset"`
+	QuoteAsset string `json:"quoteAsset"`
+}
+
+type exchangeInfoResponse struct {
+	Symbols []SymbolInfo `json:"symbols"`
+}
+
+type ticker24hResponse struct {
+	Symbol      string `json:"symbol"`
+	LastPrice   string `json:"lastPrice"`
+	QuoteVolume string `json:"quoteVolume"`
+}
+
+// Ticker24h is the rolling 24 hour summary of a symbol.
+type Ticker24h struct {
+	Symbol      string
+	LastPrice   float64
+	QuoteVolume float64
+}
+
+func restGet(path string, v interface{}) error {
+	response, err := restHttpClient.Get(restApiBaseUrl + path)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %s", path, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+// GetExchangeInfo returns all symbols listed on the exchange, including
+// ones that are not currently trading.
+func GetExchangeInfo() ([]SymbolInfo, error) {
+	var response exchangeInfoResponse
+	if err := restGet("/api/v1/exchangeInfo", &response); err != nil {
+		return nil, err
+	}
+	return response.Symbols, nil
+}
+
+// Get24hTickers returns the rolling 24 hour ticker of every symbol, with
+// the volume in its quote asset.
+func Get24hTickers() ([]Ticker24h, error) {
+	var response []ticker24hResponse
+	if err := restGet("/api/v1/ticker/24hr", &response); err != nil {
+		return nil, err
+	}
+	tickers := []Ticker24h{}
+	for _, ticker := range response {
+		price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+		if err != nil {
+			continue
+		}
+		volume, err := strconv.ParseFloat(ticker.QuoteVolume, 64)
+		if err != nil {
+			continue
+		}
+		tickers = append(tickers, Ticker24h{
+			Symbol:      ticker.Symbol,
+			LastPrice:   price,
+			QuoteVolume: volume,
+		})
+	}
+	return tickers, nil
+}
@@ -0,0 +1,152 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"sync"
+)
+
+// Assets treated as being worth exactly one US dollar.
+var UsdAssets = []string{"USDT", "BUSD", "USDC", "TUSD", "FDUSD", "PAX"}
+
+// ConversionRates tracks the value of each asset in USD as implied by the
+// last price of the markets in the ticker stream.
+type ConversionRates struct {
+	universe *SymbolUniverse
+	usd      map[string]float64
+	lock     sync.RWMutex
+}
+
+func NewConversionRates(universe *SymbolUniverse) *ConversionRates {
+	return &ConversionRates{
+		universe: universe,
+		usd:      map[string]float64{},
+	}
+}
+
+// IsUsdAsset returns true if asset is one of the USD pegged UsdAssets.
+func IsUsdAsset(asset string) bool {
+	for _, usd := range UsdAssets {
+		if usd == asset {
+			return true
+		}
+	}
+	return false
+}
+
+// Update recalculates the rates from a batch of tickers. Assets missing
+// from the batch keep their previous rate.
+func (r *ConversionRates) Update(tickers []binanceapi.TickerStreamMessage) {
+	markets := []rateMarket{}
+	for _, ticker := range tickers {
+		info, ok := r.universe.Get(ticker.Symbol)
+		if !ok {
+			continue
+		}
+		markets = append(markets, rateMarket{
+			base:  info.BaseAsset,
+			quote: info.QuoteAsset,
+			price: ticker.CurrentDayClose,
+		})
+	}
+	usd := usdRates(markets)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for asset, rate := range usd {
+		r.usd[asset] = rate
+	}
+}
+
+// USD returns the value of one unit of asset in USD, or 0 if unknown.
+func (r *ConversionRates) USD(asset string) float64 {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.usd[asset]
+}
+
+// BTC returns the value of one unit of asset in BTC, or 0 if unknown.
+func (r *ConversionRates) BTC(asset string) float64 {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	btc := r.usd["BTC"]
+	if btc == 0 {
+		return 0
+	}
+	return r.usd[asset] / btc
+}
+
+type rateMarket struct {
+	base  string
+	quote string
+	price float64
+}
+
+// usdRates returns the value in USD of every asset that can be priced
+// through the markets.
+func usdRates(markets []rateMarket) map[string]float64 {
+	usd := map[string]float64{}
+	for _, asset := range UsdAssets {
+		usd[asset] = 1
+	}
+
+	// Price against a USD asset directly, preferring USDT as the most
+	// liquid. Then resolve the remaining assets through any market with
+	// a known quote (e.g. XYZBTC) or known base (e.g. USDTTRY), repeating
+	// until nothing new is resolved.
+	for _, m := range markets {
+		if m.price <= 0 || IsUsdAsset(m.base) {
+			continue
+		}
+		if m.quote == "USDT" {
+			usd[m.base] = m.price
+		} else if _, ok := usd[m.base]; !ok && IsUsdAsset(m.quote) {
+			usd[m.base] = m.price
+		}
+	}
+	for {
+		resolved := 0
+		for _, m := range markets {
+			if m.price <= 0 {
+				continue
+			}
+			_, haveBase := usd[m.base]
+			_, haveQuote := usd[m.quote]
+			if !haveBase && haveQuote {
+				usd[m.base] = m.price * usd[m.quote]
+				resolved++
+			} else if haveBase && !haveQuote {
+				usd[m.quote] = usd[m.base] / m.price
+				resolved++
+			}
+		}
+		if resolved == 0 {
+			break
+		}
+	}
+
+	return usd
+}
@@ -0,0 +1,155 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"math"
+	"testing"
+)
+
+func TestUsdRates(t *testing.T) {
+	tests := []struct {
+		name     string
+		markets  []rateMarket
+		expected map[string]float64
+		missing  []string
+	}{
+		{
+			name:     "usdt quote",
+			markets:  []rateMarket{{"BTC", "USDT", 50000}},
+			expected: map[string]float64{"BTC": 50000, "USDT": 1},
+		},
+		{
+			name: "usdt preferred",
+			markets: []rateMarket{
+				{"BTC", "BUSD", 49000},
+				{"BTC", "USDT", 50000},
+				{"ETH", "USDT", 2500},
+				{"ETH", "USDC", 2400},
+			},
+			expected: map[string]float64{"BTC": 50000, "ETH": 2500},
+		},
+		{
+			name:     "other usd quote",
+			markets:  []rateMarket{{"XYZ", "BUSD", 2}},
+			expected: map[string]float64{"XYZ": 2},
+		},
+		{
+			name:     "usd assets stay pegged",
+			markets:  []rateMarket{{"USDC", "USDT", 0.99}},
+			expected: map[string]float64{"USDC": 1},
+		},
+		{
+			name: "through a known quote",
+			markets: []rateMarket{
+				{"ETH", "BTC", 0.05},
+				{"BTC", "USDT", 50000},
+			},
+			expected: map[string]float64{"ETH": 2500},
+		},
+		{
+			// Listed so that each hop needs another pass.
+			name: "two hops",
+			markets: []rateMarket{
+				{"ABC", "ETH", 0.1},
+				{"ETH", "BTC", 0.05},
+				{"BTC", "USDT", 50000},
+			},
+			expected: map[string]float64{"ETH": 2500, "ABC": 250},
+		},
+		{
+			name: "through a known base",
+			markets: []rateMarket{
+				{"USDT", "TRY", 20},
+				{"ABC", "TRY", 10},
+			},
+			expected: map[string]float64{"TRY": 0.05, "ABC": 0.5},
+		},
+		{
+			name: "unpriced",
+			markets: []rateMarket{
+				{"ABC", "TRY", 10},
+				{"XYZ", "USDT", 0},
+				{"ETH", "BTC", 0.05},
+			},
+			missing: []string{"ABC", "TRY", "XYZ", "ETH", "BTC"},
+		},
+	}
+	for _, test := range tests {
+		usd := usdRates(test.markets)
+		for asset, expected := range test.expected {
+			if rate, ok := usd[asset]; !ok || math.Abs(rate-expected) > expected*1e-9 {
+				t.Errorf("%s: expected %s at %v, got %v", test.name, asset, expected, rate)
+			}
+		}
+		for _, asset := range test.missing {
+			if rate, ok := usd[asset]; ok {
+				t.Errorf("%s: expected no rate for %s, got %v", test.name, asset, rate)
+			}
+		}
+	}
+}
+
+func TestConversionRatesUpdate(t *testing.T) {
+	universe, err := NewSymbolUniverse(SymbolFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	universe.update([]SymbolInfo{
+		{Symbol: "BTCUSDT", Status: "TRADING", BaseAsset: "BTC", QuoteAsset: "USDT"},
+		{Symbol: "ETHBTC", Status: "TRADING", BaseAsset: "ETH", QuoteAsset: "BTC"},
+	}, nil)
+	rates := NewConversionRates(universe)
+
+	rates.Update([]binanceapi.TickerStreamMessage{
+		{Symbol: "BTCUSDT", CurrentDayClose: 50000},
+		{Symbol: "ETHBTC", CurrentDayClose: 0.05},
+		{Symbol: "UNKNOWN", CurrentDayClose: 1},
+	})
+	// ETH is missing from the batch so keeps its last rate.
+	rates.Update([]binanceapi.TickerStreamMessage{
+		{Symbol: "BTCUSDT", CurrentDayClose: 40000},
+	})
+
+	tests := []struct {
+		asset string
+		usd   float64
+		btc   float64
+	}{
+		{"BTC", 40000, 1},
+		{"ETH", 2500, 0.0625},
+		{"USDT", 1, 0.000025},
+		{"UNKNOWN", 0, 0},
+	}
+	for _, test := range tests {
+		if usd := rates.USD(test.asset); math.Abs(usd-test.usd) > 1e-9 {
+			t.Errorf("%s: expected %v USD, got %v", test.asset, test.usd, usd)
+		}
+		if btc := rates.BTC(test.asset); math.Abs(btc-test.btc) > 1e-12 {
+			t.Errorf("%s: expected %v BTC, got %v", test.asset, test.btc, btc)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The quote assets scanned when none are configured.
+var DefaultQuoteAssets = []string{"BTC", "ETH", "BNB", "USDT"}
+
+// SymbolFilter describes which markets make up the symbol universe. Empty
+// include lists match everything; exclude lists always take precedence.
+type SymbolFilter struct {
+	QuoteAssets        []string
+	ExcludeQuoteAssets []string
+	BaseAssets         []string
+	ExcludeBaseAssets  []string
+	Symbols            []string
+	ExcludeSymbols     []string
+
+	// Regular expressions matched against the upper case symbol name.
+	Include []string
+	Exclude []string
+
+	// Minimum 24 hour volume in USD. Symbols with a quote asset that
+	// can't be priced in USD are not filtered on volume.
+	MinVolume24h float64
+}
+
+type compiledSymbolFilter struct {
+	quoteAssets        map[string]bool
+	excludeQuoteAssets map[string]bool
+	baseAssets         map[string]bool
+	excludeBaseAssets  map[string]bool
+	symbols            map[string]bool
+	excludeSymbols     map[string]bool
+	include            []*regexp.Regexp
+	exclude            []*regexp.Regexp
+	minVolume24h       float64
+}
+
+func toUpperSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, value := range values {
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if value != "" {
+			set[value] = true
+		}
+	}
+	return set
+}
+
+func compileRegexps(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid symbol regex %q: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func (f SymbolFilter) compile() (*compiledSymbolFilter, error) {
+	include, err := compileRegexps(f.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileRegexps(f.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	quoteAssets := f.QuoteAssets
+	if len(quoteAssets) == 0 {
+		quoteAssets = DefaultQuoteAssets
+	}
+	return &compiledSymbolFilter{
+		quoteAssets:        toUpperSet(quoteAssets),
+		excludeQuoteAssets: toUpperSet(f.ExcludeQuoteAssets),
+		baseAssets:         toUpperSet(f.BaseAssets),
+		excludeBaseAssets:  toUpperSet(f.ExcludeBaseAssets),
+		symbols:            toUpperSet(f.Symbols),
+		excludeSymbols:     toUpperSet(f.ExcludeSymbols),
+		include:            include,
+		exclude:            exclude,
+		minVolume24h:       f.MinVolume24h,
+	}, nil
+}
+
+func matchAny(res []*regexp.Regexp, value string) bool {
+	for _, re := range res {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// match tests a symbol against the filter. Volume is only checked if a
+// minimum is configured and volume data for the symbol is available.
+func (f *compiledSymbolFilter) match(info SymbolInfo, volume float64, haveVolume bool) bool {
+	if info.Status != "" && info.Status != "TRADING" {
+		return false
+	}
+	if f.excludeSymbols[info.Symbol] ||
+		f.excludeQuoteAssets[info.QuoteAsset] ||
+		f.excludeBaseAssets[info.BaseAsset] ||
+		matchAny(f.exclude, info.Symbol) {
+		return false
+	}
+
+	// An explicitly listed symbol skips the remaining include rules.
+	if !f.symbols[info.Symbol] {
+		if !f.quoteAssets[info.QuoteAsset] {
+			return false
+		}
+		if len(f.baseAssets) > 0 && !f.baseAssets[info.BaseAsset] {
+			return false
+		}
+		if len(f.include) > 0 && !matchAny(f.include, info.Symbol) {
+			return false
+		}
+	}
+
+	if f.minVolume24h > 0 && haveVolume && volume < f.minVolume24h {
+		return false
+	}
+
+	return true
+}
+
+// SymbolUniverse is the set of symbols selected by a SymbolFilter. It is
+// shared by the trade stream, the ticker trackers and everything that
+// publishes them so they all agree on which markets are scanned.
+type SymbolUniverse struct {
+	filter  *compiledSymbolFilter
+	symbols map[string]SymbolInfo
+	listed  map[string]SymbolInfo
+	version uint64
+	lock    sync.RWMutex
+}
+
+func NewSymbolUniverse(filter SymbolFilter) (*SymbolUniverse, error) {
+	compiled, err := filter.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &SymbolUniverse{
+		filter:  compiled,
+		symbols: map[string]SymbolInfo{},
+		listed:  map[string]SymbolInfo{},
+	}, nil
+}
+
+// Refresh reloads the symbol list from the exchange and re-applies the
+// filter.
+func (u *SymbolUniverse) Refresh() error {
+	infos, err := GetExchangeInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get exchange info: %v", err)
+	}
+
+	var tickers []Ticker24h
+	if u.filter.minVolume24h > 0 {
+		tickers, err = Get24hTickers()
+		if err != nil {
+			return fmt.Errorf("failed to get 24h tickers: %v", err)
+		}
+	}
+
+	u.update(infos, tickers)
+	return nil
+}
+
+// RefreshEvery refreshes the universe at interval so listings and
+// delistings are picked up. It does not return.
+func (u *SymbolUniverse) RefreshEvery(interval time.Duration) {
+	for range time.Tick(interval) {
+		version := u.Version()
+		if err := u.Refresh(); err != nil {
+			log.WithError(err).Errorf("Failed to refresh Binance symbol universe.")
+			continue
+		}
+		if u.Version() != version {
+			log.Infof("Binance symbol universe changed, now %d symbols.", u.Len())
+		}
+	}
+}
+
+// update replaces the symbols with the ones of infos matching the filter,
+// with the 24h volumes of tickers converted to USD.
+func (u *SymbolUniverse) update(infos []SymbolInfo, tickers []Ticker24h) {
+	listed := map[string]SymbolInfo{}
+	for _, info := range infos {
+		listed[info.Symbol] = info
+	}
+
+	markets := []rateMarket{}
+	for _, ticker := range tickers {
+		if info, ok := listed[ticker.Symbol]; ok {
+			markets = append(markets, rateMarket{
+				base:  info.BaseAsset,
+				quote: info.QuoteAsset,
+				price: ticker.LastPrice,
+			})
+		}
+	}
+	usd := usdRates(markets)
+
+	volumes := map[string]float64{}
+	for _, ticker := range tickers {
+		info, ok := listed[ticker.Symbol]
+		if !ok || usd[info.QuoteAsset] == 0 {
+			continue
+		}
+		volumes[ticker.Symbol] = ticker.QuoteVolume * usd[info.QuoteAsset]
+	}
+
+	symbols := map[string]SymbolInfo{}
+	for _, info := range infos {
+		volume, haveVolume := volumes[info.Symbol]
+		if u.filter.match(info, volume, haveVolume) {
+			symbols[info.Symbol] = info
+		}
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if !sameSymbols(u.symbols, symbols) {
+		u.version++
+	}
+	u.symbols = symbols
+	u.listed = listed
+}
+
+func sameSymbols(a map[string]SymbolInfo, b map[string]SymbolInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for symbol := range a {
+		if _, ok := b[symbol]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Version is incremented each time a refresh changes the set of symbols.
+func (u *SymbolUniverse) Version() uint64 {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	return u.version
+}
+
+// Contains returns true if the symbol, in any case, is part of the
+// universe.
+func (u *SymbolUniverse) Contains(symbol string) bool {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	_, ok := u.symbols[strings.ToUpper(symbol)]
+	return ok
+}
+
+// Get returns the exchange info for any listed symbol, including symbols
+// excluded from the universe.
+func (u *SymbolUniverse) Get(symbol string) (SymbolInfo, bool) {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	info, ok := u.listed[strings.ToUpper(symbol)]
+	return info, ok
+}
+
+// Symbols returns the upper case names of all symbols in the universe.
+func (u *SymbolUniverse) Symbols() []string {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	symbols := make([]string, 0, len(u.symbols))
+	for symbol := range u.symbols {
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+func (u *SymbolUniverse) Len() int {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	return len(u.symbols)
+}
@@ -0,0 +1,106 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"testing"
+)
+
+func TestSymbolUniverseMinVolumeUsd(t *testing.T) {
+	universe, err := NewSymbolUniverse(SymbolFilter{
+		QuoteAssets:  []string{"BTC", "USDT", "TRY"},
+		MinVolume24h: 100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos := []SymbolInfo{
+		{Symbol: "BTCUSDT", Status: "TRADING", BaseAsset: "BTC", QuoteAsset: "USDT"},
+		{Symbol: "ETHBTC", Status: "TRADING", BaseAsset: "ETH", QuoteAsset: "BTC"},
+		{Symbol: "XYZBTC", Status: "TRADING", BaseAsset: "XYZ", QuoteAsset: "BTC"},
+		{Symbol: "XYZUSDT", Status: "TRADING", BaseAsset: "XYZ", QuoteAsset: "USDT"},
+		{Symbol: "ABCTRY", Status: "TRADING", BaseAsset: "ABC", QuoteAsset: "TRY"},
+	}
+	tickers := []Ticker24h{
+		{Symbol: "BTCUSDT", LastPrice: 50000, QuoteVolume: 1000000},
+		// 10 BTC is 500000 USD.
+		{Symbol: "ETHBTC", LastPrice: 0.05, QuoteVolume: 10},
+		// 1 BTC is 50000 USD, under the minimum though over it in BTC.
+		{Symbol: "XYZBTC", LastPrice: 0.00001, QuoteVolume: 1},
+		{Symbol: "XYZUSDT", LastPrice: 0.5, QuoteVolume: 99999},
+		// TRY can't be priced in USD so isn't filtered on volume.
+		{Symbol: "ABCTRY", LastPrice: 10, QuoteVolume: 1},
+	}
+	universe.update(infos, tickers)
+
+	for symbol, expected := range map[string]bool{
+		"BTCUSDT": true,
+		"ETHBTC":  true,
+		"XYZBTC":  false,
+		"XYZUSDT": false,
+		"ABCTRY":  true,
+	} {
+		if universe.Contains(symbol) != expected {
+			t.Errorf("%s: expected contains %v", symbol, expected)
+		}
+	}
+}
+
+func TestSymbolUniverseVersion(t *testing.T) {
+	universe, err := NewSymbolUniverse(SymbolFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos := []SymbolInfo{
+		{Symbol: "ETHBTC", Status: "TRADING", BaseAsset: "ETH", QuoteAsset: "BTC"},
+		{Symbol: "LTCBTC", Status: "TRADING", BaseAsset: "LTC", QuoteAsset: "BTC"},
+	}
+
+	universe.update(infos, nil)
+	version := universe.Version()
+	universe.update(infos, nil)
+	if universe.Version() != version {
+		t.Fatalf("version changed without a change of symbols")
+	}
+
+	// A listing.
+	infos = append(infos, SymbolInfo{Symbol: "NEWBTC", Status: "TRADING", BaseAsset: "NEW", QuoteAsset: "BTC"})
+	universe.update(infos, nil)
+	if universe.Version() == version || !universe.Contains("NEWBTC") {
+		t.Fatalf("listing not picked up")
+	}
+	version = universe.Version()
+
+	// A delisting.
+	infos[0].Status = "BREAK"
+	universe.update(infos, nil)
+	if universe.Version() == version || universe.Contains("ETHBTC") {
+		t.Fatalf("delisting not picked up")
+	}
+	if _, ok := universe.Get("ETHBTC"); !ok {
+		t.Fatalf("delisted symbol should still be listed")
+	}
+}
@@ -41,11 +41,13 @@ type TradeStream struct {
 	subscribers map[chan binanceapi.StreamAggTrade]tradeStreamSubscriberQueue
 	lock        sync.RWMutex
 	cache       *db.GenericCache
+	universe    *SymbolUniverse
 }
 
-func NewTradeStream() *TradeStream {
+func NewTradeStream(universe *SymbolUniverse) *TradeStream {
 	tradeStream := &TradeStream{
 		subscribers: map[chan binanceapi.StreamAggTrade]tradeStreamSubscriberQueue{},
+		universe:    universe,
 	}
 	cache, err := db.OpenGenericCache("binance-cache")
 	if err != nil {
@@ -104,24 +106,21 @@ func (b *TradeStream) Run() {
 
 	go func() {
 		for {
-			// Get the symbols to subscribe to.
+			// Get the symbols to subscribe to from the universe, which is
+			// loaded before the stream is run and kept up to date by its
+			// periodic refresh.
 			var symbols []string
+			var version uint64
 			for {
-				var err error
-				symbols, err = b.GetSymbols()
-				if err != nil {
-					log.Printf("binance: failed to get streams: %v", err)
-					goto SymbolsTryAgain
-				}
-				if len(symbols) == 0 {
-					log.Printf("binance: got 0 streams, trying again")
-					goto SymbolsTryAgain
+				version = b.universe.Version()
+				symbols = b.GetSymbols()
+				if len(symbols) > 0 {
+					break
 				}
-				log.Printf("binance: got %d streams\n", len(symbols))
-				break
-			SymbolsTryAgain:
+				log.Printf("binance: got 0 streams, trying again")
 				time.Sleep(1 * time.Second)
 			}
+			log.Printf("binance: got %d streams\n", len(symbols))
 
 			combinedStreamBuilder := binanceapi.NewCombinedStreamBuilder()
 			for _, symbol := range symbols {
@@ -163,6 +162,13 @@ func (b *TradeStream) Run() {
 				b.cache.AddItem(trade.Timestamp(), "trade", body)
 
 				tradeChannel <- trade
+
+				// Resubscribe when a refresh has listed or dropped symbols.
+				if b.universe.Version() != version {
+					log.Printf("binance: symbol universe changed, reconnecting trade stream.")
+					tradeStream.Close()
+					break ReadLoop
+				}
 			}
 
 		}
@@ -212,23 +218,12 @@ func (b *TradeStream) DecodeTrade(body []byte) (*binanceapi.StreamAggTrade, erro
 	return streamEvent.AggTrade, nil
 }
 
-func (b *TradeStream) GetSymbols() ([]string, error) {
-	prices, err := (&binanceapi.RestClient{}).GetPriceTickerAll()
-	if err != nil {
-		log.Errorf("Failed to get all prices for symbol list: %v", err)
-	}
+// GetSymbols returns the lower case symbols of the universe as last
+// loaded.
+func (b *TradeStream) GetSymbols() []string {
 	symbols := []string{}
-	for _, price := range prices {
-		lower := strings.ToLower(price.Symbol)
-		switch {
-		case strings.HasSuffix(lower, "btc"):
-		case strings.HasSuffix(lower, "eth"):
-		case strings.HasSuffix(lower, "bnb"):
-		case strings.HasSuffix(lower, "usdt"):
-		default:
-			continue
-		}
-		symbols = append(symbols, lower)
+	for _, symbol := range b.universe.Symbols() {
+		symbols = append(symbols, strings.ToLower(symbol))
 	}
-	return symbols, nil
+	return symbols
 }
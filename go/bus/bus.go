@@ -0,0 +1,58 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package bus publishes messages to message buses.
+package bus
+
+import (
+	"fmt"
+)
+
+// Sink publishes messages to a message bus. Messages are JSON.
+type Sink interface {
+	Publish(topic string, message []byte) error
+	Close() error
+}
+
+type SinkOptions struct {
+	// nats or tcp.
+	Type string
+
+	// Server URL for NATS, for example nats://localhost:4222.
+	Url string
+
+	// Address for TCP, for example localhost:9000.
+	Address string
+}
+
+// Open returns the sink given by options.
+func Open(options SinkOptions) (Sink, error) {
+	switch options.Type {
+	case "nats":
+		return NewNatsSink(options.Url)
+	case "tcp":
+		return NewTcpSink(options.Address), nil
+	}
+	return nil, fmt.Errorf("unknown message bus type: %s", options.Type)
+}
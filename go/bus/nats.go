@@ -0,0 +1,65 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bus
+
+import (
+	"github.com/nats-io/nats.go"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+)
+
+// NatsSink publishes to a NATS server, the topics being subjects. The
+// client reconnects on its own and buffers messages while disconnected.
+type NatsSink struct {
+	conn *nats.Conn
+}
+
+func NewNatsSink(url string) (*NatsSink, error) {
+	if url == "" {
+		url = nats.DefaultURL
+	}
+	conn, err := nats.Connect(url,
+		nats.Name("cryptoxscanner"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(conn *nats.Conn, err error) {
+			log.WithError(err).Warnf("Disconnected from NATS server")
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			log.Infof("Reconnected to NATS server %s", conn.ConnectedUrl())
+		}))
+	if err != nil {
+		return nil, err
+	}
+	return &NatsSink{
+		conn: conn,
+	}, nil
+}
+
+func (s *NatsSink) Publish(topic string, message []byte) error {
+	return s.conn.Publish(topic, message)
+}
+
+func (s *NatsSink) Close() error {
+	return s.conn.Drain()
+}
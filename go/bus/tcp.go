@@ -0,0 +1,124 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net"
+	"sync"
+	"time"
+)
+
+// How long to wait before reconnecting after a failure. Messages published
+// in the meantime are dropped.
+var tcpRetryInterval = time.Second * 5
+
+const tcpWriteTimeout = time.Second * 5
+
+// TcpLine is a line written by TcpSink.
+type TcpLine struct {
+	Topic   string          `json:"topic"`
+	Message json.RawMessage `json:"message"`
+}
+
+// TcpSink writes each message as a line of JSON to a TCP server, for
+// consumers without a message bus. It connects on the first message and
+// reconnects after failures.
+type TcpSink struct {
+	address   string
+	conn      net.Conn
+	writer    *bufio.Writer
+	lastError time.Time
+	lock      sync.Mutex
+}
+
+func NewTcpSink(address string) *TcpSink {
+	return &TcpSink{
+		address: address,
+	}
+}
+
+func (s *TcpSink) connect() error {
+	if time.Now().Sub(s.lastError) < tcpRetryInterval {
+		return fmt.Errorf("not connected to %s", s.address)
+	}
+	conn, err := net.DialTimeout("tcp", s.address, tcpWriteTimeout)
+	if err != nil {
+		s.lastError = time.Now()
+		log.WithError(err).Warnf("Failed to connect to %s", s.address)
+		return err
+	}
+	log.Infof("Connected to %s", s.address)
+	s.conn = conn
+	s.writer = bufio.NewWriter(conn)
+	return nil
+}
+
+func (s *TcpSink) disconnect() {
+	s.conn.Close()
+	s.conn = nil
+	s.writer = nil
+}
+
+// Publish writes the message as a line. The message must be valid JSON.
+func (s *TcpSink) Publish(topic string, message []byte) error {
+	line, err := json.Marshal(TcpLine{
+		Topic:   topic,
+		Message: message,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(tcpWriteTimeout))
+	s.writer.Write(line)
+	s.writer.WriteByte('\n')
+	if err := s.writer.Flush(); err != nil {
+		s.lastError = time.Now()
+		log.WithError(err).Warnf("Failed to write to %s", s.address)
+		s.disconnect()
+		return err
+	}
+	return nil
+}
+
+func (s *TcpSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.conn != nil {
+		s.disconnect()
+	}
+	return nil
+}
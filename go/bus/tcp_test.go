@@ -0,0 +1,118 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bus
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func readTcpLine(t *testing.T, reader *bufio.Reader) TcpLine {
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcpLine := TcpLine{}
+	if err := json.Unmarshal(line, &tcpLine); err != nil {
+		t.Fatalf("invalid line %q: %v", line, err)
+	}
+	return tcpLine
+}
+
+func TestTcpSink(t *testing.T) {
+	tcpRetryInterval = time.Millisecond * 100
+	defer func() { tcpRetryInterval = time.Second * 5 }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	sink := NewTcpSink(listener.Addr().String())
+	defer sink.Close()
+
+	if err := sink.Publish("trades.ETHBTC", []byte(`{"price":0.03}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Publish("trades.BNBBTC", []byte(`{"price":0.002}`)); err != nil {
+		t.Fatal(err)
+	}
+	conn := <-conns
+	reader := bufio.NewReader(conn)
+	line := readTcpLine(t, reader)
+	if line.Topic != "trades.ETHBTC" || string(line.Message) != `{"price":0.03}` {
+		t.Errorf("unexpected line %+v", line)
+	}
+	line = readTcpLine(t, reader)
+	if line.Topic != "trades.BNBBTC" || string(line.Message) != `{"price":0.002}` {
+		t.Errorf("unexpected line %+v", line)
+	}
+
+	// Writes fail once the server has closed the connection, after which
+	// the sink reconnects and carries on.
+	conn.Close()
+	failed := false
+	deadline := time.Now().Add(time.Second * 5)
+	for !failed && time.Now().Before(deadline) {
+		failed = sink.Publish("trades.ETHBTC", []byte(`{}`)) != nil
+		time.Sleep(time.Millisecond * 10)
+	}
+	if !failed {
+		t.Fatal("publishing to a closed connection did not fail")
+	}
+	for {
+		if err := sink.Publish("alerts.pump", []byte(`{"symbol":"ETHBTC"}`)); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("did not reconnect")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	select {
+	case conn = <-conns:
+	case <-time.After(time.Second * 5):
+		t.Fatal("did not reconnect")
+	}
+	defer conn.Close()
+	line = readTcpLine(t, bufio.NewReader(conn))
+	if line.Topic != "alerts.pump" || string(line.Message) != `{"symbol":"ETHBTC"}` {
+		t.Errorf("unexpected line %+v", line)
+	}
+}
@@ -0,0 +1,62 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/spf13/cobra"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"gitlab.com/crankykernel/cryptoxscanner/server"
+	"os"
+)
+
+var openApiOutput string
+
+// openApiCmd writes the OpenAPI document served at /api/1/openapi.json,
+// used to generate the client in the apiclient package.
+var openApiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Write the OpenAPI document of the REST API",
+	Run: func(cmd *cobra.Command, args []string) {
+		buf, err := json.MarshalIndent(server.OpenApiSpec(), "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode OpenAPI document: %v", err)
+		}
+		buf = append(buf, '\n')
+		if openApiOutput == "" {
+			os.Stdout.Write(buf)
+			return
+		}
+		if err := os.WriteFile(openApiOutput, buf, 0644); err != nil {
+			log.Fatalf("Failed to write %s: %v", openApiOutput, err)
+		}
+	},
+}
+
+func init() {
+	openApiCmd.Flags().StringVar(&openApiOutput, "output", "",
+		"file to write the document to (default is stdout)")
+	rootCmd.AddCommand(openApiCmd)
+}
@@ -26,7 +26,12 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gitlab.com/crankykernel/cryptoxscanner/binance"
+	"gitlab.com/crankykernel/cryptoxscanner/bus"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
 	"gitlab.com/crankykernel/cryptoxscanner/server"
+	"time"
 )
 
 var options server.Options
@@ -34,13 +39,306 @@ var options server.Options
 var binanceCmd = &cobra.Command{
 	Use: "server",
 	Run: func(cmd *cobra.Command, args []string) {
+		options.SymbolFilter = loadSymbolFilter()
+		options.UniverseRefresh = loadUniverseRefresh()
+		options.Metrics = loadMetricsOptions()
+		options.WsQueue = loadWsQueueOptions()
+		options.Limits = loadLimits()
+		options.Proxy = loadProxyOptions()
+		options.Pump = loadPumpOptions()
+		options.Whales = loadWhaleOptions()
+		options.Profiles = loadVolumeProfileOptions()
+		options.Bus = loadBusOptions()
+		options.Snapshots = loadSnapshotOptions()
 		server.ServerMain(options)
 	},
 }
 
+// loadSymbolFilter reads the symbol universe configuration from the
+// "binance" section of the config file, for example:
+//
+//	binance:
+//	  quote-assets: [BTC, USDT, BUSD]
+//	  exclude-symbols: [BCCBTC]
+//	  exclude: ["^.*(UP|DOWN)USDT$"]
+//	  min-volume-24h: 100000
+func loadSymbolFilter() binance.SymbolFilter {
+	return binance.SymbolFilter{
+		QuoteAssets:        viper.GetStringSlice("binance.quote-assets"),
+		ExcludeQuoteAssets: viper.GetStringSlice("binance.exclude-quote-assets"),
+		BaseAssets:         viper.GetStringSlice("binance.base-assets"),
+		ExcludeBaseAssets:  viper.GetStringSlice("binance.exclude-base-assets"),
+		Symbols:            viper.GetStringSlice("binance.symbols"),
+		ExcludeSymbols:     viper.GetStringSlice("binance.exclude-symbols"),
+		Include:            viper.GetStringSlice("binance.include"),
+		Exclude:            viper.GetStringSlice("binance.exclude"),
+		MinVolume24h:       viper.GetFloat64("binance.min-volume-24h"),
+	}
+}
+
+// loadUniverseRefresh reads how often the symbol universe is reloaded to
+// pick up listings and delistings, "binance.refresh-interval", defaulting
+// to an hour. An interval of 0 disables the refresh.
+func loadUniverseRefresh() time.Duration {
+	if !viper.IsSet("binance.refresh-interval") {
+		return time.Hour
+	}
+	interval := viper.GetDuration("binance.refresh-interval")
+	if interval < 0 {
+		log.Fatalf("Invalid binance.refresh-interval: can't be negative")
+	}
+	return interval
+}
+
+// loadMetricsOptions reads the buckets, in minutes, and indicator periods
+// from the "metrics" section of the config file:
+//
+//	metrics:
+//	  buckets: [1, 2, 3, 5, 10, 15, 60]
+//	  rsi-period: 14
+func loadMetricsOptions() server.MetricsOptions {
+	options := server.DefaultMetricsOptions
+	if viper.IsSet("metrics.buckets") {
+		options.Buckets = nil
+		if err := viper.UnmarshalKey("metrics.buckets", &options.Buckets); err != nil {
+			log.Fatalf("Invalid metrics.buckets configuration: %v", err)
+		}
+	}
+	if viper.IsSet("metrics.rsi-period") {
+		options.RSIPeriod = viper.GetInt("metrics.rsi-period")
+	}
+	return options
+}
+
+// loadWsQueueOptions reads the per client WebSocket send queue options from
+// the "websocket" section of the config file.
+func loadWsQueueOptions() server.WsQueueOptions {
+	policy, err := server.ParseWsQueuePolicy(viper.GetString("websocket.queue-policy"))
+	if err != nil {
+		log.Fatalf("Invalid websocket configuration: %v", err)
+	}
+	return server.WsQueueOptions{
+		Depth:  viper.GetInt("websocket.queue-depth"),
+		Policy: policy,
+	}
+}
+
+// loadLimits reads the per client limits from the "limits" section of the
+// config file, for example:
+//
+//	limits:
+//	  ws-connections-per-ip: 16
+//	  api: {rate: 10, burst: 20}
+//	  proxy: {rate: 2, burst: 10}
+//	  routes:
+//	    /api/1/binance/volume: {rate: 1, burst: 5}
+//	  trusted-proxies: [127.0.0.1, 10.0.0.0/8]
+func loadLimits() server.Limits {
+	limits := server.DefaultLimits
+	limits.WsConnectionsPerIP = viper.GetInt("limits.ws-connections-per-ip")
+	if viper.IsSet("limits.trusted-proxies") {
+		limits.TrustedProxies = viper.GetStringSlice("limits.trusted-proxies")
+	}
+	for key, limit := range map[string]*server.RateLimit{
+		"limits.api":   &limits.Api,
+		"limits.proxy": &limits.Proxy,
+	} {
+		if viper.IsSet(key) {
+			if err := viper.UnmarshalKey(key, limit); err != nil {
+				log.Fatalf("Invalid %s configuration: %v", key, err)
+			}
+		}
+	}
+	if err := viper.UnmarshalKey("limits.routes", &limits.Routes); err != nil {
+		log.Fatalf("Invalid limits.routes configuration: %v", err)
+	}
+	return limits
+}
+
+// loadProxyOptions reads the Binance API proxy options. The endpoints, if
+// set, replace the default allowlist:
+//
+//	proxy:
+//	  cache-size: 256
+//	  endpoints:
+//	    /api/v3/depth: 1s
+//	    /api/v3/klines: 5s
+func loadProxyOptions() binance.ProxyOptions {
+	options := binance.DefaultProxyOptions
+	options.WeightPerMinute = viper.GetInt("limits.proxy-weight-per-minute")
+	if viper.IsSet("proxy.cache-size") {
+		options.CacheSize = viper.GetInt("proxy.cache-size")
+	}
+	if viper.IsSet("proxy.endpoints") {
+		options.Endpoints = map[string]time.Duration{}
+		for path, ttl := range viper.GetStringMapString("proxy.endpoints") {
+			duration, err := time.ParseDuration(ttl)
+			if err != nil {
+				log.Fatalf("Invalid TTL for proxy endpoint %s: %v", path, err)
+			}
+			options.Endpoints[path] = duration
+		}
+	}
+	return options
+}
+
+// loadPumpOptions reads the pump detector options from the "pump" section
+// of the config file:
+//
+//	pump:
+//	  start-score: 60
+//	  end-score: 30
+//	  fade-after: 2m
+//	  peak-retrace: 0.25
+//	  end-retrace: 0.5
+//	  max-duration: 1h
+func loadPumpOptions() server.PumpOptions {
+	options := server.DefaultPumpOptions
+	for key, value := range map[string]*float64{
+		"pump.start-score":  &options.StartScore,
+		"pump.end-score":    &options.EndScore,
+		"pump.peak-retrace": &options.PeakRetrace,
+		"pump.end-retrace":  &options.EndRetrace,
+	} {
+		if viper.IsSet(key) {
+			*value = viper.GetFloat64(key)
+		}
+	}
+	for key, value := range map[string]*time.Duration{
+		"pump.fade-after":   &options.FadeAfter,
+		"pump.max-duration": &options.MaxDuration,
+	} {
+		if viper.IsSet(key) {
+			*value = viper.GetDuration(key)
+		}
+	}
+	return options
+}
+
+// loadWhaleOptions reads the whale trade thresholds from the "whales"
+// section of the config file:
+//
+//	whales:
+//	  percentile: 99.5
+//	  min-trades: 200
+//	  min-usd: 50000
+func loadWhaleOptions() server.WhaleOptions {
+	options := server.DefaultWhaleOptions
+	if viper.IsSet("whales.percentile") {
+		options.Percentile = viper.GetFloat64("whales.percentile")
+	}
+	if viper.IsSet("whales.min-trades") {
+		options.MinTrades = viper.GetInt("whales.min-trades")
+	}
+	if viper.IsSet("whales.min-usd") {
+		options.MinUSD = viper.GetFloat64("whales.min-usd")
+	}
+	return options
+}
+
+// loadVolumeProfileOptions reads the volume profile options from the
+// "profile" section of the config file:
+//
+//	profile:
+//	  windows: [15, 60]
+//	  bins: 50
+//	  value-area: 0.7
+func loadVolumeProfileOptions() server.VolumeProfileOptions {
+	options := server.DefaultVolumeProfileOptions
+	if viper.IsSet("profile.windows") {
+		options.Windows = nil
+		if err := viper.UnmarshalKey("profile.windows", &options.Windows); err != nil {
+			log.Fatalf("Invalid profile.windows configuration: %v", err)
+		}
+	}
+	if viper.IsSet("profile.bins") {
+		options.Bins = viper.GetInt("profile.bins")
+	}
+	if viper.IsSet("profile.value-area") {
+		options.ValueArea = viper.GetFloat64("profile.value-area")
+	}
+	for _, window := range options.Windows {
+		if window < 1 || window > 210 {
+			log.Fatalf("Invalid profile window %d, must be 1 to 210 minutes", window)
+		}
+	}
+	if options.Bins < 1 || options.ValueArea <= 0 || options.ValueArea > 1 {
+		log.Fatalf("Invalid profile configuration: bins must be positive and value-area in (0, 1]")
+	}
+	return options
+}
+
+// loadBusOptions reads the message bus options from the "bus" section of
+// the config file. Publishing is disabled unless a type is set:
+//
+//	bus:
+//	  type: nats
+//	  url: nats://localhost:4222
+//	  topics:
+//	    trades: cryptoxscanner.trades.{symbol}
+//	    metrics: ""
+//	    alerts: cryptoxscanner.alerts.{type}
+func loadBusOptions() server.BusOptions {
+	options := server.DefaultBusOptions
+	options.Sink = bus.SinkOptions{
+		Type:    viper.GetString("bus.type"),
+		Url:     viper.GetString("bus.url"),
+		Address: viper.GetString("bus.address"),
+	}
+	for key, value := range map[string]*string{
+		"bus.topics.trades":  &options.TradeTopic,
+		"bus.topics.metrics": &options.MetricsTopic,
+		"bus.topics.alerts":  &options.AlertTopic,
+	} {
+		if viper.IsSet(key) {
+			*value = viper.GetString(key)
+		}
+	}
+	return options
+}
+
+// loadSnapshotOptions reads the metric snapshot options from the
+// "snapshots" section of the config file. An interval of 0 disables
+// snapshots:
+//
+//	snapshots:
+//	  interval: 5m
+//	  retention: 168h
+func loadSnapshotOptions() server.SnapshotOptions {
+	options := server.DefaultSnapshotOptions
+	for key, value := range map[string]*time.Duration{
+		"snapshots.interval":  &options.Interval,
+		"snapshots.retention": &options.Retention,
+	} {
+		if viper.IsSet(key) {
+			*value = viper.GetDuration(key)
+		}
+	}
+	if options.Interval < 0 || (options.Interval > 0 && options.Retention < options.Interval) {
+		log.Fatalf("Invalid snapshots configuration: interval can't be negative and retention must be at least the interval")
+	}
+	return options
+}
+
 func init() {
 	rootCmd.AddCommand(binanceCmd)
 
 	flags := binanceCmd.Flags()
 	flags.Uint16VarP(&options.Port, "port", "p", 6035, "Port to listen on")
+	flags.Uint16Var(&options.GrpcPort, "grpc-port", 0, "Port for the gRPC API, 0 to disable")
+	flags.StringSlice("quote-assets", binance.DefaultQuoteAssets,
+		"Quote assets of the markets to scan")
+	viper.BindPFlag("binance.quote-assets", flags.Lookup("quote-assets"))
+	flags.Int("ws-queue-depth", server.DefaultWsQueueOptions.Depth,
+		"Number of updates queued per WebSocket client subscription")
+	viper.BindPFlag("websocket.queue-depth", flags.Lookup("ws-queue-depth"))
+	flags.String("ws-queue-policy", server.DefaultWsQueueOptions.Policy.String(),
+		"What to do when a WebSocket client queue is full: drop-oldest or disconnect")
+	viper.BindPFlag("websocket.queue-policy", flags.Lookup("ws-queue-policy"))
+	flags.Int("ws-connections-per-ip", server.DefaultLimits.WsConnectionsPerIP,
+		"Maximum WebSocket connections per client IP, 0 for no limit")
+	viper.BindPFlag("limits.ws-connections-per-ip", flags.Lookup("ws-connections-per-ip"))
+	flags.Int("proxy-weight-per-minute", binance.DefaultProxyWeightPerMinute,
+		"Binance request weight per minute the API proxy may use, 0 for no limit")
+	viper.BindPFlag("limits.proxy-weight-per-minute", flags.Lookup("proxy-weight-per-minute"))
 }
@@ -0,0 +1,201 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"os"
+	"sync"
+	"time"
+)
+
+// How long events are kept.
+const defaultEventTtl = time.Hour * 24 * 30
+
+// EventStore persists events detected by the scanner. An event is stored
+// under its id and replaced when it is saved again, so an event can be
+// updated as it develops.
+type EventStore struct {
+	name       string
+	db         *sql.DB
+	lastExpire time.Time
+	lock       sync.Mutex
+}
+
+// EventQuery selects events. Empty fields match everything.
+type EventQuery struct {
+	Type   string
+	Symbol string
+	Since  time.Time
+	Limit  int
+}
+
+func OpenEventStore(name string) (*EventStore, error) {
+	filename := fmt.Sprintf("./%s.sqlite", name)
+
+	if _, err := os.Stat(filename); err != nil {
+		log.Infof("Creating event database %s.", filename)
+	} else {
+		log.Infof("Opening event database %s.", filename)
+	}
+
+	db, err := sql.Open("sqlite3",
+		fmt.Sprintf("%s?cache=shared&mode=rwc&_busy_timeout=3000", filename))
+	if err != nil {
+		return nil, err
+	}
+
+	store := &EventStore{
+		name: name,
+		db:   db,
+	}
+
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Save stores an event, replacing any event with the same id.
+func (s *EventStore) Save(id string, eventType string, symbol string,
+	timestamp time.Time, body []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err := s.db.Exec(`insert or replace into events
+		(id, type, symbol, timestamp, data) values (?, ?, ?, ?, ?)`,
+		id, eventType, symbol, timestamp.Unix(), body)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().Sub(s.lastExpire) > time.Hour {
+		n, err := s.expire(time.Now().Add(-defaultEventTtl))
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"store": s.name,
+			}).Errorf("Failed to purge expired events.")
+		} else if n > 0 {
+			log.WithFields(log.Fields{
+				"store": s.name,
+			}).Debugf("Purged %d expired events.", n)
+		}
+		s.lastExpire = time.Now()
+	}
+
+	return nil
+}
+
+func (s *EventStore) expire(before time.Time) (int64, error) {
+	res, err := s.db.Exec("delete from events where timestamp < ?", before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Query returns the bodies of the matching events, most recent first.
+func (s *EventStore) Query(query EventQuery) ([][]byte, error) {
+	sql := "select data from events where timestamp >= ?"
+	args := []interface{}{query.Since.Unix()}
+	if query.Type != "" {
+		sql += " and type = ?"
+		args = append(args, query.Type)
+	}
+	if query.Symbol != "" {
+		sql += " and symbol = ?"
+		args = append(args, query.Symbol)
+	}
+	sql += " order by timestamp desc"
+	if query.Limit > 0 {
+		sql += " limit ?"
+		args = append(args, query.Limit)
+	}
+
+	rows, err := s.db.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bodies := [][]byte{}
+	for rows.Next() {
+		var body []byte
+		if err := rows.Scan(&body); err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, rows.Err()
+}
+
+func (s *EventStore) migrate() error {
+	var version = 0
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	row := tx.QueryRow("select max(version) from schema")
+	if err := row.Scan(&version); err != nil {
+		log.Infof("Initializing database for event store %s", s.name)
+		_, err := tx.Exec("create table schema (version integer not null primary key, timestamp timestamp)")
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to create schema table: %v", err)
+		}
+		if err := s.incrementVersion(tx, 0); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to insert into schema table: %v", err)
+		}
+		version = 0
+	}
+
+	if version < 1 {
+		log.Infof("Migrating event database to v1.")
+		_, err := tx.Exec(`
+create table events (id text not null primary key, type text, symbol text, timestamp integer, data blob);
+create index events_index on events (timestamp, type);
+`)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := s.incrementVersion(tx, 1); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *EventStore) incrementVersion(tx *sql.Tx, version int) error {
+	_, err := tx.Exec("insert into schema values (?, 'now')", version)
+	return err
+}
@@ -0,0 +1,192 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"os"
+	"sync"
+	"time"
+)
+
+// How long rollups are kept.
+const defaultRollupTtl = time.Hour * 24
+
+// Rollup is the trade activity of a symbol over one minute.
+type Rollup struct {
+	Symbol     string
+	Minute     time.Time
+	BuyVolume  float64
+	SellVolume float64
+	BuyTrades  uint64
+	SellTrades uint64
+
+	// Sums of price times quantity and of quantity, for the VWAP.
+	PriceVolume float64
+	Quantity    float64
+}
+
+// RollupStore persists per minute rollups so windows longer than the
+// trades kept in memory survive a restart.
+type RollupStore struct {
+	name string
+	db   *sql.DB
+	lock sync.Mutex
+}
+
+func OpenRollupStore(name string) (*RollupStore, error) {
+	filename := fmt.Sprintf("./%s.sqlite", name)
+
+	if _, err := os.Stat(filename); err != nil {
+		log.Infof("Creating rollup database %s.", filename)
+	} else {
+		log.Infof("Opening rollup database %s.", filename)
+	}
+
+	db, err := sql.Open("sqlite3",
+		fmt.Sprintf("%s?cache=shared&mode=rwc&_busy_timeout=3000", filename))
+	if err != nil {
+		return nil, err
+	}
+
+	store := &RollupStore{
+		name: name,
+		db:   db,
+	}
+
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Save stores rollups, replacing any for the same symbol and minute, and
+// expires the old ones.
+func (s *RollupStore) Save(rollups []Rollup) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`insert or replace into rollups
+		(symbol, minute, buy_volume, sell_volume, buy_trades, sell_trades, price_volume, quantity)
+		values (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range rollups {
+		if _, err := stmt.Exec(r.Symbol, r.Minute.Unix(), r.BuyVolume, r.SellVolume,
+			r.BuyTrades, r.SellTrades, r.PriceVolume, r.Quantity); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.Exec("delete from rollups where minute < ?",
+		time.Now().Add(-defaultRollupTtl).Unix()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// LoadSince returns the rollups from since on, ordered by symbol and
+// minute.
+func (s *RollupStore) LoadSince(since time.Time) ([]Rollup, error) {
+	rows, err := s.db.Query(`select symbol, minute, buy_volume, sell_volume,
+		buy_trades, sell_trades, price_volume, quantity from rollups
+		where minute >= ? order by symbol, minute`, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rollups := []Rollup{}
+	for rows.Next() {
+		var r Rollup
+		var minute int64
+		if err := rows.Scan(&r.Symbol, &minute, &r.BuyVolume, &r.SellVolume,
+			&r.BuyTrades, &r.SellTrades, &r.PriceVolume, &r.Quantity); err != nil {
+			return nil, err
+		}
+		r.Minute = time.Unix(minute, 0)
+		rollups = append(rollups, r)
+	}
+	return rollups, rows.Err()
+}
+
+func (s *RollupStore) migrate() error {
+	var version = 0
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	row := tx.QueryRow("select max(version) from schema")
+	if err := row.Scan(&version); err != nil {
+		log.Infof("Initializing database for rollup store %s", s.name)
+		_, err := tx.Exec("create table schema (version integer not null primary key, timestamp timestamp)")
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to create schema table: %v", err)
+		}
+		if err := s.incrementVersion(tx, 0); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to insert into schema table: %v", err)
+		}
+		version = 0
+	}
+
+	if version < 1 {
+		log.Infof("Migrating rollup database to v1.")
+		_, err := tx.Exec(`
+create table rollups (symbol text not null, minute integer not null,
+  buy_volume real, sell_volume real, buy_trades integer, sell_trades integer,
+  price_volume real, quantity real, primary key (symbol, minute));
+create index rollups_minute on rollups (minute);
+`)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := s.incrementVersion(tx, 1); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *RollupStore) incrementVersion(tx *sql.Tx, version int) error {
+	_, err := tx.Exec("insert into schema values (?, 'now')", version)
+	return err
+}
@@ -0,0 +1,198 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// SnapshotStore persists periodic snapshots of the metrics of all symbols.
+// A snapshot is stored as one gzip compressed document, the caller decides
+// its format.
+type SnapshotStore struct {
+	name      string
+	db        *sql.DB
+	retention time.Duration
+	lock      sync.Mutex
+}
+
+func OpenSnapshotStore(name string, retention time.Duration) (*SnapshotStore, error) {
+	filename := fmt.Sprintf("./%s.sqlite", name)
+
+	if _, err := os.Stat(filename); err != nil {
+		log.Infof("Creating snapshot database %s.", filename)
+	} else {
+		log.Infof("Opening snapshot database %s.", filename)
+	}
+
+	db, err := sql.Open("sqlite3",
+		fmt.Sprintf("%s?cache=shared&mode=rwc&_busy_timeout=3000", filename))
+	if err != nil {
+		return nil, err
+	}
+
+	store := &SnapshotStore{
+		name:      name,
+		db:        db,
+		retention: retention,
+	}
+
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Save stores a snapshot, replacing any taken in the same second, and
+// expires the snapshots older than the retention.
+func (s *SnapshotStore) Save(timestamp time.Time, body []byte) error {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("insert or replace into snapshots (timestamp, body) values (?, ?)",
+		timestamp.Unix(), buf.Bytes()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec("delete from snapshots where timestamp < ?",
+		time.Now().Add(-s.retention).Unix()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Times returns the times of the snapshots taken from since to until,
+// oldest first.
+func (s *SnapshotStore) Times(since time.Time, until time.Time) ([]time.Time, error) {
+	rows, err := s.db.Query(`select timestamp from snapshots
+		where timestamp >= ? and timestamp <= ? order by timestamp`,
+		since.Unix(), until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := []time.Time{}
+	for rows.Next() {
+		var timestamp int64
+		if err := rows.Scan(&timestamp); err != nil {
+			return nil, err
+		}
+		times = append(times, time.Unix(timestamp, 0))
+	}
+	return times, rows.Err()
+}
+
+// LoadAt returns the most recent snapshot taken at or before at and its
+// time, or a nil body if there is none.
+func (s *SnapshotStore) LoadAt(at time.Time) (time.Time, []byte, error) {
+	var timestamp int64
+	var compressed []byte
+	row := s.db.QueryRow(`select timestamp, body from snapshots
+		where timestamp <= ? order by timestamp desc limit 1`, at.Unix())
+	if err := row.Scan(&timestamp, &compressed); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil, nil
+		}
+		return time.Time{}, nil, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return time.Unix(timestamp, 0), body, nil
+}
+
+func (s *SnapshotStore) migrate() error {
+	var version = 0
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	row := tx.QueryRow("select max(version) from schema")
+	if err := row.Scan(&version); err != nil {
+		log.Infof("Initializing database for snapshot store %s", s.name)
+		_, err := tx.Exec("create table schema (version integer not null primary key, timestamp timestamp)")
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to create schema table: %v", err)
+		}
+		if err := s.incrementVersion(tx, 0); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to insert into schema table: %v", err)
+		}
+		version = 0
+	}
+
+	if version < 1 {
+		log.Infof("Migrating snapshot database to v1.")
+		_, err := tx.Exec(`
+create table snapshots (timestamp integer not null primary key, body blob not null);
+`)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := s.incrementVersion(tx, 1); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SnapshotStore) incrementVersion(tx *sql.Tx, version int) error {
+	_, err := tx.Exec("insert into schema values (?, 'now')", version)
+	return err
+}
@@ -0,0 +1,1876 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: scanner.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Volumes converted from the quote asset into a common currency.
+type NormalizedVolume struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         float64                `protobuf:"fixed64,1,opt,name=total,proto3" json:"total,omitempty"`
+	Net           float64                `protobuf:"fixed64,2,opt,name=net,proto3" json:"net,omitempty"`
+	Buy           float64                `protobuf:"fixed64,3,opt,name=buy,proto3" json:"buy,omitempty"`
+	Sell          float64                `protobuf:"fixed64,4,opt,name=sell,proto3" json:"sell,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormalizedVolume) Reset() {
+	*x = NormalizedVolume{}
+	mi := &file_scanner_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormalizedVolume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormalizedVolume) ProtoMessage() {}
+
+func (x *NormalizedVolume) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormalizedVolume.ProtoReflect.Descriptor instead.
+func (*NormalizedVolume) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NormalizedVolume) GetTotal() float64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *NormalizedVolume) GetNet() float64 {
+	if x != nil {
+		return x.Net
+	}
+	return 0
+}
+
+func (x *NormalizedVolume) GetBuy() float64 {
+	if x != nil {
+		return x.Buy
+	}
+	return 0
+}
+
+func (x *NormalizedVolume) GetSell() float64 {
+	if x != nil {
+		return x.Sell
+	}
+	return 0
+}
+
+// TickerMetrics mirrors the metrics the server keeps for each bucket.
+type TickerMetrics struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PriceChangePct  float64                `protobuf:"fixed64,1,opt,name=price_change_pct,json=priceChangePct,proto3" json:"price_change_pct,omitempty"`
+	VolumeChangePct float64                `protobuf:"fixed64,2,opt,name=volume_change_pct,json=volumeChangePct,proto3" json:"volume_change_pct,omitempty"`
+	High            float64                `protobuf:"fixed64,3,opt,name=high,proto3" json:"high,omitempty"`
+	Low             float64                `protobuf:"fixed64,4,opt,name=low,proto3" json:"low,omitempty"`
+	Range           float64                `protobuf:"fixed64,5,opt,name=range,proto3" json:"range,omitempty"`
+	RangePct        float64                `protobuf:"fixed64,6,opt,name=range_pct,json=rangePct,proto3" json:"range_pct,omitempty"`
+	Vwap            float64                `protobuf:"fixed64,7,opt,name=vwap,proto3" json:"vwap,omitempty"`
+	VwapSd          float64                `protobuf:"fixed64,8,opt,name=vwap_sd,json=vwapSd,proto3" json:"vwap_sd,omitempty"`
+	TotalVolume     float64                `protobuf:"fixed64,9,opt,name=total_volume,json=totalVolume,proto3" json:"total_volume,omitempty"`
+	NetVolume       float64                `protobuf:"fixed64,10,opt,name=net_volume,json=netVolume,proto3" json:"net_volume,omitempty"`
+	BuyVolume       float64                `protobuf:"fixed64,11,opt,name=buy_volume,json=buyVolume,proto3" json:"buy_volume,omitempty"`
+	SellVolume      float64                `protobuf:"fixed64,12,opt,name=sell_volume,json=sellVolume,proto3" json:"sell_volume,omitempty"`
+	Rsi             float64                `protobuf:"fixed64,13,opt,name=rsi,proto3" json:"rsi,omitempty"`
+	Trades          uint64                 `protobuf:"varint,14,opt,name=trades,proto3" json:"trades,omitempty"`
+	SellTrades      uint64                 `protobuf:"varint,15,opt,name=sell_trades,json=sellTrades,proto3" json:"sell_trades,omitempty"`
+	BuyTrades       uint64                 `protobuf:"varint,16,opt,name=buy_trades,json=buyTrades,proto3" json:"buy_trades,omitempty"`
+	Usd             *NormalizedVolume      `protobuf:"bytes,17,opt,name=usd,proto3" json:"usd,omitempty"`
+	Btc             *NormalizedVolume      `protobuf:"bytes,18,opt,name=btc,proto3" json:"btc,omitempty"`
+	ZPrice          float64                `protobuf:"fixed64,19,opt,name=z_price,json=zPrice,proto3" json:"z_price,omitempty"`
+	ZVolume         float64                `protobuf:"fixed64,20,opt,name=z_volume,json=zVolume,proto3" json:"z_volume,omitempty"`
+	ZTrades         float64                `protobuf:"fixed64,21,opt,name=z_trades,json=zTrades,proto3" json:"z_trades,omitempty"`
+	BetaBtc         float64                `protobuf:"fixed64,22,opt,name=beta_btc,json=betaBtc,proto3" json:"beta_btc,omitempty"`
+	CorrBtc         float64                `protobuf:"fixed64,23,opt,name=corr_btc,json=corrBtc,proto3" json:"corr_btc,omitempty"`
+	RsBtc           float64                `protobuf:"fixed64,24,opt,name=rs_btc,json=rsBtc,proto3" json:"rs_btc,omitempty"`
+	BetaMarket      float64                `protobuf:"fixed64,25,opt,name=beta_market,json=betaMarket,proto3" json:"beta_market,omitempty"`
+	CorrMarket      float64                `protobuf:"fixed64,26,opt,name=corr_market,json=corrMarket,proto3" json:"corr_market,omitempty"`
+	RsMarket        float64                `protobuf:"fixed64,27,opt,name=rs_market,json=rsMarket,proto3" json:"rs_market,omitempty"`
+	WhaleBuyVolume  float64                `protobuf:"fixed64,28,opt,name=whale_buy_volume,json=whaleBuyVolume,proto3" json:"whale_buy_volume,omitempty"`
+	WhaleSellVolume float64                `protobuf:"fixed64,29,opt,name=whale_sell_volume,json=whaleSellVolume,proto3" json:"whale_sell_volume,omitempty"`
+	WhaleTrades     uint64                 `protobuf:"varint,30,opt,name=whale_trades,json=whaleTrades,proto3" json:"whale_trades,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TickerMetrics) Reset() {
+	*x = TickerMetrics{}
+	mi := &file_scanner_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TickerMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TickerMetrics) ProtoMessage() {}
+
+func (x *TickerMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TickerMetrics.ProtoReflect.Descriptor instead.
+func (*TickerMetrics) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TickerMetrics) GetPriceChangePct() float64 {
+	if x != nil {
+		return x.PriceChangePct
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetVolumeChangePct() float64 {
+	if x != nil {
+		return x.VolumeChangePct
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetHigh() float64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetLow() float64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetRange() float64 {
+	if x != nil {
+		return x.Range
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetRangePct() float64 {
+	if x != nil {
+		return x.RangePct
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetVwap() float64 {
+	if x != nil {
+		return x.Vwap
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetVwapSd() float64 {
+	if x != nil {
+		return x.VwapSd
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetTotalVolume() float64 {
+	if x != nil {
+		return x.TotalVolume
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetNetVolume() float64 {
+	if x != nil {
+		return x.NetVolume
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetBuyVolume() float64 {
+	if x != nil {
+		return x.BuyVolume
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetSellVolume() float64 {
+	if x != nil {
+		return x.SellVolume
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetRsi() float64 {
+	if x != nil {
+		return x.Rsi
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetTrades() uint64 {
+	if x != nil {
+		return x.Trades
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetSellTrades() uint64 {
+	if x != nil {
+		return x.SellTrades
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetBuyTrades() uint64 {
+	if x != nil {
+		return x.BuyTrades
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetUsd() *NormalizedVolume {
+	if x != nil {
+		return x.Usd
+	}
+	return nil
+}
+
+func (x *TickerMetrics) GetBtc() *NormalizedVolume {
+	if x != nil {
+		return x.Btc
+	}
+	return nil
+}
+
+func (x *TickerMetrics) GetZPrice() float64 {
+	if x != nil {
+		return x.ZPrice
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetZVolume() float64 {
+	if x != nil {
+		return x.ZVolume
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetZTrades() float64 {
+	if x != nil {
+		return x.ZTrades
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetBetaBtc() float64 {
+	if x != nil {
+		return x.BetaBtc
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetCorrBtc() float64 {
+	if x != nil {
+		return x.CorrBtc
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetRsBtc() float64 {
+	if x != nil {
+		return x.RsBtc
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetBetaMarket() float64 {
+	if x != nil {
+		return x.BetaMarket
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetCorrMarket() float64 {
+	if x != nil {
+		return x.CorrMarket
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetRsMarket() float64 {
+	if x != nil {
+		return x.RsMarket
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetWhaleBuyVolume() float64 {
+	if x != nil {
+		return x.WhaleBuyVolume
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetWhaleSellVolume() float64 {
+	if x != nil {
+		return x.WhaleSellVolume
+	}
+	return 0
+}
+
+func (x *TickerMetrics) GetWhaleTrades() uint64 {
+	if x != nil {
+		return x.WhaleTrades
+	}
+	return 0
+}
+
+// Aggregate is a candle of a bucket.
+type Aggregate struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Time            int64                  `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Open            float64                `protobuf:"fixed64,2,opt,name=open,proto3" json:"open,omitempty"`
+	High            float64                `protobuf:"fixed64,3,opt,name=high,proto3" json:"high,omitempty"`
+	Low             float64                `protobuf:"fixed64,4,opt,name=low,proto3" json:"low,omitempty"`
+	Close           float64                `protobuf:"fixed64,5,opt,name=close,proto3" json:"close,omitempty"`
+	QuoteVolume_24H float64                `protobuf:"fixed64,6,opt,name=quote_volume_24h,json=quoteVolume24h,proto3" json:"quote_volume_24h,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Aggregate) Reset() {
+	*x = Aggregate{}
+	mi := &file_scanner_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Aggregate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Aggregate) ProtoMessage() {}
+
+func (x *Aggregate) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Aggregate.ProtoReflect.Descriptor instead.
+func (*Aggregate) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Aggregate) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Aggregate) GetOpen() float64 {
+	if x != nil {
+		return x.Open
+	}
+	return 0
+}
+
+func (x *Aggregate) GetHigh() float64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *Aggregate) GetLow() float64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *Aggregate) GetClose() float64 {
+	if x != nil {
+		return x.Close
+	}
+	return 0
+}
+
+func (x *Aggregate) GetQuoteVolume_24H() float64 {
+	if x != nil {
+		return x.QuoteVolume_24H
+	}
+	return 0
+}
+
+type Candles struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Aggregates    []*Aggregate           `protobuf:"bytes,1,rep,name=aggregates,proto3" json:"aggregates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candles) Reset() {
+	*x = Candles{}
+	mi := &file_scanner_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candles) ProtoMessage() {}
+
+func (x *Candles) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candles.ProtoReflect.Descriptor instead.
+func (*Candles) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Candles) GetAggregates() []*Aggregate {
+	if x != nil {
+		return x.Aggregates
+	}
+	return nil
+}
+
+type Ticker struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Symbol             string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Base               string                 `protobuf:"bytes,2,opt,name=base,proto3" json:"base,omitempty"`
+	Quote              string                 `protobuf:"bytes,3,opt,name=quote,proto3" json:"quote,omitempty"`
+	Close              float64                `protobuf:"fixed64,4,opt,name=close,proto3" json:"close,omitempty"`
+	Bid                float64                `protobuf:"fixed64,5,opt,name=bid,proto3" json:"bid,omitempty"`
+	Ask                float64                `protobuf:"fixed64,6,opt,name=ask,proto3" json:"ask,omitempty"`
+	High               float64                `protobuf:"fixed64,7,opt,name=high,proto3" json:"high,omitempty"`
+	Low                float64                `protobuf:"fixed64,8,opt,name=low,proto3" json:"low,omitempty"`
+	Volume             float64                `protobuf:"fixed64,9,opt,name=volume,proto3" json:"volume,omitempty"`
+	VolumeUsd          float64                `protobuf:"fixed64,10,opt,name=volume_usd,json=volumeUsd,proto3" json:"volume_usd,omitempty"`
+	VolumeBtc          float64                `protobuf:"fixed64,11,opt,name=volume_btc,json=volumeBtc,proto3" json:"volume_btc,omitempty"`
+	PriceChangePct_24H float64                `protobuf:"fixed64,12,opt,name=price_change_pct_24h,json=priceChangePct24h,proto3" json:"price_change_pct_24h,omitempty"`
+	Range_24H          float64                `protobuf:"fixed64,13,opt,name=range_24h,json=range24h,proto3" json:"range_24h,omitempty"`
+	RangePct_24H       float64                `protobuf:"fixed64,14,opt,name=range_pct_24h,json=rangePct24h,proto3" json:"range_pct_24h,omitempty"`
+	PumpScore          float64                `protobuf:"fixed64,15,opt,name=pump_score,json=pumpScore,proto3" json:"pump_score,omitempty"`
+	Timestamp          int64                  `protobuf:"varint,16,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Keyed by bucket in minutes.
+	Metrics map[int32]*TickerMetrics `protobuf:"bytes,17,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Keyed by bucket in minutes, only set by GetSymbol.
+	Candles       map[int32]*Candles `protobuf:"bytes,18,rep,name=candles,proto3" json:"candles,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ticker) Reset() {
+	*x = Ticker{}
+	mi := &file_scanner_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ticker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ticker) ProtoMessage() {}
+
+func (x *Ticker) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ticker.ProtoReflect.Descriptor instead.
+func (*Ticker) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Ticker) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Ticker) GetBase() string {
+	if x != nil {
+		return x.Base
+	}
+	return ""
+}
+
+func (x *Ticker) GetQuote() string {
+	if x != nil {
+		return x.Quote
+	}
+	return ""
+}
+
+func (x *Ticker) GetClose() float64 {
+	if x != nil {
+		return x.Close
+	}
+	return 0
+}
+
+func (x *Ticker) GetBid() float64 {
+	if x != nil {
+		return x.Bid
+	}
+	return 0
+}
+
+func (x *Ticker) GetAsk() float64 {
+	if x != nil {
+		return x.Ask
+	}
+	return 0
+}
+
+func (x *Ticker) GetHigh() float64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *Ticker) GetLow() float64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *Ticker) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *Ticker) GetVolumeUsd() float64 {
+	if x != nil {
+		return x.VolumeUsd
+	}
+	return 0
+}
+
+func (x *Ticker) GetVolumeBtc() float64 {
+	if x != nil {
+		return x.VolumeBtc
+	}
+	return 0
+}
+
+func (x *Ticker) GetPriceChangePct_24H() float64 {
+	if x != nil {
+		return x.PriceChangePct_24H
+	}
+	return 0
+}
+
+func (x *Ticker) GetRange_24H() float64 {
+	if x != nil {
+		return x.Range_24H
+	}
+	return 0
+}
+
+func (x *Ticker) GetRangePct_24H() float64 {
+	if x != nil {
+		return x.RangePct_24H
+	}
+	return 0
+}
+
+func (x *Ticker) GetPumpScore() float64 {
+	if x != nil {
+		return x.PumpScore
+	}
+	return 0
+}
+
+func (x *Ticker) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Ticker) GetMetrics() map[int32]*TickerMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *Ticker) GetCandles() map[int32]*Candles {
+	if x != nil {
+		return x.Candles
+	}
+	return nil
+}
+
+type GetSymbolRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Symbol string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// Number of the most recent candles of each bucket, none if 0.
+	Candles       int32 `protobuf:"varint,2,opt,name=candles,proto3" json:"candles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSymbolRequest) Reset() {
+	*x = GetSymbolRequest{}
+	mi := &file_scanner_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSymbolRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSymbolRequest) ProtoMessage() {}
+
+func (x *GetSymbolRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSymbolRequest.ProtoReflect.Descriptor instead.
+func (*GetSymbolRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetSymbolRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetSymbolRequest) GetCandles() int32 {
+	if x != nil {
+		return x.Candles
+	}
+	return 0
+}
+
+type ScreenerRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Metric to rank by, as named in the live feed, default volume.
+	Sort      string `protobuf:"bytes,1,opt,name=sort,proto3" json:"sort,omitempty"`
+	Ascending bool   `protobuf:"varint,2,opt,name=ascending,proto3" json:"ascending,omitempty"`
+	// Default 50, at most 1000.
+	Limit       int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset      int32    `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	QuoteAssets []string `protobuf:"bytes,5,rep,name=quote_assets,json=quoteAssets,proto3" json:"quote_assets,omitempty"`
+	MinVolume   float64  `protobuf:"fixed64,6,opt,name=min_volume,json=minVolume,proto3" json:"min_volume,omitempty"`
+	// Metric conditions such as nv_15>1000.
+	Filters       []string `protobuf:"bytes,7,rep,name=filters,proto3" json:"filters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenerRequest) Reset() {
+	*x = ScreenerRequest{}
+	mi := &file_scanner_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenerRequest) ProtoMessage() {}
+
+func (x *ScreenerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenerRequest.ProtoReflect.Descriptor instead.
+func (*ScreenerRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ScreenerRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ScreenerRequest) GetAscending() bool {
+	if x != nil {
+		return x.Ascending
+	}
+	return false
+}
+
+func (x *ScreenerRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ScreenerRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ScreenerRequest) GetQuoteAssets() []string {
+	if x != nil {
+		return x.QuoteAssets
+	}
+	return nil
+}
+
+func (x *ScreenerRequest) GetMinVolume() float64 {
+	if x != nil {
+		return x.MinVolume
+	}
+	return 0
+}
+
+func (x *ScreenerRequest) GetFilters() []string {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+type ScreenerResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of tickers that matched.
+	Total int32 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	// Ranked from offset + 1.
+	Tickers       []*Ticker `protobuf:"bytes,2,rep,name=tickers,proto3" json:"tickers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenerResponse) Reset() {
+	*x = ScreenerResponse{}
+	mi := &file_scanner_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenerResponse) ProtoMessage() {}
+
+func (x *ScreenerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenerResponse.ProtoReflect.Descriptor instead.
+func (*ScreenerResponse) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ScreenerResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ScreenerResponse) GetTickers() []*Ticker {
+	if x != nil {
+		return x.Tickers
+	}
+	return nil
+}
+
+type StreamTickersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Symbols to send, all if empty.
+	Symbols []string `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	// Minimum seconds between updates.
+	UpdateInterval int32 `protobuf:"varint,2,opt,name=update_interval,json=updateInterval,proto3" json:"update_interval,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StreamTickersRequest) Reset() {
+	*x = StreamTickersRequest{}
+	mi := &file_scanner_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTickersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTickersRequest) ProtoMessage() {}
+
+func (x *StreamTickersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTickersRequest.ProtoReflect.Descriptor instead.
+func (*StreamTickersRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamTickersRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *StreamTickersRequest) GetUpdateInterval() int32 {
+	if x != nil {
+		return x.UpdateInterval
+	}
+	return 0
+}
+
+type TickerUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tickers       []*Ticker              `protobuf:"bytes,1,rep,name=tickers,proto3" json:"tickers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TickerUpdate) Reset() {
+	*x = TickerUpdate{}
+	mi := &file_scanner_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TickerUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TickerUpdate) ProtoMessage() {}
+
+func (x *TickerUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TickerUpdate.ProtoReflect.Descriptor instead.
+func (*TickerUpdate) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TickerUpdate) GetTickers() []*Ticker {
+	if x != nil {
+		return x.Tickers
+	}
+	return nil
+}
+
+type StreamTradesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Symbols to send, all if empty.
+	Symbols       []string `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTradesRequest) Reset() {
+	*x = StreamTradesRequest{}
+	mi := &file_scanner_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTradesRequest) ProtoMessage() {}
+
+func (x *StreamTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTradesRequest.ProtoReflect.Descriptor instead.
+func (*StreamTradesRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StreamTradesRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type Trade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	BuyerMaker    bool                   `protobuf:"varint,5,opt,name=buyer_maker,json=buyerMaker,proto3" json:"buyer_maker,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trade) Reset() {
+	*x = Trade{}
+	mi := &file_scanner_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trade) ProtoMessage() {}
+
+func (x *Trade) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trade.ProtoReflect.Descriptor instead.
+func (*Trade) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Trade) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Trade) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Trade) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Trade) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Trade) GetBuyerMaker() bool {
+	if x != nil {
+		return x.BuyerMaker
+	}
+	return false
+}
+
+func (x *Trade) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type PumpScore struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Score         float64                `protobuf:"fixed64,1,opt,name=score,proto3" json:"score,omitempty"`
+	Price         float64                `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	Acceleration  float64                `protobuf:"fixed64,3,opt,name=acceleration,proto3" json:"acceleration,omitempty"`
+	BuyRatio      float64                `protobuf:"fixed64,4,opt,name=buy_ratio,json=buyRatio,proto3" json:"buy_ratio,omitempty"`
+	NetVolume     float64                `protobuf:"fixed64,5,opt,name=net_volume,json=netVolume,proto3" json:"net_volume,omitempty"`
+	Trades        float64                `protobuf:"fixed64,6,opt,name=trades,proto3" json:"trades,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PumpScore) Reset() {
+	*x = PumpScore{}
+	mi := &file_scanner_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PumpScore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PumpScore) ProtoMessage() {}
+
+func (x *PumpScore) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PumpScore.ProtoReflect.Descriptor instead.
+func (*PumpScore) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PumpScore) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *PumpScore) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *PumpScore) GetAcceleration() float64 {
+	if x != nil {
+		return x.Acceleration
+	}
+	return 0
+}
+
+func (x *PumpScore) GetBuyRatio() float64 {
+	if x != nil {
+		return x.BuyRatio
+	}
+	return 0
+}
+
+func (x *PumpScore) GetNetVolume() float64 {
+	if x != nil {
+		return x.NetVolume
+	}
+	return 0
+}
+
+func (x *PumpScore) GetTrades() float64 {
+	if x != nil {
+		return x.Trades
+	}
+	return 0
+}
+
+type PumpEvent struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Symbol string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// start, peak or fade.
+	Stage      string  `protobuf:"bytes,3,opt,name=stage,proto3" json:"stage,omitempty"`
+	Start      int64   `protobuf:"varint,4,opt,name=start,proto3" json:"start,omitempty"`
+	StartPrice float64 `protobuf:"fixed64,5,opt,name=start_price,json=startPrice,proto3" json:"start_price,omitempty"`
+	PeakTime   int64   `protobuf:"varint,6,opt,name=peak_time,json=peakTime,proto3" json:"peak_time,omitempty"`
+	PeakPrice  float64 `protobuf:"fixed64,7,opt,name=peak_price,json=peakPrice,proto3" json:"peak_price,omitempty"`
+	PeakScore  float64 `protobuf:"fixed64,8,opt,name=peak_score,json=peakScore,proto3" json:"peak_score,omitempty"`
+	// Zero while the pump is active.
+	End           int64      `protobuf:"varint,9,opt,name=end,proto3" json:"end,omitempty"`
+	Price         float64    `protobuf:"fixed64,10,opt,name=price,proto3" json:"price,omitempty"`
+	Score         *PumpScore `protobuf:"bytes,11,opt,name=score,proto3" json:"score,omitempty"`
+	GainPct       float64    `protobuf:"fixed64,12,opt,name=gain_pct,json=gainPct,proto3" json:"gain_pct,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PumpEvent) Reset() {
+	*x = PumpEvent{}
+	mi := &file_scanner_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PumpEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PumpEvent) ProtoMessage() {}
+
+func (x *PumpEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PumpEvent.ProtoReflect.Descriptor instead.
+func (*PumpEvent) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PumpEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PumpEvent) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *PumpEvent) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *PumpEvent) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *PumpEvent) GetStartPrice() float64 {
+	if x != nil {
+		return x.StartPrice
+	}
+	return 0
+}
+
+func (x *PumpEvent) GetPeakTime() int64 {
+	if x != nil {
+		return x.PeakTime
+	}
+	return 0
+}
+
+func (x *PumpEvent) GetPeakPrice() float64 {
+	if x != nil {
+		return x.PeakPrice
+	}
+	return 0
+}
+
+func (x *PumpEvent) GetPeakScore() float64 {
+	if x != nil {
+		return x.PeakScore
+	}
+	return 0
+}
+
+func (x *PumpEvent) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *PumpEvent) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *PumpEvent) GetScore() *PumpScore {
+	if x != nil {
+		return x.Score
+	}
+	return nil
+}
+
+func (x *PumpEvent) GetGainPct() float64 {
+	if x != nil {
+		return x.GainPct
+	}
+	return 0
+}
+
+type WhaleTrade struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Symbol string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// buy or sell.
+	Side          string  `protobuf:"bytes,2,opt,name=side,proto3" json:"side,omitempty"`
+	Price         float64 `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity      float64 `protobuf:"fixed64,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	QuoteQuantity float64 `protobuf:"fixed64,5,opt,name=quote_quantity,json=quoteQuantity,proto3" json:"quote_quantity,omitempty"`
+	// NaN if no conversion rate is known.
+	Usd           float64 `protobuf:"fixed64,6,opt,name=usd,proto3" json:"usd,omitempty"`
+	Threshold     float64 `protobuf:"fixed64,7,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Timestamp     int64   `protobuf:"varint,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ImpactPct     float64 `protobuf:"fixed64,9,opt,name=impact_pct,json=impactPct,proto3" json:"impact_pct,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WhaleTrade) Reset() {
+	*x = WhaleTrade{}
+	mi := &file_scanner_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WhaleTrade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WhaleTrade) ProtoMessage() {}
+
+func (x *WhaleTrade) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WhaleTrade.ProtoReflect.Descriptor instead.
+func (*WhaleTrade) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WhaleTrade) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *WhaleTrade) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+func (x *WhaleTrade) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *WhaleTrade) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *WhaleTrade) GetQuoteQuantity() float64 {
+	if x != nil {
+		return x.QuoteQuantity
+	}
+	return 0
+}
+
+func (x *WhaleTrade) GetUsd() float64 {
+	if x != nil {
+		return x.Usd
+	}
+	return 0
+}
+
+func (x *WhaleTrade) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *WhaleTrade) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *WhaleTrade) GetImpactPct() float64 {
+	if x != nil {
+		return x.ImpactPct
+	}
+	return 0
+}
+
+type StreamAlertsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Alert types to send, pump and whale, all if empty.
+	Types []string `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	// Symbols to send, all if empty.
+	Symbols       []string `protobuf:"bytes,2,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamAlertsRequest) Reset() {
+	*x = StreamAlertsRequest{}
+	mi := &file_scanner_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamAlertsRequest) ProtoMessage() {}
+
+func (x *StreamAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamAlertsRequest.ProtoReflect.Descriptor instead.
+func (*StreamAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StreamAlertsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *StreamAlertsRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type Alert struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Type   string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Symbol string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*Alert_Pump
+	//	*Alert_Whale
+	Event         isAlert_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_scanner_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Alert) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Alert) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Alert) GetEvent() isAlert_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *Alert) GetPump() *PumpEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Alert_Pump); ok {
+			return x.Pump
+		}
+	}
+	return nil
+}
+
+func (x *Alert) GetWhale() *WhaleTrade {
+	if x != nil {
+		if x, ok := x.Event.(*Alert_Whale); ok {
+			return x.Whale
+		}
+	}
+	return nil
+}
+
+type isAlert_Event interface {
+	isAlert_Event()
+}
+
+type Alert_Pump struct {
+	Pump *PumpEvent `protobuf:"bytes,3,opt,name=pump,proto3,oneof"`
+}
+
+type Alert_Whale struct {
+	Whale *WhaleTrade `protobuf:"bytes,4,opt,name=whale,proto3,oneof"`
+}
+
+func (*Alert_Pump) isAlert_Event() {}
+
+func (*Alert_Whale) isAlert_Event() {}
+
+var File_scanner_proto protoreflect.FileDescriptor
+
+const file_scanner_proto_rawDesc = "" +
+	"\n" +
+	"\rscanner.proto\x12\x0ecryptoxscanner\"`\n" +
+	"\x10NormalizedVolume\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x01R\x05total\x12\x10\n" +
+	"\x03net\x18\x02 \x01(\x01R\x03net\x12\x10\n" +
+	"\x03buy\x18\x03 \x01(\x01R\x03buy\x12\x12\n" +
+	"\x04sell\x18\x04 \x01(\x01R\x04sell\"\xb3\a\n" +
+	"\rTickerMetrics\x12(\n" +
+	"\x10price_change_pct\x18\x01 \x01(\x01R\x0epriceChangePct\x12*\n" +
+	"\x11volume_change_pct\x18\x02 \x01(\x01R\x0fvolumeChangePct\x12\x12\n" +
+	"\x04high\x18\x03 \x01(\x01R\x04high\x12\x10\n" +
+	"\x03low\x18\x04 \x01(\x01R\x03low\x12\x14\n" +
+	"\x05range\x18\x05 \x01(\x01R\x05range\x12\x1b\n" +
+	"\trange_pct\x18\x06 \x01(\x01R\brangePct\x12\x12\n" +
+	"\x04vwap\x18\a \x01(\x01R\x04vwap\x12\x17\n" +
+	"\avwap_sd\x18\b \x01(\x01R\x06vwapSd\x12!\n" +
+	"\ftotal_volume\x18\t \x01(\x01R\vtotalVolume\x12\x1d\n" +
+	"\n" +
+	"net_volume\x18\n" +
+	" \x01(\x01R\tnetVolume\x12\x1d\n" +
+	"\n" +
+	"buy_volume\x18\v \x01(\x01R\tbuyVolume\x12\x1f\n" +
+	"\vsell_volume\x18\f \x01(\x01R\n" +
+	"sellVolume\x12\x10\n" +
+	"\x03rsi\x18\r \x01(\x01R\x03rsi\x12\x16\n" +
+	"\x06trades\x18\x0e \x01(\x04R\x06trades\x12\x1f\n" +
+	"\vsell_trades\x18\x0f \x01(\x04R\n" +
+	"sellTrades\x12\x1d\n" +
+	"\n" +
+	"buy_trades\x18\x10 \x01(\x04R\tbuyTrades\x122\n" +
+	"\x03usd\x18\x11 \x01(\v2 .cryptoxscanner.NormalizedVolumeR\x03usd\x122\n" +
+	"\x03btc\x18\x12 \x01(\v2 .cryptoxscanner.NormalizedVolumeR\x03btc\x12\x17\n" +
+	"\az_price\x18\x13 \x01(\x01R\x06zPrice\x12\x19\n" +
+	"\bz_volume\x18\x14 \x01(\x01R\azVolume\x12\x19\n" +
+	"\bz_trades\x18\x15 \x01(\x01R\azTrades\x12\x19\n" +
+	"\bbeta_btc\x18\x16 \x01(\x01R\abetaBtc\x12\x19\n" +
+	"\bcorr_btc\x18\x17 \x01(\x01R\acorrBtc\x12\x15\n" +
+	"\x06rs_btc\x18\x18 \x01(\x01R\x05rsBtc\x12\x1f\n" +
+	"\vbeta_market\x18\x19 \x01(\x01R\n" +
+	"betaMarket\x12\x1f\n" +
+	"\vcorr_market\x18\x1a \x01(\x01R\n" +
+	"corrMarket\x12\x1b\n" +
+	"\trs_market\x18\x1b \x01(\x01R\brsMarket\x12(\n" +
+	"\x10whale_buy_volume\x18\x1c \x01(\x01R\x0ewhaleBuyVolume\x12*\n" +
+	"\x11whale_sell_volume\x18\x1d \x01(\x01R\x0fwhaleSellVolume\x12!\n" +
+	"\fwhale_trades\x18\x1e \x01(\x04R\vwhaleTrades\"\x99\x01\n" +
+	"\tAggregate\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x12\n" +
+	"\x04open\x18\x02 \x01(\x01R\x04open\x12\x12\n" +
+	"\x04high\x18\x03 \x01(\x01R\x04high\x12\x10\n" +
+	"\x03low\x18\x04 \x01(\x01R\x03low\x12\x14\n" +
+	"\x05close\x18\x05 \x01(\x01R\x05close\x12(\n" +
+	"\x10quote_volume_24h\x18\x06 \x01(\x01R\x0equoteVolume24h\"D\n" +
+	"\aCandles\x129\n" +
+	"\n" +
+	"aggregates\x18\x01 \x03(\v2\x19.cryptoxscanner.AggregateR\n" +
+	"aggregates\"\xdd\x05\n" +
+	"\x06Ticker\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04base\x18\x02 \x01(\tR\x04base\x12\x14\n" +
+	"\x05quote\x18\x03 \x01(\tR\x05quote\x12\x14\n" +
+	"\x05close\x18\x04 \x01(\x01R\x05close\x12\x10\n" +
+	"\x03bid\x18\x05 \x01(\x01R\x03bid\x12\x10\n" +
+	"\x03ask\x18\x06 \x01(\x01R\x03ask\x12\x12\n" +
+	"\x04high\x18\a \x01(\x01R\x04high\x12\x10\n" +
+	"\x03low\x18\b \x01(\x01R\x03low\x12\x16\n" +
+	"\x06volume\x18\t \x01(\x01R\x06volume\x12\x1d\n" +
+	"\n" +
+	"volume_usd\x18\n" +
+	" \x01(\x01R\tvolumeUsd\x12\x1d\n" +
+	"\n" +
+	"volume_btc\x18\v \x01(\x01R\tvolumeBtc\x12/\n" +
+	"\x14price_change_pct_24h\x18\f \x01(\x01R\x11priceChangePct24h\x12\x1b\n" +
+	"\trange_24h\x18\r \x01(\x01R\brange24h\x12\"\n" +
+	"\rrange_pct_24h\x18\x0e \x01(\x01R\vrangePct24h\x12\x1d\n" +
+	"\n" +
+	"pump_score\x18\x0f \x01(\x01R\tpumpScore\x12\x1c\n" +
+	"\ttimestamp\x18\x10 \x01(\x03R\ttimestamp\x12=\n" +
+	"\ametrics\x18\x11 \x03(\v2#.cryptoxscanner.Ticker.MetricsEntryR\ametrics\x12=\n" +
+	"\acandles\x18\x12 \x03(\v2#.cryptoxscanner.Ticker.CandlesEntryR\acandles\x1aY\n" +
+	"\fMetricsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x123\n" +
+	"\x05value\x18\x02 \x01(\v2\x1d.cryptoxscanner.TickerMetricsR\x05value:\x028\x01\x1aS\n" +
+	"\fCandlesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.cryptoxscanner.CandlesR\x05value:\x028\x01\"D\n" +
+	"\x10GetSymbolRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x18\n" +
+	"\acandles\x18\x02 \x01(\x05R\acandles\"\xcd\x01\n" +
+	"\x0fScreenerRequest\x12\x12\n" +
+	"\x04sort\x18\x01 \x01(\tR\x04sort\x12\x1c\n" +
+	"\tascending\x18\x02 \x01(\bR\tascending\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x12!\n" +
+	"\fquote_assets\x18\x05 \x03(\tR\vquoteAssets\x12\x1d\n" +
+	"\n" +
+	"min_volume\x18\x06 \x01(\x01R\tminVolume\x12\x18\n" +
+	"\afilters\x18\a \x03(\tR\afilters\"Z\n" +
+	"\x10ScreenerResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x120\n" +
+	"\atickers\x18\x02 \x03(\v2\x16.cryptoxscanner.TickerR\atickers\"Y\n" +
+	"\x14StreamTickersRequest\x12\x18\n" +
+	"\asymbols\x18\x01 \x03(\tR\asymbols\x12'\n" +
+	"\x0fupdate_interval\x18\x02 \x01(\x05R\x0eupdateInterval\"@\n" +
+	"\fTickerUpdate\x120\n" +
+	"\atickers\x18\x01 \x03(\v2\x16.cryptoxscanner.TickerR\atickers\"/\n" +
+	"\x13StreamTradesRequest\x12\x18\n" +
+	"\asymbols\x18\x01 \x03(\tR\asymbols\"\xa0\x01\n" +
+	"\x05Trade\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x01R\bquantity\x12\x1f\n" +
+	"\vbuyer_maker\x18\x05 \x01(\bR\n" +
+	"buyerMaker\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\"\xaf\x01\n" +
+	"\tPumpScore\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x01R\x05score\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x01R\x05price\x12\"\n" +
+	"\facceleration\x18\x03 \x01(\x01R\facceleration\x12\x1b\n" +
+	"\tbuy_ratio\x18\x04 \x01(\x01R\bbuyRatio\x12\x1d\n" +
+	"\n" +
+	"net_volume\x18\x05 \x01(\x01R\tnetVolume\x12\x16\n" +
+	"\x06trades\x18\x06 \x01(\x01R\x06trades\"\xcf\x02\n" +
+	"\tPumpEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05stage\x18\x03 \x01(\tR\x05stage\x12\x14\n" +
+	"\x05start\x18\x04 \x01(\x03R\x05start\x12\x1f\n" +
+	"\vstart_price\x18\x05 \x01(\x01R\n" +
+	"startPrice\x12\x1b\n" +
+	"\tpeak_time\x18\x06 \x01(\x03R\bpeakTime\x12\x1d\n" +
+	"\n" +
+	"peak_price\x18\a \x01(\x01R\tpeakPrice\x12\x1d\n" +
+	"\n" +
+	"peak_score\x18\b \x01(\x01R\tpeakScore\x12\x10\n" +
+	"\x03end\x18\t \x01(\x03R\x03end\x12\x14\n" +
+	"\x05price\x18\n" +
+	" \x01(\x01R\x05price\x12/\n" +
+	"\x05score\x18\v \x01(\v2\x19.cryptoxscanner.PumpScoreR\x05score\x12\x19\n" +
+	"\bgain_pct\x18\f \x01(\x01R\againPct\"\xfe\x01\n" +
+	"\n" +
+	"WhaleTrade\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04side\x18\x02 \x01(\tR\x04side\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x01R\bquantity\x12%\n" +
+	"\x0equote_quantity\x18\x05 \x01(\x01R\rquoteQuantity\x12\x10\n" +
+	"\x03usd\x18\x06 \x01(\x01R\x03usd\x12\x1c\n" +
+	"\tthreshold\x18\a \x01(\x01R\tthreshold\x12\x1c\n" +
+	"\ttimestamp\x18\b \x01(\x03R\ttimestamp\x12\x1d\n" +
+	"\n" +
+	"impact_pct\x18\t \x01(\x01R\timpactPct\"E\n" +
+	"\x13StreamAlertsRequest\x12\x14\n" +
+	"\x05types\x18\x01 \x03(\tR\x05types\x12\x18\n" +
+	"\asymbols\x18\x02 \x03(\tR\asymbols\"\xa1\x01\n" +
+	"\x05Alert\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12/\n" +
+	"\x04pump\x18\x03 \x01(\v2\x19.cryptoxscanner.PumpEventH\x00R\x04pump\x122\n" +
+	"\x05whale\x18\x04 \x01(\v2\x1a.cryptoxscanner.WhaleTradeH\x00R\x05whaleB\a\n" +
+	"\x05event2\x92\x03\n" +
+	"\aScanner\x12E\n" +
+	"\tGetSymbol\x12 .cryptoxscanner.GetSymbolRequest\x1a\x16.cryptoxscanner.Ticker\x12M\n" +
+	"\bScreener\x12\x1f.cryptoxscanner.ScreenerRequest\x1a .cryptoxscanner.ScreenerResponse\x12U\n" +
+	"\rStreamTickers\x12$.cryptoxscanner.StreamTickersRequest\x1a\x1c.cryptoxscanner.TickerUpdate0\x01\x12L\n" +
+	"\fStreamTrades\x12#.cryptoxscanner.StreamTradesRequest\x1a\x15.cryptoxscanner.Trade0\x01\x12L\n" +
+	"\fStreamAlerts\x12#.cryptoxscanner.StreamAlertsRequest\x1a\x15.cryptoxscanner.Alert0\x01B0Z.gitlab.com/crankykernel/cryptoxscanner/grpcapib\x06proto3"
+
+var (
+	file_scanner_proto_rawDescOnce sync.Once
+	file_scanner_proto_rawDescData []byte
+)
+
+func file_scanner_proto_rawDescGZIP() []byte {
+	file_scanner_proto_rawDescOnce.Do(func() {
+		file_scanner_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scanner_proto_rawDesc), len(file_scanner_proto_rawDesc)))
+	})
+	return file_scanner_proto_rawDescData
+}
+
+var file_scanner_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_scanner_proto_goTypes = []any{
+	(*NormalizedVolume)(nil),     // 0: cryptoxscanner.NormalizedVolume
+	(*TickerMetrics)(nil),        // 1: cryptoxscanner.TickerMetrics
+	(*Aggregate)(nil),            // 2: cryptoxscanner.Aggregate
+	(*Candles)(nil),              // 3: cryptoxscanner.Candles
+	(*Ticker)(nil),               // 4: cryptoxscanner.Ticker
+	(*GetSymbolRequest)(nil),     // 5: cryptoxscanner.GetSymbolRequest
+	(*ScreenerRequest)(nil),      // 6: cryptoxscanner.ScreenerRequest
+	(*ScreenerResponse)(nil),     // 7: cryptoxscanner.ScreenerResponse
+	(*StreamTickersRequest)(nil), // 8: cryptoxscanner.StreamTickersRequest
+	(*TickerUpdate)(nil),         // 9: cryptoxscanner.TickerUpdate
+	(*StreamTradesRequest)(nil),  // 10: cryptoxscanner.StreamTradesRequest
+	(*Trade)(nil),                // 11: cryptoxscanner.Trade
+	(*PumpScore)(nil),            // 12: cryptoxscanner.PumpScore
+	(*PumpEvent)(nil),            // 13: cryptoxscanner.PumpEvent
+	(*WhaleTrade)(nil),           // 14: cryptoxscanner.WhaleTrade
+	(*StreamAlertsRequest)(nil),  // 15: cryptoxscanner.StreamAlertsRequest
+	(*Alert)(nil),                // 16: cryptoxscanner.Alert
+	nil,                          // 17: cryptoxscanner.Ticker.MetricsEntry
+	nil,                          // 18: cryptoxscanner.Ticker.CandlesEntry
+}
+var file_scanner_proto_depIdxs = []int32{
+	0,  // 0: cryptoxscanner.TickerMetrics.usd:type_name -> cryptoxscanner.NormalizedVolume
+	0,  // 1: cryptoxscanner.TickerMetrics.btc:type_name -> cryptoxscanner.NormalizedVolume
+	2,  // 2: cryptoxscanner.Candles.aggregates:type_name -> cryptoxscanner.Aggregate
+	17, // 3: cryptoxscanner.Ticker.metrics:type_name -> cryptoxscanner.Ticker.MetricsEntry
+	18, // 4: cryptoxscanner.Ticker.candles:type_name -> cryptoxscanner.Ticker.CandlesEntry
+	4,  // 5: cryptoxscanner.ScreenerResponse.tickers:type_name -> cryptoxscanner.Ticker
+	4,  // 6: cryptoxscanner.TickerUpdate.tickers:type_name -> cryptoxscanner.Ticker
+	12, // 7: cryptoxscanner.PumpEvent.score:type_name -> cryptoxscanner.PumpScore
+	13, // 8: cryptoxscanner.Alert.pump:type_name -> cryptoxscanner.PumpEvent
+	14, // 9: cryptoxscanner.Alert.whale:type_name -> cryptoxscanner.WhaleTrade
+	1,  // 10: cryptoxscanner.Ticker.MetricsEntry.value:type_name -> cryptoxscanner.TickerMetrics
+	3,  // 11: cryptoxscanner.Ticker.CandlesEntry.value:type_name -> cryptoxscanner.Candles
+	5,  // 12: cryptoxscanner.Scanner.GetSymbol:input_type -> cryptoxscanner.GetSymbolRequest
+	6,  // 13: cryptoxscanner.Scanner.Screener:input_type -> cryptoxscanner.ScreenerRequest
+	8,  // 14: cryptoxscanner.Scanner.StreamTickers:input_type -> cryptoxscanner.StreamTickersRequest
+	10, // 15: cryptoxscanner.Scanner.StreamTrades:input_type -> cryptoxscanner.StreamTradesRequest
+	15, // 16: cryptoxscanner.Scanner.StreamAlerts:input_type -> cryptoxscanner.StreamAlertsRequest
+	4,  // 17: cryptoxscanner.Scanner.GetSymbol:output_type -> cryptoxscanner.Ticker
+	7,  // 18: cryptoxscanner.Scanner.Screener:output_type -> cryptoxscanner.ScreenerResponse
+	9,  // 19: cryptoxscanner.Scanner.StreamTickers:output_type -> cryptoxscanner.TickerUpdate
+	11, // 20: cryptoxscanner.Scanner.StreamTrades:output_type -> cryptoxscanner.Trade
+	16, // 21: cryptoxscanner.Scanner.StreamAlerts:output_type -> cryptoxscanner.Alert
+	17, // [17:22] is the sub-list for method output_type
+	12, // [12:17] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_scanner_proto_init() }
+func file_scanner_proto_init() {
+	if File_scanner_proto != nil {
+		return
+	}
+	file_scanner_proto_msgTypes[16].OneofWrappers = []any{
+		(*Alert_Pump)(nil),
+		(*Alert_Whale)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scanner_proto_rawDesc), len(file_scanner_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_scanner_proto_goTypes,
+		DependencyIndexes: file_scanner_proto_depIdxs,
+		MessageInfos:      file_scanner_proto_msgTypes,
+	}.Build()
+	File_scanner_proto = out.File
+	file_scanner_proto_goTypes = nil
+	file_scanner_proto_depIdxs = nil
+}
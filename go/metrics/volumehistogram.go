@@ -46,15 +46,19 @@ type VolumeHistogramCalculator struct {
 	BuyVolume [Buckets]float64
 	// Net volume in quote currency.
 	NetVolume [Buckets]float64
+
+	// Trades are bucketed by their age at this time.
+	now time.Time
 }
 
-func NewVolumeHistogramCalculator() VolumeHistogramCalculator {
-	return VolumeHistogramCalculator{}
+func NewVolumeHistogramCalculator(now time.Time) VolumeHistogramCalculator {
+	return VolumeHistogramCalculator{
+		now: now,
+	}
 }
 
 func (v *VolumeHistogramCalculator) AddTrade(trade *binance.StreamAggTrade) {
-	now := time.Now()
-	age := now.Sub(trade.Timestamp())
+	age := v.now.Sub(trade.Timestamp())
 	bucket := int(age.Truncate(time.Minute).Minutes())
 	if bucket < Buckets {
 		v.TradeCount[bucket] += 1
@@ -0,0 +1,219 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Anomaly scores compare the latest window of a bucket with the earlier
+// windows of the same length, rolled forward a minute at a time over the
+// trades kept, as robust z-scores: the distance from the median in units
+// of the scaled median absolute deviation. If the MAD is 0, as it is for
+// symbols that rarely trade, the mean and standard deviation are used
+// instead. Trade counts are treated as Poisson distributed so their spread
+// is at least the square root of the baseline. Scores are NaN when there
+// are not enough windows to form a baseline or it has no spread.
+
+// Minutes of history used for the baseline, as long as trades are kept.
+const anomalyHistory = maxBucket
+
+// Minimum number of baseline windows for a score.
+const anomalyMinSamples = 10
+
+// Scales the MAD to be comparable to the standard deviation of normally
+// distributed values.
+const madScale = 1.4826
+
+// The wire names of the anomaly scores of a bucket.
+var anomalyFields = []string{"zp_%d", "zv_%d", "zt_%d"}
+
+// hasAnomalyBaseline returns true if the bucket is short enough for its
+// baseline windows, which do not overlap the latest window, to fit in the
+// history.
+func hasAnomalyBaseline(bucket int) bool {
+	return anomalyHistory-2*bucket+1 >= anomalyMinSamples
+}
+
+// anomalyFieldNames returns the wire names of the anomaly scores of the
+// buckets that can never have a baseline.
+func anomalyFieldNames(buckets []int) []string {
+	names := []string{}
+	for _, bucket := range buckets {
+		if hasAnomalyBaseline(bucket) {
+			continue
+		}
+		for _, field := range anomalyFields {
+			names = append(names, fmt.Sprintf(field, bucket))
+		}
+	}
+	return names
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// zScore returns the robust z-score of value against baseline. If counts
+// is set the values are event counts.
+func zScore(value float64, baseline []float64, counts bool) float64 {
+	if len(baseline) < anomalyMinSamples {
+		return math.NaN()
+	}
+	center := median(baseline)
+	deviations := make([]float64, len(baseline))
+	for i, v := range baseline {
+		deviations[i] = math.Abs(v - center)
+	}
+	spread := median(deviations) * madScale
+	if spread == 0 {
+		mean := 0.0
+		for _, v := range baseline {
+			mean += v
+		}
+		mean /= float64(len(baseline))
+		variance := 0.0
+		for _, v := range baseline {
+			variance += (v - mean) * (v - mean)
+		}
+		center = mean
+		spread = math.Sqrt(variance / float64(len(baseline)))
+	}
+	if counts && spread < math.Sqrt(center) {
+		spread = math.Sqrt(center)
+	}
+	if spread == 0 {
+		return math.NaN()
+	}
+	return Round3((value - center) / spread)
+}
+
+// minuteSeries is the trade activity of the last minutes, index 0 being
+// the most recent minute.
+type minuteSeries struct {
+	// The price at the start of each minute, and now at index 0. Minutes
+	// before the first trade are NaN.
+	Prices []float64
+
+	// Running totals of the volume and number of trades, index i being
+	// the total of the i most recent minutes.
+	Volume []float64
+	Trades []float64
+}
+
+// minuteSeries builds the series of the last minutes from the trades.
+func (t *TickerTracker) minuteSeries(now time.Time, minutes int) *minuteSeries {
+	series := &minuteSeries{
+		Prices: make([]float64, minutes+1),
+		Volume: make([]float64, minutes+1),
+		Trades: make([]float64, minutes+1),
+	}
+	volume := make([]float64, minutes)
+	trades := make([]float64, minutes)
+	i := len(t.Trades) - 1
+	for minute := 0; minute <= minutes; minute++ {
+		at := now.Add(-time.Duration(minute) * time.Minute)
+		for ; i >= 0 && t.Trades[i].Timestamp().After(at); i-- {
+			if minute > 0 {
+				volume[minute-1] += t.Trades[i].QuoteQuantity()
+				trades[minute-1]++
+			}
+		}
+		if i < 0 {
+			series.Prices[minute] = math.NaN()
+		} else {
+			series.Prices[minute] = t.Trades[i].Price
+		}
+	}
+	for minute := 0; minute < minutes; minute++ {
+		series.Volume[minute+1] = series.Volume[minute] + volume[minute]
+		series.Trades[minute+1] = series.Trades[minute] + trades[minute]
+	}
+	return series
+}
+
+// CalculateAnomalies sets the anomaly scores of each bucket for the price
+// change, volume and number of trades.
+func (t *TickerTracker) CalculateAnomalies(now time.Time) {
+
+	// Only use the minutes that have been tracked so windows from
+	// before startup do not count as quiet.
+	available := 0
+	if !t.trackedSince.IsZero() {
+		available = int(now.Sub(t.trackedSince).Minutes())
+	}
+	if available > anomalyHistory {
+		available = anomalyHistory
+	}
+	series := t.minuteSeries(now, available)
+
+	window := func(values []float64, start int, bucket int) float64 {
+		return values[start+bucket] - values[start]
+	}
+	change := func(start int, bucket int) float64 {
+		if series.Prices[start+bucket] > 0 {
+			return (series.Prices[start] - series.Prices[start+bucket]) /
+				series.Prices[start+bucket] * 100
+		}
+		return math.NaN()
+	}
+
+	for _, bucket := range Buckets {
+		metrics := t.Metrics[bucket]
+		metrics.PriceZScore = math.NaN()
+		metrics.VolumeZScore = math.NaN()
+		metrics.TradeRateZScore = math.NaN()
+		if 2*bucket > available {
+			continue
+		}
+
+		// The baseline windows start after the latest one ends.
+		baselineChanges := []float64{}
+		baselineVolumes := []float64{}
+		baselineTrades := []float64{}
+		for start := bucket; start+bucket <= available; start++ {
+			if ret := change(start, bucket); !math.IsNaN(ret) {
+				baselineChanges = append(baselineChanges, ret)
+			}
+			baselineVolumes = append(baselineVolumes, window(series.Volume, start, bucket))
+			baselineTrades = append(baselineTrades, window(series.Trades, start, bucket))
+		}
+
+		if ret := change(0, bucket); !math.IsNaN(ret) {
+			metrics.PriceZScore = zScore(ret, baselineChanges, false)
+		}
+		metrics.VolumeZScore = zScore(window(series.Volume, 0, bucket), baselineVolumes, false)
+		metrics.TradeRateZScore = zScore(window(series.Trades, 0, bucket), baselineTrades, true)
+	}
+}
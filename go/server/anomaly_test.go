@@ -0,0 +1,92 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestAnomalyBuckets checks that every bucket short enough for a baseline
+// is scored once the trades cover the history, and that the scores of the
+// others are left out of the schema.
+func TestAnomalyBuckets(t *testing.T) {
+	if err := ConfigureMetrics(MetricsOptions{Buckets: []int{1, 2, 60, 80, 120}, RSIPeriod: 14}); err != nil {
+		t.Fatal(err)
+	}
+	defer ConfigureMetrics(DefaultMetricsOptions)
+
+	now := goldenNow
+	tracker := newTestTracker("ETHBTC", "BTC", 0.034, now)
+	tracker.RecalculateAt(now)
+	for _, bucket := range []int{1, 60, 80} {
+		metrics := tracker.Metrics[bucket]
+		for name, score := range map[string]float64{
+			"price":  metrics.PriceZScore,
+			"volume": metrics.VolumeZScore,
+			"trades": metrics.TradeRateZScore,
+		} {
+			if math.IsNaN(score) {
+				t.Errorf("bucket %d: no %s score", bucket, name)
+			}
+		}
+	}
+	if !math.IsNaN(tracker.Metrics[120].VolumeZScore) {
+		t.Errorf("bucket 120: unexpected volume score")
+	}
+
+	expected := []string{"zp_120", "zv_120", "zt_120"}
+	if names := anomalyFieldNames(Buckets); !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+	properties := EntrySchema(reflect.TypeOf(CompleteEntry{}))["properties"].(map[string]interface{})
+	for _, name := range []string{"zp_80", "zv_80", "zt_80"} {
+		if properties[name] == nil {
+			t.Errorf("%s is missing from the schema", name)
+		}
+	}
+	for _, name := range expected {
+		if properties[name] != nil {
+			t.Errorf("%s is in the schema", name)
+		}
+	}
+}
+
+// TestAnomalyTrackedSince checks that minutes before the tracker saw its
+// first trade do not count as quiet windows.
+func TestAnomalyTrackedSince(t *testing.T) {
+	now := goldenNow
+	tracker := newTestTracker("ETHBTC", "BTC", 0.034, now)
+	tracker.trackedSince = now.Add(-20 * time.Minute)
+	tracker.RecalculateAt(now)
+	if !math.IsNaN(tracker.Metrics[15].VolumeZScore) {
+		t.Errorf("bucket 15: unexpected score with 20 minutes of history")
+	}
+	if math.IsNaN(tracker.Metrics[5].VolumeZScore) {
+		t.Errorf("bucket 5: no score with 20 minutes of history")
+	}
+}
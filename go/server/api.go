@@ -0,0 +1,129 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Responses of the REST API. The OpenAPI document served at
+// /api/1/openapi.json is generated from these types, so the doc tags end
+// up as the field descriptions.
+
+type PingResponse struct {
+	Version     int64 `json:"version" doc:"Build number of the server."`
+	BuildNumber int64 `json:"buildNumber" doc:"Build number of the server."`
+}
+
+type WsQueueStatus struct {
+	Depth  int    `json:"depth" doc:"Number of updates queued per client subscription."`
+	Policy string `json:"policy" doc:"What happens when a client queue is full: drop-oldest or disconnect."`
+}
+
+type WsConnectionStatus struct {
+	Id           uint64   `json:"id" doc:"Number of the connection."`
+	Client       string   `json:"client" doc:"Hash of the client address."`
+	Paths        []string `json:"paths" doc:"Paths the connection is subscribed to."`
+	Queued       int      `json:"queued" doc:"Updates waiting to be sent."`
+	Dropped      uint64   `json:"dropped" doc:"Updates dropped as the queue was full."`
+	Disconnected bool     `json:"disconnected" doc:"Whether the connection is being closed for falling behind."`
+}
+
+type WebSocketsStatusResponse struct {
+	Paths        map[string]int       `json:"paths" doc:"Number of connections by path."`
+	Clients      map[string][]string  `json:"clients" doc:"Paths connected to by each client, keyed by a hash of the client address."`
+	Connections  []WsConnectionStatus `json:"connections" doc:"Open connections, in the order they connected."`
+	Dropped      uint64               `json:"dropped" doc:"Updates dropped for all connections since startup."`
+	Disconnected uint64               `json:"disconnected" doc:"Connections closed for falling behind since startup."`
+	Queue        WsQueueStatus        `json:"queue"`
+}
+
+// VolumeEntry is the volume summary of a symbol. Histograms are per
+// minute, the current minute first. The hour fields are only sent if the
+// 60 minute bucket is configured, and rsi15 only if the 15 minute bucket
+// is.
+type VolumeEntry struct {
+	Quote     string  `json:"quote" doc:"Quote asset."`
+	Volume    float64 `json:"vol" doc:"24 hour volume in the quote asset."`
+	VolumeUSD float64 `json:"vol_usd" doc:"24 hour volume in USD, zero if no conversion rate is known."`
+	VolumeBTC float64 `json:"vol_btc" doc:"24 hour volume in BTC, zero if no conversion rate is known."`
+
+	NetVolumeHistogram    []float64 `json:"nvh" doc:"Net volume per minute in the quote asset."`
+	NetVolumeHistogramUSD []float64 `json:"nvh_usd" doc:"Net volume per minute in USD."`
+	NetVolumeHistogramBTC []float64 `json:"nvh_btc" doc:"Net volume per minute in BTC."`
+	BuyVolumeHistogram    []float64 `json:"bvh" doc:"Buy volume per minute in the quote asset."`
+	BuyVolumeHistogramUSD []float64 `json:"bvh_usd" doc:"Buy volume per minute in USD."`
+	BuyVolumeHistogramBTC []float64 `json:"bvh_btc" doc:"Buy volume per minute in BTC."`
+	VolumeHistogram       []float64 `json:"vh" doc:"Volume per minute in the quote asset."`
+	VolumeHistogramUSD    []float64 `json:"vh_usd" doc:"Volume per minute in USD."`
+	VolumeHistogramBTC    []float64 `json:"vh_btc" doc:"Volume per minute in BTC."`
+	Volume24Histogram     []float64 `json:"v24h" doc:"24 hour volume in the quote asset at each minute."`
+	Volume24HistogramUSD  []float64 `json:"v24h_usd" doc:"24 hour volume in USD at each minute."`
+	Volume24HistogramBTC  []float64 `json:"v24h_btc" doc:"24 hour volume in BTC at each minute."`
+	PriceChangePercent1h  *float64  `json:"priceChange1h,omitempty" doc:"Price change in percent over the last hour."`
+	NetVolume60           *float64  `json:"nv60,omitempty" doc:"Net volume over the last hour in the quote asset."`
+	NetVolume60USD        *float64  `json:"nv60_usd,omitempty" doc:"Net volume over the last hour in USD."`
+	NetVolume60BTC        *float64  `json:"nv60_btc,omitempty" doc:"Net volume over the last hour in BTC."`
+	Volume60              *float64  `json:"v60,omitempty" doc:"Volume over the last hour in the quote asset."`
+	Volume60USD           *float64  `json:"v60_usd,omitempty" doc:"Volume over the last hour in USD."`
+	Volume60BTC           *float64  `json:"v60_btc,omitempty" doc:"Volume over the last hour in BTC."`
+	Trades60              *uint64   `json:"t60,omitempty" doc:"Number of trades over the last hour."`
+	BuyTradeRatio60       *float64  `json:"t60pb,omitempty" doc:"Share of the trades over the last hour that were buys, 0 to 1."`
+	RSI15                 *float64  `json:"rsi15,omitempty" doc:"RSI of the 15 minute bucket, 0 if unavailable."`
+}
+
+type VolumeResponse struct {
+	Data map[string]*VolumeEntry `json:"data" doc:"Keyed by symbol."`
+}
+
+type VolumeProfileResponse struct {
+	Symbol   string           `json:"symbol"`
+	Profiles []*VolumeProfile `json:"profiles" doc:"One profile for each window with trades."`
+}
+
+type BreadthResponse struct {
+	Breadth *Breadth   `json:"breadth" doc:"Latest breadth, null until the first has been calculated."`
+	History []*Breadth `json:"history,omitempty" doc:"Recent breadth, oldest first, if requested."`
+}
+
+type EventsResponse struct {
+	Events []json.RawMessage `json:"events" doc:"Stored events, most recent first."`
+}
+
+type ScreenerResponse struct {
+	Total   int                      `json:"total" doc:"Number of entries that matched."`
+	Offset  int                      `json:"offset"`
+	Limit   int                      `json:"limit"`
+	Count   int                      `json:"count" doc:"Number of entries returned."`
+	Results []map[string]interface{} `json:"results" doc:"Entries of the live feed with their rank."`
+
+	Timestamp *time.Time `json:"timestamp,omitempty" doc:"Time of the snapshot screened, for queries with at."`
+}
+
+type SnapshotListResponse struct {
+	Interval   int64       `json:"interval" doc:"Seconds between snapshots, 0 if they are disabled."`
+	Timestamps []time.Time `json:"timestamps" doc:"Times of the stored snapshots, oldest first."`
+}
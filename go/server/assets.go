@@ -0,0 +1,228 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/binance"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// AssetMetrics are the metrics of all markets of a base asset combined
+// for one bucket. Volumes are in USD.
+type AssetMetrics struct {
+	PriceChangePercent float64 `msgpack:"price_change_pct"`
+	TotalVolume        float64 `msgpack:"total_volume_usd"`
+	NetVolume          float64 `msgpack:"nv_usd"`
+	BuyVolume          float64 `msgpack:"bv_usd"`
+	SellVolume         float64 `msgpack:"sv_usd"`
+	TotalTrades        uint64  `msgpack:"trades"`
+	BuyTrades          uint64  `msgpack:"buy_trades"`
+	SellTrades         uint64  `msgpack:"sell_trades"`
+}
+
+// AssetAggregate is the coin level view of a base asset across all the
+// markets it trades in.
+type AssetAggregate struct {
+	Asset       string                `msgpack:"asset"`
+	Markets     []string              `msgpack:"markets"`
+	PriceUSD    float64               `msgpack:"price_usd"`
+	Volume24USD float64               `msgpack:"volume_usd"`
+	Metrics     map[int]*AssetMetrics `msgpack:"metrics"`
+}
+
+// quotePriceChange returns the USD price change of a quote asset for a
+// bucket, taken from its USDT market.
+func quotePriceChange(trackers *TickerTrackerMap, quote string, bucket int) (float64, bool) {
+	if binance.IsUsdAsset(quote) {
+		return 0, true
+	}
+	tracker, ok := trackers.Trackers[quote+"USDT"]
+	if !ok {
+		return 0, false
+	}
+	return tracker.Metrics[bucket].PriceChangePercent, true
+}
+
+// AggregateAssets combines the trackers by base asset. Markets without a
+// USD conversion rate for their quote asset are left out.
+func AggregateAssets(trackers *TickerTrackerMap) map[string]*AssetAggregate {
+	assets := map[string]*AssetAggregate{}
+
+	// Price change weights, by asset then bucket.
+	weights := map[string]map[int]float64{}
+
+	// Volume of the market the asset price was taken from.
+	priceVolumes := map[string]float64{}
+
+	for symbol, tracker := range trackers.Trackers {
+		last := tracker.LastTick()
+		if last == nil || tracker.BaseAsset == "" || tracker.QuoteUSD == 0 {
+			continue
+		}
+
+		asset := assets[tracker.BaseAsset]
+		if asset == nil {
+			asset = &AssetAggregate{
+				Asset:   tracker.BaseAsset,
+				Metrics: map[int]*AssetMetrics{},
+			}
+			for _, bucket := range Buckets {
+				asset.Metrics[bucket] = &AssetMetrics{}
+			}
+			assets[tracker.BaseAsset] = asset
+			weights[tracker.BaseAsset] = map[int]float64{}
+		}
+
+		volume24 := last.TotalQuoteVolume * tracker.QuoteUSD
+		asset.Markets = append(asset.Markets, symbol)
+		asset.Volume24USD += volume24
+
+		// Price the asset from its most liquid market.
+		if volume24 >= priceVolumes[tracker.BaseAsset] {
+			asset.PriceUSD = last.CurrentDayClose * tracker.QuoteUSD
+			priceVolumes[tracker.BaseAsset] = volume24
+		}
+
+		for _, bucket := range Buckets {
+			metrics := tracker.Metrics[bucket]
+			aggregate := asset.Metrics[bucket]
+			aggregate.TotalVolume += metrics.USD.Total
+			aggregate.NetVolume += metrics.USD.Net
+			aggregate.BuyVolume += metrics.USD.Buy
+			aggregate.SellVolume += metrics.USD.Sell
+			aggregate.TotalTrades += metrics.TotalTrades
+			aggregate.BuyTrades += metrics.BuyTrades
+			aggregate.SellTrades += metrics.SellTrades
+
+			// Convert the pair price change into a USD price change
+			// and weight it by the volume of the market.
+			quoteChange, ok := quotePriceChange(trackers, tracker.QuoteAsset, bucket)
+			if !ok {
+				continue
+			}
+			change := ((1+metrics.PriceChangePercent/100)*(1+quoteChange/100) - 1) * 100
+			weight := metrics.USD.Total
+			if weight == 0 {
+				weight = volume24
+			}
+			aggregate.PriceChangePercent += change * weight
+			weights[tracker.BaseAsset][bucket] += weight
+		}
+	}
+
+	for name, asset := range assets {
+		sort.Strings(asset.Markets)
+		for _, bucket := range Buckets {
+			if weight := weights[name][bucket]; weight > 0 {
+				asset.Metrics[bucket].PriceChangePercent = Round3(
+					asset.Metrics[bucket].PriceChangePercent / weight)
+			} else {
+				asset.Metrics[bucket].PriceChangePercent = 0
+			}
+		}
+	}
+
+	return assets
+}
+
+func WsBuildAssetEntry(asset *AssetAggregate) map[string]interface{} {
+	entry := map[string]interface{}{
+		"asset":      asset.Asset,
+		"markets":    asset.Markets,
+		"price_usd":  Round8(asset.PriceUSD),
+		"volume_usd": Round8(asset.Volume24USD),
+	}
+
+	priceChange := map[string]float64{}
+	for _, bucket := range Buckets {
+		priceChange[bucketLabel(bucket)] = asset.Metrics[bucket].PriceChangePercent
+	}
+	entry["price_change_pct"] = priceChange
+
+	for bucket, metrics := range asset.Metrics {
+		entry[fmt.Sprintf("total_volume_usd_%d", bucket)] = Round8(metrics.TotalVolume)
+		entry[fmt.Sprintf("nv_usd_%d", bucket)] = Round8(metrics.NetVolume)
+		entry[fmt.Sprintf("bv_usd_%d", bucket)] = Round8(metrics.BuyVolume)
+		entry[fmt.Sprintf("sv_usd_%d", bucket)] = Round8(metrics.SellVolume)
+		entry[fmt.Sprintf("trades_%d", bucket)] = metrics.TotalTrades
+		entry[fmt.Sprintf("buy_trades_%d", bucket)] = metrics.BuyTrades
+		entry[fmt.Sprintf("sell_trades_%d", bucket)] = metrics.SellTrades
+	}
+
+	return entry
+}
+
+func WsBuildAssetMessage(trackers *TickerTrackerMap) []interface{} {
+	entries := []interface{}{}
+	for _, asset := range AggregateAssets(trackers) {
+		entries = append(entries, WsBuildAssetEntry(asset))
+	}
+	return entries
+}
+
+type AssetHandler struct {
+	binanceRunner *BinanceRunner
+}
+
+func NewAssetHandler(binanceRunner *BinanceRunner) *AssetHandler {
+	return &AssetHandler{
+		binanceRunner: binanceRunner,
+	}
+}
+
+// ServeHTTP returns the aggregates of all assets, or only the assets given
+// as a comma separated list in the "asset" parameter.
+func (h *AssetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	lastTracker := h.binanceRunner.GetCache()
+
+	filter := map[string]bool{}
+	if assets := r.FormValue("asset"); assets != "" {
+		for _, asset := range strings.Split(assets, ",") {
+			filter[strings.ToUpper(strings.TrimSpace(asset))] = true
+		}
+	}
+
+	data := map[string]interface{}{}
+	for name, asset := range AggregateAssets(&lastTracker) {
+		if len(filter) > 0 && !filter[name] {
+			continue
+		}
+		data[name] = WsBuildAssetEntry(asset)
+	}
+
+	w.Header().Add("content-type", "application/json")
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(map[string]interface{}{
+		"data": data,
+	}); err != nil {
+		log.WithError(err).WithField("handler", "assets").
+			Errorf("Failed to encode response to JSON")
+	}
+}
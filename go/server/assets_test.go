@@ -0,0 +1,122 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"reflect"
+	"testing"
+)
+
+func TestAggregateAssets(t *testing.T) {
+	trackers := NewTickerTrackerMap()
+	market := func(symbol string, base string, quote string, quoteUSD float64,
+		close float64, volume24 float64, metrics TickerMetrics) {
+		tracker := trackers.GetTracker(symbol)
+		tracker.BaseAsset = base
+		tracker.QuoteAsset = quote
+		tracker.QuoteUSD = quoteUSD
+		tracker.Ticks = append(tracker.Ticks, &binanceapi.TickerStreamMessage{
+			Symbol:           symbol,
+			CurrentDayClose:  close,
+			TotalQuoteVolume: volume24,
+		})
+		*tracker.Metrics[60] = metrics
+	}
+	market("BTCUSDT", "BTC", "USDT", 1, 50000, 1000000, TickerMetrics{
+		PriceChangePercent: 2,
+		USD:                NormalizedVolume{Total: 1000, Net: 200, Buy: 600, Sell: 400},
+		TotalTrades:        10, BuyTrades: 6, SellTrades: 4,
+	})
+	market("ETHBTC", "ETH", "BTC", 50000, 0.05, 10, TickerMetrics{
+		PriceChangePercent: 1,
+		USD:                NormalizedVolume{Total: 3000, Net: -1000, Buy: 1000, Sell: 2000},
+		TotalTrades:        5, BuyTrades: 2, SellTrades: 3,
+	})
+	market("ETHUSDT", "ETH", "USDT", 1, 2600, 1000000, TickerMetrics{
+		PriceChangePercent: -1,
+		USD:                NormalizedVolume{Total: 1000, Net: 1000, Buy: 1000},
+		TotalTrades:        1, BuyTrades: 1,
+	})
+	// BNB has no USDT market to convert the price change with.
+	market("LTCBNB", "LTC", "BNB", 300, 0.5, 100, TickerMetrics{
+		PriceChangePercent: 5,
+		USD:                NormalizedVolume{Total: 50, Net: 50, Buy: 50},
+		TotalTrades:        1, BuyTrades: 1,
+	})
+	// No USD rate for TRY, and no ticks yet.
+	market("XYZTRY", "XYZ", "TRY", 0, 10, 1000, TickerMetrics{})
+	trackers.GetTracker("ABCBTC").BaseAsset = "ABC"
+
+	assets := AggregateAssets(trackers)
+
+	tests := []struct {
+		asset    string
+		markets  []string
+		priceUSD float64
+		volume   float64
+		metrics  AssetMetrics
+	}{
+		{"BTC", []string{"BTCUSDT"}, 50000, 1000000, AssetMetrics{
+			PriceChangePercent: 2,
+			TotalVolume:        1000, NetVolume: 200, BuyVolume: 600, SellVolume: 400,
+			TotalTrades: 10, BuyTrades: 6, SellTrades: 4,
+		}},
+		// ETHBTC is up 3.02% in USD, weighted by its volume against
+		// the 1% fall of ETHUSDT. The price is from the more liquid
+		// ETHUSDT.
+		{"ETH", []string{"ETHBTC", "ETHUSDT"}, 2600, 1500000, AssetMetrics{
+			PriceChangePercent: 2.015,
+			TotalVolume:        4000, NetVolume: 0, BuyVolume: 2000, SellVolume: 2000,
+			TotalTrades: 6, BuyTrades: 3, SellTrades: 3,
+		}},
+		{"LTC", []string{"LTCBNB"}, 150, 30000, AssetMetrics{
+			TotalVolume: 50, NetVolume: 50, BuyVolume: 50,
+			TotalTrades: 1, BuyTrades: 1,
+		}},
+	}
+	for _, test := range tests {
+		asset, ok := assets[test.asset]
+		if !ok {
+			t.Errorf("%s: missing", test.asset)
+			continue
+		}
+		if !reflect.DeepEqual(asset.Markets, test.markets) {
+			t.Errorf("%s: expected markets %v, got %v", test.asset, test.markets, asset.Markets)
+		}
+		if asset.PriceUSD != test.priceUSD || asset.Volume24USD != test.volume {
+			t.Errorf("%s: expected price %v and volume %v, got %v and %v", test.asset,
+				test.priceUSD, test.volume, asset.PriceUSD, asset.Volume24USD)
+		}
+		if *asset.Metrics[60] != test.metrics {
+			t.Errorf("%s: expected %+v, got %+v", test.asset, test.metrics, *asset.Metrics[60])
+		}
+	}
+	for _, asset := range []string{"XYZ", "ABC"} {
+		if _, ok := assets[asset]; ok {
+			t.Errorf("%s: expected to be left out", asset)
+		}
+	}
+}
@@ -27,6 +27,7 @@ package server
 import (
 	"github.com/crankykernel/binanceapi-go"
 	"gitlab.com/crankykernel/cryptoxscanner/binance"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
 	"gitlab.com/crankykernel/cryptoxscanner/log"
 	"runtime"
 	"sync"
@@ -35,69 +36,114 @@ import (
 
 type BinanceRunner struct {
 	trackers          *TickerTrackerMap
-	symbolSubscribers map[string]map[chan interface{}]bool
-	subscribers  map[chan *TickerTrackerMap]bool
-	tickerStream *binance.TickerStream
+	symbolSubscribers map[string]map[wsSubscriber]bool
+	subscribers       map[chan *TickerTrackerMap]bool
+	tickerStream      *binance.TickerStream
+	universe          *binance.SymbolUniverse
+	rates             *binance.ConversionRates
 
 	Cached    TickerTrackerMap
 	CacheLock sync.RWMutex
+
+	// Whale trades as they are received.
+	WhaleFeed *EventFeed
+
+	// Every trade of the universe as it is received, without history.
+	TradeFeed *EventFeed
+
+	rollups      *db.RollupStore
+	rollupsSaved time.Time
+
+	subscriberLock sync.RWMutex
 }
 
-func NewBinanceRunner() *BinanceRunner {
+// NewBinanceRunner creates a runner for the symbols of universe. The
+// rollups of the rolling metrics are persisted to rollups if not nil.
+func NewBinanceRunner(universe *binance.SymbolUniverse, rollups *db.RollupStore) *BinanceRunner {
 	feed := BinanceRunner{
 		trackers:    NewTickerTrackerMap(),
 		subscribers: map[chan *TickerTrackerMap]bool{},
+		universe:    universe,
+		rates:       binance.NewConversionRates(universe),
+		WhaleFeed:   NewEventFeed(eventFeedHistory),
+		TradeFeed:   NewEventFeed(0),
+		rollups:     rollups,
 	}
 	return &feed
 }
 
 func (b *BinanceRunner) Subscribe() chan *TickerTrackerMap {
+	b.subscriberLock.Lock()
+	defer b.subscriberLock.Unlock()
 	channel := make(chan *TickerTrackerMap, 1)
 	b.subscribers[channel] = true
 	return channel
 }
 
 func (b *BinanceRunner) Unsubscribe(channel chan *TickerTrackerMap) {
+	b.subscriberLock.Lock()
+	defer b.subscriberLock.Unlock()
 	if _, exists := b.subscribers[channel]; exists {
 		delete(b.subscribers, channel)
 	}
 }
 
-func (b *BinanceRunner) SubscribeSymbol(symbol string) chan interface{} {
-	channel := make(chan interface{})
+// SubscribeSymbol queues the updates of a single symbol for a client,
+// tagged with key, until unsubscribed.
+func (b *BinanceRunner) SubscribeSymbol(symbol string, queue *WsSendQueue, key string) {
+	b.subscriberLock.Lock()
+	defer b.subscriberLock.Unlock()
 	if b.symbolSubscribers == nil {
-		b.symbolSubscribers = map[string]map[chan interface{}]bool{}
+		b.symbolSubscribers = map[string]map[wsSubscriber]bool{}
 	}
 	if b.symbolSubscribers[symbol] == nil {
-		b.symbolSubscribers[symbol] = map[chan interface{}]bool{}
+		b.symbolSubscribers[symbol] = map[wsSubscriber]bool{}
 	}
-	b.symbolSubscribers[symbol][channel] = true
-	return channel
+	b.symbolSubscribers[symbol][wsSubscriber{queue: queue, key: key}] = true
 }
 
-func (b *BinanceRunner) UnsubscribeSymbol(symbol string, channel chan interface{}) {
+func (b *BinanceRunner) UnsubscribeSymbol(symbol string, queue *WsSendQueue, key string) {
+	b.subscriberLock.Lock()
+	defer b.subscriberLock.Unlock()
 	if b.symbolSubscribers[symbol] != nil {
-		if _, exists := b.symbolSubscribers[symbol][channel]; exists {
-			delete(b.symbolSubscribers[symbol], channel)
-		}
+		delete(b.symbolSubscribers[symbol], wsSubscriber{queue: queue, key: key})
 	}
 }
 
 func (b *BinanceRunner) Run() {
+	// Load the symbol universe before anything is restored from the cache
+	// so cached symbols that are no longer wanted are dropped.
+	for {
+		if err := b.universe.Refresh(); err != nil {
+			log.Errorf("Failed to load Binance symbol universe: %v", err)
+			time.Sleep(1 * time.Second)
+			continue
+		}
+		log.Infof("Loaded %d Binance symbols.", b.universe.Len())
+		break
+	}
+
 	// Create and start the trade stream.
-	binanceTradeStream := binance.NewTradeStream()
+	binanceTradeStream := binance.NewTradeStream(b.universe)
 	go binanceTradeStream.Run()
 
 	// SubscribeSymbol to the trade stream. This will start queuing trades
 	// until the cache is done loading.
 	tradeChannel := binanceTradeStream.Subscribe()
 
+	// Rollups must be restored before the trades so the trades of the
+	// minutes they cover are not counted twice.
+	b.restoreRollups()
+
 	wg := sync.WaitGroup{}
 
 	wg.Add(1)
 	go func() {
 		count := 0
 		binanceTradeStream.RestoreCache(func(trade *binanceapi.StreamAggTrade) {
+			if !b.universe.Contains(trade.Symbol) {
+				return
+			}
 			ticker := b.trackers.GetTracker(trade.Symbol)
 			ticker.AddTrade(*trade)
 			count += 1
@@ -130,8 +176,14 @@ func (b *BinanceRunner) Run() {
 			select {
 
 			case trade := <-tradeChannel:
+				if !b.universe.Contains(trade.Symbol) {
+					goto ReadLoop
+				}
 				ticker := b.trackers.GetTracker(trade.Symbol)
-				ticker.AddTrade(trade)
+				if whale := ticker.AddTrade(trade); whale != nil {
+					b.WhaleFeed.Publish("whale", whale)
+				}
+				b.TradeFeed.Publish("trade", trade)
 
 				if trade.Timestamp().After(lastTradeTime) {
 					lastTradeTime = trade.Timestamp()
@@ -154,18 +206,24 @@ func (b *BinanceRunner) Run() {
 				}
 
 				b.updateTrackers(b.trackers, tickers, true)
+				CalculateMarketMetrics(b.trackers, time.Now())
+				CalculateRollupCoverage(b.trackers, time.Now())
+				b.saveRollups(time.Now())
+				b.trackers.Prune(b.universe.Contains)
 
+				b.subscriberLock.RLock()
 				for key := range b.trackers.Trackers {
 					count := len(b.symbolSubscribers[key])
 					if count == 0 {
 						continue
 					}
-					message := WsBuildCompleteEntry(b.trackers.Trackers[key])
+					message := NewWsSymbolUpdate(b.trackers.Trackers[key])
 					for subscriber := range b.symbolSubscribers[key] {
-						select {
-						case subscriber <- message:
-						default:
-						}
+						subscriber.queue.Push(wsQueueItem{
+							key:    subscriber.key,
+							symbol: key,
+							entry:  message,
+						})
 					}
 				}
 
@@ -176,6 +234,7 @@ func (b *BinanceRunner) Run() {
 						log.Warnf("warning: failed to send trackers to subscriber")
 					}
 				}
+				b.subscriberLock.RUnlock()
 
 				b.CacheLock.Lock()
 				b.Cached = *b.trackers
@@ -194,6 +253,12 @@ func (b *BinanceRunner) Run() {
 	}()
 }
 
+// GetTracker returns the tracker of symbol, or nil if there is none. Its
+// read lock must be held while reading it.
+func (b *BinanceRunner) GetTracker(symbol string) *TickerTracker {
+	return b.trackers.Get(symbol)
+}
+
 func (b *BinanceRunner) GetCache() TickerTrackerMap {
 	b.CacheLock.RLock()
 	defer b.CacheLock.RUnlock()
@@ -201,6 +266,10 @@ func (b *BinanceRunner) GetCache() TickerTrackerMap {
 }
 
 func (b *BinanceRunner) updateTrackers(trackers *TickerTrackerMap, tickers []binanceapi.TickerStreamMessage, recalculate bool) {
+	// Rates are taken from all tickers, including the markets that are not
+	// part of the universe.
+	b.rates.Update(tickers)
+
 	channel := make(chan binanceapi.TickerStreamMessage)
 	wg := sync.WaitGroup{}
 
@@ -213,6 +282,14 @@ func (b *BinanceRunner) updateTrackers(trackers *TickerTrackerMap, tickers []bin
 			}
 			count += 1
 			tracker := trackers.GetTracker(ticker.Symbol)
+			if info, ok := b.universe.Get(ticker.Symbol); ok {
+				tracker.lock.Lock()
+				tracker.BaseAsset = info.BaseAsset
+				tracker.QuoteAsset = info.QuoteAsset
+				tracker.QuoteUSD = b.rates.USD(info.QuoteAsset)
+				tracker.QuoteBTC = b.rates.BTC(info.QuoteAsset)
+				tracker.lock.Unlock()
+			}
 			tracker.Update(ticker)
 			if recalculate {
 				tracker.Recalculate()
@@ -227,6 +304,9 @@ func (b *BinanceRunner) updateTrackers(trackers *TickerTrackerMap, tickers []bin
 	}
 
 	for _, ticker := range tickers {
+		if !b.universe.Contains(ticker.Symbol) {
+			continue
+		}
 		channel <- ticker
 	}
 
@@ -0,0 +1,195 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Number of breadth snapshots kept, one per update.
+const breadthHistory = 120
+
+// Upper edges, in percent, of the price change histogram bins. The last
+// bin holds everything above the last edge.
+var breadthHistogramEdges = []float64{-10, -5, -3, -2, -1, -0.5, 0, 0.5, 1, 2, 3, 5, 10}
+
+type BreadthBucket struct {
+	Advancers int `json:"advancers" msgpack:"advancers"`
+	Decliners int `json:"decliners" msgpack:"decliners"`
+	Unchanged int `json:"unchanged" msgpack:"unchanged"`
+
+	// Share of the symbols with trades whose price is above the VWAP.
+	AboveVwapPercent float64 `json:"above_vwap_pct" msgpack:"above_vwap_pct"`
+
+	// Net volume summed by quote asset, and over all symbols in USD.
+	NetVolume    map[string]float64 `json:"net_volume" msgpack:"net_volume"`
+	NetVolumeUSD float64            `json:"net_volume_usd" msgpack:"net_volume_usd"`
+
+	// Number of symbols by price change, see breadthHistogramEdges.
+	Histogram []int `json:"histogram" msgpack:"histogram"`
+}
+
+// Breadth is a snapshot of the market as a whole.
+type Breadth struct {
+	Timestamp time.Time `json:"timestamp" msgpack:"timestamp"`
+	Symbols   int       `json:"symbols" msgpack:"symbols"`
+
+	// Upper edges of the histogram bins in percent.
+	HistogramEdges []float64 `json:"histogram_edges" msgpack:"histogram_edges"`
+
+	// Keyed by bucket in minutes.
+	Buckets map[int]*BreadthBucket `json:"buckets" msgpack:"buckets"`
+}
+
+func histogramBin(change float64) int {
+	for i, edge := range breadthHistogramEdges {
+		if change <= edge {
+			return i
+		}
+	}
+	return len(breadthHistogramEdges)
+}
+
+func CalculateBreadth(trackers *TickerTrackerMap, now time.Time) *Breadth {
+	breadth := &Breadth{
+		Timestamp:      now,
+		HistogramEdges: breadthHistogramEdges,
+		Buckets:        map[int]*BreadthBucket{},
+	}
+	withVwap := map[int]int{}
+	for _, bucket := range Buckets {
+		breadth.Buckets[bucket] = &BreadthBucket{
+			NetVolume: map[string]float64{},
+			Histogram: make([]int, len(breadthHistogramEdges)+1),
+		}
+	}
+
+	for _, tracker := range trackers.Trackers {
+		last := tracker.LastTick()
+		if last == nil {
+			continue
+		}
+		breadth.Symbols++
+		for _, bucket := range Buckets {
+			metrics := tracker.Metrics[bucket]
+			entry := breadth.Buckets[bucket]
+			change := metrics.PriceChangePercent
+			if math.IsNaN(change) {
+				continue
+			}
+			switch {
+			case change > 0:
+				entry.Advancers++
+			case change < 0:
+				entry.Decliners++
+			default:
+				entry.Unchanged++
+			}
+			entry.Histogram[histogramBin(change)]++
+
+			if tracker.HaveVwap && metrics.Vwap > 0 {
+				withVwap[bucket]++
+				if last.CurrentDayClose > metrics.Vwap {
+					entry.AboveVwapPercent++
+				}
+			}
+			if tracker.HaveNetVolume {
+				entry.NetVolume[tracker.QuoteAsset] += metrics.NetVolume
+				entry.NetVolumeUSD += metrics.USD.Net
+			}
+		}
+	}
+
+	for bucket, entry := range breadth.Buckets {
+		if withVwap[bucket] > 0 {
+			entry.AboveVwapPercent = Round3(entry.AboveVwapPercent / float64(withVwap[bucket]) * 100)
+		}
+		for quote, volume := range entry.NetVolume {
+			entry.NetVolume[quote] = Round8(volume)
+		}
+		entry.NetVolumeUSD = Round8(entry.NetVolumeUSD)
+	}
+
+	return breadth
+}
+
+// BreadthTracker calculates the market breadth after each update of the
+// trackers and publishes it on its feed, which also keeps the history.
+type BreadthTracker struct {
+	Feed *EventFeed
+}
+
+func NewBreadthTracker() *BreadthTracker {
+	return &BreadthTracker{
+		Feed: NewEventFeed(breadthHistory),
+	}
+}
+
+func (b *BreadthTracker) Run(channel chan *TickerTrackerMap) {
+	for trackers := range channel {
+		b.Feed.Publish("breadth", CalculateBreadth(trackers, time.Now()))
+	}
+}
+
+// Last returns the most recent breadth, nil if there is none yet.
+func (b *BreadthTracker) Last() *Breadth {
+	recent := b.Feed.Recent()
+	if len(recent) == 0 {
+		return nil
+	}
+	return recent[len(recent)-1].Event.(*Breadth)
+}
+
+// History returns the recent breadth snapshots, oldest first.
+func (b *BreadthTracker) History() []*Breadth {
+	history := []*Breadth{}
+	for _, message := range b.Feed.Recent() {
+		history = append(history, message.Event.(*Breadth))
+	}
+	return history
+}
+
+// breadthHandler serves the latest breadth, or with history=true all the
+// recent snapshots.
+func breadthHandler(tracker *BreadthTracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := BreadthResponse{
+			Breadth: tracker.Last(),
+		}
+		if r.FormValue("history") == "true" {
+			response.History = tracker.History()
+		}
+		w.Header().Add("content-type", "application/json")
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(response); err != nil {
+			log.WithError(err).WithField("handler", "breadth").
+				Errorf("Failed to encode response to JSON")
+		}
+	}
+}
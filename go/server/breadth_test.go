@@ -0,0 +1,124 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistogramBin(t *testing.T) {
+	tests := []struct {
+		change float64
+		bin    int
+	}{
+		{-20, 0},
+		{-10, 0},
+		{-9.9, 1},
+		{-0.5, 5},
+		{0, 6},
+		{0.1, 7},
+		{2, 9},
+		{10, 12},
+		{10.1, 13},
+	}
+	for _, test := range tests {
+		if bin := histogramBin(test.change); bin != test.bin {
+			t.Errorf("%v: expected bin %d, got %d", test.change, test.bin, bin)
+		}
+	}
+}
+
+func TestCalculateBreadth(t *testing.T) {
+	now := time.Date(2019, 2, 15, 12, 0, 0, 0, time.UTC)
+	trackers := NewTickerTrackerMap()
+	market := func(symbol string, quote string, close float64, metrics TickerMetrics) *TickerTracker {
+		tracker := trackers.GetTracker(symbol)
+		tracker.QuoteAsset = quote
+		tracker.Ticks = append(tracker.Ticks, &binanceapi.TickerStreamMessage{
+			Symbol:          symbol,
+			CurrentDayClose: close,
+		})
+		*tracker.Metrics[5] = metrics
+		return tracker
+	}
+	up := market("ETHBTC", "BTC", 10, TickerMetrics{
+		PriceChangePercent: 2, Vwap: 9, NetVolume: 1,
+		USD: NormalizedVolume{Net: 100},
+	})
+	up.HaveVwap, up.HaveNetVolume = true, true
+	down := market("XRPBTC", "BTC", 10, TickerMetrics{
+		PriceChangePercent: -0.5, Vwap: 11, NetVolume: -0.25,
+		USD: NormalizedVolume{Net: -25},
+	})
+	down.HaveVwap, down.HaveNetVolume = true, true
+	// Without trades for a VWAP.
+	flat := market("BTCUSDT", "USDT", 50000, TickerMetrics{NetVolume: 50, USD: NormalizedVolume{Net: 50}})
+	flat.HaveNetVolume = true
+	// Counted as a symbol but not in the bucket.
+	market("LTCBTC", "BTC", 0.01, TickerMetrics{PriceChangePercent: math.NaN()})
+	// No ticks yet.
+	trackers.GetTracker("ABCBTC")
+
+	breadth := CalculateBreadth(trackers, now)
+
+	if breadth.Symbols != 4 {
+		t.Errorf("expected 4 symbols, got %d", breadth.Symbols)
+	}
+	histogram := make([]int, len(breadthHistogramEdges)+1)
+	histogram[5], histogram[6], histogram[9] = 1, 1, 1
+	expected := &BreadthBucket{
+		Advancers:        1,
+		Decliners:        1,
+		Unchanged:        1,
+		AboveVwapPercent: 50,
+		NetVolume:        map[string]float64{"BTC": 0.75, "USDT": 50},
+		NetVolumeUSD:     125,
+		Histogram:        histogram,
+	}
+	if bucket := breadth.Buckets[5]; !reflect.DeepEqual(bucket, expected) {
+		t.Errorf("expected %+v, got %+v", expected, bucket)
+	}
+}
+
+func TestBreadthTrackerHistory(t *testing.T) {
+	tracker := NewBreadthTracker()
+	if tracker.Last() != nil {
+		t.Errorf("expected no breadth before the first update")
+	}
+	first := &Breadth{Symbols: 1}
+	second := &Breadth{Symbols: 2}
+	tracker.Feed.Publish("breadth", first)
+	tracker.Feed.Publish("breadth", second)
+	if last := tracker.Last(); last != second {
+		t.Errorf("expected the last breadth, got %+v", last)
+	}
+	if history := tracker.History(); !reflect.DeepEqual(history, []*Breadth{first, second}) {
+		t.Errorf("expected the history oldest first, got %+v", history)
+	}
+}
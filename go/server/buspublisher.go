@@ -0,0 +1,197 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"github.com/crankykernel/binanceapi-go"
+	"gitlab.com/crankykernel/cryptoxscanner/bus"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"strings"
+	"time"
+)
+
+// The trades, metrics and alerts of the scanner can be published to a
+// message bus. Topics are templates where {symbol} and {type} are
+// replaced by the symbol and the alert type. An empty topic disables that
+// output.
+
+type BusOptions struct {
+	Sink bus.SinkOptions
+
+	TradeTopic   string
+	MetricsTopic string
+	AlertTopic   string
+}
+
+var DefaultBusOptions = BusOptions{
+	TradeTopic:   "cryptoxscanner.trades.{symbol}",
+	MetricsTopic: "cryptoxscanner.metrics.{symbol}",
+	AlertTopic:   "cryptoxscanner.alerts.{type}",
+}
+
+// Trades and alerts are queued so a slow bus doesn't hold up the runner,
+// the oldest being dropped when the queue is full.
+const busQueueDepth = 4096
+
+// Dropped trades and alerts are logged at most this often.
+const busDropReportInterval = time.Minute
+
+// BusTrade is a trade as published to the bus.
+type BusTrade struct {
+	Symbol        string    `json:"symbol"`
+	Id            int64     `json:"id"`
+	Side          string    `json:"side"`
+	Price         float64   `json:"price"`
+	Quantity      float64   `json:"quantity"`
+	QuoteQuantity float64   `json:"quote_quantity"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func NewBusTrade(trade *binanceapi.StreamAggTrade) *BusTrade {
+	side := "buy"
+	if trade.BuyerMaker {
+		side = "sell"
+	}
+	return &BusTrade{
+		Symbol:        trade.Symbol,
+		Id:            trade.TradeID,
+		Side:          side,
+		Price:         trade.Price,
+		Quantity:      trade.Quantity,
+		QuoteQuantity: trade.QuoteQuantity(),
+		Timestamp:     trade.Timestamp(),
+	}
+}
+
+func busTopic(template string, symbol string, eventType string) string {
+	return strings.NewReplacer("{symbol}", symbol, "{type}", eventType).
+		Replace(template)
+}
+
+// eventSymbol returns the symbol of an alert event.
+func eventSymbol(event interface{}) string {
+	switch event := event.(type) {
+	case *PumpEvent:
+		return event.Symbol
+	case *WhaleTrade:
+		return event.Symbol
+	}
+	return ""
+}
+
+// BusPublisher publishes the output of a runner to a message bus.
+type BusPublisher struct {
+	sink    bus.Sink
+	options BusOptions
+
+	// The trades and alerts waiting to be published.
+	queue *WsSendQueue
+}
+
+func NewBusPublisher(sink bus.Sink, options BusOptions) *BusPublisher {
+	return &BusPublisher{
+		sink:    sink,
+		options: options,
+		queue: NewWsSendQueue("bus", WsQueueOptions{
+			Depth:  busQueueDepth,
+			Policy: WsQueueDropOldest,
+		}),
+	}
+}
+
+// Dropped returns the number of trades and alerts dropped as the bus did
+// not keep up.
+func (p *BusPublisher) Dropped() uint64 {
+	return p.queue.Dropped()
+}
+
+func (p *BusPublisher) publish(topic string, v interface{}) {
+	message, err := json.Marshal(v)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to encode message for %s", topic)
+		return
+	}
+	if err := p.sink.Publish(topic, message); err != nil {
+		log.WithError(err).Debugf("Failed to publish message to %s", topic)
+	}
+}
+
+// Run publishes a metrics snapshot of each symbol after every update of
+// the runner, and the trades and the events of alertFeeds as they come.
+func (p *BusPublisher) Run(binanceRunner *BinanceRunner, alertFeeds ...*EventFeed) {
+	if p.options.MetricsTopic != "" {
+		go p.publishMetrics(binanceRunner.Subscribe())
+	}
+
+	feeds := []*EventFeed{}
+	if p.options.TradeTopic != "" {
+		feeds = append(feeds, binanceRunner.TradeFeed)
+	}
+	if p.options.AlertTopic != "" {
+		feeds = append(feeds, alertFeeds...)
+	}
+	if len(feeds) > 0 {
+		// Subscribed before returning so no event is missed.
+		for _, feed := range feeds {
+			feed.Subscribe(p.queue, "")
+		}
+		go p.publishEvents()
+	}
+}
+
+func (p *BusPublisher) publishMetrics(channel chan *TickerTrackerMap) {
+	for trackers := range channel {
+		for symbol, tracker := range trackers.Trackers {
+			if entry := WsBuildCompleteEntry(tracker); entry != nil {
+				p.publish(busTopic(p.options.MetricsTopic, symbol, "metrics"), entry)
+			}
+		}
+	}
+}
+
+func (p *BusPublisher) publishEvents() {
+	reported := uint64(0)
+	lastReport := time.Time{}
+	for range p.queue.Ready() {
+		for item, ok := p.queue.Pop(); ok; item, ok = p.queue.Pop() {
+			message := item.message.(*WsEventMessage)
+			if trade, ok := message.Event.(binanceapi.StreamAggTrade); ok {
+				p.publish(busTopic(p.options.TradeTopic, trade.Symbol, message.Type),
+					NewBusTrade(&trade))
+				continue
+			}
+			p.publish(busTopic(p.options.AlertTopic, eventSymbol(message.Event),
+				message.Type), message)
+		}
+		dropped := p.queue.Dropped()
+		if dropped > reported && time.Since(lastReport) >= busDropReportInterval {
+			log.Warnf("Dropped %d trades and alerts as the message bus is not keeping up, %d in total",
+				dropped-reported, dropped)
+			reported = dropped
+			lastReport = time.Now()
+		}
+	}
+}
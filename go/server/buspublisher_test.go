@@ -0,0 +1,173 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"github.com/crankykernel/binanceapi-go"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"gitlab.com/crankykernel/cryptoxscanner/bus"
+	"testing"
+	"time"
+)
+
+func TestBusPublisherNats(t *testing.T) {
+	serverOptions := natsserver.DefaultTestOptions
+	serverOptions.Port = -1
+	natsServer := natsserver.RunServer(&serverOptions)
+	defer natsServer.Shutdown()
+
+	conn, err := nats.Connect(natsServer.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	trades, _ := conn.SubscribeSync("cryptoxscanner.trades.ETHBTC")
+	metrics, _ := conn.SubscribeSync("cryptoxscanner.metrics.ETHBTC")
+	alerts, _ := conn.SubscribeSync("cryptoxscanner.alerts.pump")
+	if err := conn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	sink, err := bus.Open(bus.SinkOptions{Type: "nats", Url: natsServer.ClientURL()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	runner := NewBinanceRunner(nil, nil)
+	pumpFeed := NewEventFeed(0)
+	NewBusPublisher(sink, DefaultBusOptions).Run(runner, pumpFeed)
+
+	now := time.Now()
+	trackers := NewTickerTrackerMap()
+	tracker := trackers.GetTracker("ETHBTC")
+	tracker.QuoteAsset = "BTC"
+	tracker.Update(binanceapi.TickerStreamMessage{
+		Symbol:           "ETHBTC",
+		EventTime:        now.UnixNano() / int64(time.Millisecond),
+		CurrentDayClose:  0.03,
+		TotalQuoteVolume: 1000,
+	})
+	tracker.Recalculate()
+	for channel := range runner.subscribers {
+		channel <- trackers
+	}
+	runner.TradeFeed.Publish("trade", binanceapi.StreamAggTrade{
+		Symbol:    "ETHBTC",
+		TradeID:   42,
+		Price:     0.03,
+		Quantity:  2,
+		TradeTime: now.UnixNano() / int64(time.Millisecond),
+	})
+	pumpFeed.Publish("pump", &PumpEvent{Symbol: "ETHBTC", Stage: "start"})
+
+	msg, err := trades.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatalf("no trade: %v", err)
+	}
+	trade := BusTrade{}
+	if err := json.Unmarshal(msg.Data, &trade); err != nil {
+		t.Fatal(err)
+	}
+	if trade.Id != 42 || trade.Side != "buy" || trade.QuoteQuantity != 0.06 {
+		t.Errorf("unexpected trade %+v", trade)
+	}
+
+	msg, err = metrics.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatalf("no metrics: %v", err)
+	}
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(msg.Data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["symbol"] != "ETHBTC" || entry["close"] != 0.03 {
+		t.Errorf("unexpected metrics %v", entry)
+	}
+
+	msg, err = alerts.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatalf("no alert: %v", err)
+	}
+	alert := struct {
+		Type  string    `json:"type"`
+		Event PumpEvent `json:"event"`
+	}{}
+	if err := json.Unmarshal(msg.Data, &alert); err != nil {
+		t.Fatal(err)
+	}
+	if alert.Type != "pump" || alert.Event.Symbol != "ETHBTC" || alert.Event.Stage != "start" {
+		t.Errorf("unexpected alert %s", msg.Data)
+	}
+}
+
+// blockingSink passes the published topics on and then blocks the
+// publisher until released.
+type blockingSink struct {
+	published chan string
+	release   chan bool
+}
+
+func (s *blockingSink) Publish(topic string, message []byte) error {
+	s.published <- topic
+	<-s.release
+	return nil
+}
+
+func (s *blockingSink) Close() error {
+	return nil
+}
+
+// TestBusPublisherDropped checks that the trades that do not fit in the
+// queue while the bus is blocked are dropped and counted.
+func TestBusPublisherDropped(t *testing.T) {
+	sink := &blockingSink{published: make(chan string, 4), release: make(chan bool)}
+	publisher := NewBusPublisher(sink, BusOptions{TradeTopic: "trades"})
+	publisher.queue = NewWsSendQueue("bus", WsQueueOptions{Depth: 2})
+	runner := NewBinanceRunner(nil, nil)
+	publisher.Run(runner)
+
+	trade := binanceapi.StreamAggTrade{Symbol: "ETHBTC"}
+	runner.TradeFeed.Publish("trade", trade)
+	<-sink.published
+
+	// The publisher is blocked on the first trade.
+	for i := 0; i < 4; i++ {
+		runner.TradeFeed.Publish("trade", trade)
+	}
+	if dropped := publisher.Dropped(); dropped != 2 {
+		t.Errorf("expected 2 dropped, got %d", dropped)
+	}
+	close(sink.release)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-sink.published:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the 2 queued trades to be published")
+		}
+	}
+}
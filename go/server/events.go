@@ -0,0 +1,260 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Number of recent events sent to clients of the events feeds when they
+// connect.
+const eventFeedHistory = 50
+
+// WsEventMessage is a message of the events feed. The type tells what kind
+// of event it carries.
+type WsEventMessage struct {
+	Type  string      `json:"type" msgpack:"type"`
+	Event interface{} `json:"event" msgpack:"event"`
+}
+
+// EventFeed streams detected events to WebSocket clients. Unlike the
+// ticker feeds it has no snapshot; clients receive the most recent events
+// when they connect and then each event as it is published.
+type EventFeed struct {
+	subscribers map[wsSubscriber]bool
+	recent      []*WsEventMessage
+	history     int
+	lock        sync.RWMutex
+}
+
+// NewEventFeed creates a feed that keeps the given number of recent
+// messages for new clients.
+func NewEventFeed(history int) *EventFeed {
+	return &EventFeed{
+		subscribers: map[wsSubscriber]bool{},
+		history:     history,
+	}
+}
+
+func (f *EventFeed) Publish(eventType string, event interface{}) {
+	message := &WsEventMessage{
+		Type:  eventType,
+		Event: event,
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.recent = append(f.recent, message)
+	if len(f.recent) > f.history {
+		f.recent = f.recent[1:]
+	}
+	for subscriber := range f.subscribers {
+		subscriber.queue.Push(wsQueueItem{
+			key:     subscriber.key,
+			message: message,
+		})
+	}
+}
+
+// Subscribe queues each new event for a client until unsubscribed. The
+// recent events are returned rather than queued, as there may be more of
+// them than fit in the queue.
+func (f *EventFeed) Subscribe(queue *WsSendQueue, key string) []*WsEventMessage {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.subscribers[wsSubscriber{queue: queue, key: key}] = true
+	return append([]*WsEventMessage{}, f.recent...)
+}
+
+func (f *EventFeed) Unsubscribe(queue *WsSendQueue, key string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.subscribers, wsSubscriber{queue: queue, key: key})
+}
+
+// Recent returns the recent messages, oldest first.
+func (f *EventFeed) Recent() []*WsEventMessage {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return append([]*WsEventMessage{}, f.recent...)
+}
+
+type EventWebSocketHandler struct {
+	upgrader websocket.Upgrader
+	feed     *EventFeed
+}
+
+func NewEventWebSocketHandler(feed *EventFeed) *EventWebSocketHandler {
+	return &EventWebSocketHandler{
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+			EnableCompression: true,
+			Subprotocols:      wsSubprotocols,
+		},
+		feed: feed,
+	}
+}
+
+// Handle streams the events feed. Clients may limit the event types they
+// receive with a comma separated "types" parameter.
+func (h *EventWebSocketHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Infof("Failed to upgrade websocket connection: %v", err)
+		return
+	}
+	client := NewWebSocketClient(conn, r)
+	log.Infof("WebSocket connnected to %s: RemoteAddr=%v; Origin=%s",
+		r.URL.String(),
+		client.GetRemoteAddr(),
+		r.Header.Get("origin"))
+
+	wsConnectionTracker.Add(r.URL.String(), client)
+	defer wsConnectionTracker.Del(r.URL.String(), client)
+
+	types := map[string]bool{}
+	if r.FormValue("types") != "" {
+		for _, eventType := range strings.Split(r.FormValue("types"), ",") {
+			types[strings.TrimSpace(eventType)] = true
+		}
+	}
+
+	// Nothing is expected from the client, read until the connection
+	// closes. The signal is not waited for, as the handler may have
+	// ended on a write error already.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				break
+			}
+		}
+		select {
+		case client.closeChannel <- true:
+		default:
+		}
+	}()
+
+	write := func(message *WsEventMessage) error {
+		if len(types) > 0 && !types[message.Type] {
+			return nil
+		}
+		buf, err := client.encoding.Marshal(message)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to encode event message")
+			return nil
+		}
+		client.setWriteDeadline()
+		if err := conn.WriteMessage(client.encoding.MessageType(), buf); err != nil {
+			log.WithError(err).Errorf("Failed to write websocket message to %s",
+				client.GetRemoteAddr())
+			return err
+		}
+		return nil
+	}
+
+	queue := client.queue
+	recent := h.feed.Subscribe(queue, "")
+	defer h.feed.Unsubscribe(queue, "")
+
+	for _, message := range recent {
+		if err := write(message); err != nil {
+			goto Done
+		}
+	}
+
+	for {
+		select {
+		case <-queue.Ready():
+			for item, ok := queue.Pop(); ok; item, ok = queue.Pop() {
+				if err := write(item.message.(*WsEventMessage)); err != nil {
+					goto Done
+				}
+			}
+		case <-client.closeChannel:
+			goto Done
+		}
+	}
+Done:
+	conn.Close()
+	log.Infof("WebSocket connection closed: %v", client.GetRemoteAddr())
+}
+
+// EventHandler serves stored events. Parameters: type, symbol, since (unix
+// seconds, default 24 hours ago) and limit (default 100).
+type EventHandler struct {
+	store *db.EventStore
+}
+
+func NewEventHandler(store *db.EventStore) *EventHandler {
+	return &EventHandler{
+		store: store,
+	}
+}
+
+func (h *EventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := db.EventQuery{
+		Type:   r.FormValue("type"),
+		Symbol: strings.ToUpper(r.FormValue("symbol")),
+		Since:  time.Now().Add(-time.Hour * 24),
+		Limit:  100,
+	}
+	if since, err := strconv.ParseInt(r.FormValue("since"), 10, 64); err == nil {
+		query.Since = time.Unix(since, 0)
+	}
+	if limit, err := strconv.Atoi(r.FormValue("limit")); err == nil && limit > 0 && limit <= 1000 {
+		query.Limit = limit
+	}
+
+	bodies, err := h.store.Query(query)
+	if err != nil {
+		log.WithError(err).WithField("handler", "events").
+			Errorf("Failed to query events")
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	events := []json.RawMessage{}
+	for _, body := range bodies {
+		events = append(events, json.RawMessage(body))
+	}
+
+	w.Header().Add("content-type", "application/json")
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(EventsResponse{
+		Events: events,
+	}); err != nil {
+		log.WithError(err).WithField("handler", "events").
+			Errorf("Failed to encode response to JSON")
+	}
+}
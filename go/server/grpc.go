@@ -0,0 +1,496 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/crankykernel/binanceapi-go"
+	"gitlab.com/crankykernel/cryptoxscanner/grpcapi"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Depth of the send queue of trade streams, which get every trade of the
+// universe rather than one update a second.
+const grpcTradeQueueDepth = 1024
+
+// GrpcServer implements the gRPC API described in grpcapi/scanner.proto.
+type GrpcServer struct {
+	grpcapi.UnimplementedScannerServer
+	binanceRunner *BinanceRunner
+	tickers       *WsSourceCache
+	alertFeeds    []*EventFeed
+}
+
+// NewGrpcServer creates the gRPC API. StreamTickers clients are sent the
+// tickers built by GrpcBuildTickers for the tickers feed, and the events
+// of alertFeeds are sent to StreamAlerts clients.
+func NewGrpcServer(binanceRunner *BinanceRunner, tickers *WsSourceCache, alertFeeds ...*EventFeed) *GrpcServer {
+	return &GrpcServer{
+		binanceRunner: binanceRunner,
+		tickers:       tickers,
+		alertFeeds:    alertFeeds,
+	}
+}
+
+// ListenAndServe serves the API on port with the limits of limiter, only
+// returning on failure.
+func (s *GrpcServer) ListenAndServe(port uint16, limiter *Limiter) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	log.Printf("Starting gRPC server on port %d.", port)
+	return s.newServer(limiter).Serve(listener)
+}
+
+func (s *GrpcServer) newServer(limiter *Limiter) *grpc.Server {
+	server := grpc.NewServer(limiter.GrpcServerOptions()...)
+	grpcapi.RegisterScannerServer(server, s)
+	return server
+}
+
+// grpcRequest returns a request for the client of a gRPC call, with the
+// forwarding headers of a reverse proxy taken from the metadata, so it can
+// be identified like an HTTP client.
+func grpcRequest(ctx context.Context) *http.Request {
+	r := &http.Request{Header: http.Header{}}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, name := range []string{"x-forwarded-for", "x-real-ip"} {
+			for _, value := range md.Get(name) {
+				r.Header.Add(name, value)
+			}
+		}
+	}
+	return r
+}
+
+func grpcRemoteHost(ctx context.Context) string {
+	return requestRemoteHost(grpcRequest(ctx))
+}
+
+func grpcMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func grpcNormalizedVolume(v NormalizedVolume) *grpcapi.NormalizedVolume {
+	return &grpcapi.NormalizedVolume{
+		Total: v.Total,
+		Net:   v.Net,
+		Buy:   v.Buy,
+		Sell:  v.Sell,
+	}
+}
+
+func grpcTickerMetrics(m *TickerMetrics) *grpcapi.TickerMetrics {
+	rsi := math.NaN()
+	if m.RSIKnown {
+		rsi = m.RSI
+	}
+	return &grpcapi.TickerMetrics{
+		PriceChangePct:  m.PriceChangePercent,
+		VolumeChangePct: m.VolumeChangePercent,
+		High:            m.High,
+		Low:             m.Low,
+		Range:           m.Range,
+		RangePct:        m.RangePercent,
+		Vwap:            m.Vwap,
+		VwapSd:          m.VwapStdDev,
+		TotalVolume:     m.TotalVolume,
+		NetVolume:       m.NetVolume,
+		BuyVolume:       m.BuyVolume,
+		SellVolume:      m.SellVolume,
+		Rsi:             rsi,
+		Trades:          m.TotalTrades,
+		SellTrades:      m.SellTrades,
+		BuyTrades:       m.BuyTrades,
+		Usd:             grpcNormalizedVolume(m.USD),
+		Btc:             grpcNormalizedVolume(m.BTC),
+		ZPrice:          m.PriceZScore,
+		ZVolume:         m.VolumeZScore,
+		ZTrades:         m.TradeRateZScore,
+		BetaBtc:         m.BetaBTC,
+		CorrBtc:         m.CorrelationBTC,
+		RsBtc:           m.RelativeStrengthBTC,
+		BetaMarket:      m.BetaMarket,
+		CorrMarket:      m.CorrelationMarket,
+		RsMarket:        m.RelativeStrengthMarket,
+		WhaleBuyVolume:  m.WhaleBuyVolume,
+		WhaleSellVolume: m.WhaleSellVolume,
+		WhaleTrades:     m.WhaleTrades,
+	}
+}
+
+// grpcTicker returns the ticker of a tracker, or nil if it has not
+// received a ticker yet.
+func grpcTicker(tracker *TickerTracker) *grpcapi.Ticker {
+	last := tracker.LastTick()
+	if last == nil {
+		return nil
+	}
+	ticker := &grpcapi.Ticker{
+		Symbol:             tracker.Symbol,
+		Base:               tracker.BaseAsset,
+		Quote:              tracker.QuoteAsset,
+		Close:              last.CurrentDayClose,
+		Bid:                last.Bid,
+		Ask:                last.Ask,
+		High:               last.HighPrice,
+		Low:                last.LowPrice,
+		Volume:             last.TotalQuoteVolume,
+		VolumeUsd:          Round8(last.TotalQuoteVolume * tracker.QuoteUSD),
+		VolumeBtc:          Round8(last.TotalQuoteVolume * tracker.QuoteBTC),
+		PriceChangePct_24H: last.PriceChangePercent,
+		Range_24H:          tracker.H24Metrics.Range,
+		RangePct_24H:       tracker.H24Metrics.RangePercent,
+		PumpScore:          tracker.Pump.Score,
+		Timestamp:          grpcMillis(last.Timestamp()),
+		Metrics:            map[int32]*grpcapi.TickerMetrics{},
+	}
+	for bucket, metrics := range tracker.Metrics {
+		ticker.Metrics[int32(bucket)] = grpcTickerMetrics(metrics)
+	}
+	return ticker
+}
+
+// GrpcBuildTickers returns the tickers of the trackers that have received
+// a ticker, for the StreamTickers clients of a WsSourceCache.
+func GrpcBuildTickers(trackers *TickerTrackerMap) []interface{} {
+	tickers := []interface{}{}
+	for _, tracker := range trackers.Trackers {
+		tracker.lock.RLock()
+		ticker := grpcTicker(tracker)
+		tracker.lock.RUnlock()
+		if ticker != nil {
+			tickers = append(tickers, ticker)
+		}
+	}
+	return tickers
+}
+
+func grpcCandles(tracker *TickerTracker, limit int) map[int32]*grpcapi.Candles {
+	candles := map[int32]*grpcapi.Candles{}
+	for _, bucket := range Buckets {
+		aggs := tracker.Aggs[bucket]
+		if len(aggs) > limit {
+			aggs = aggs[len(aggs)-limit:]
+		}
+		bucketCandles := &grpcapi.Candles{}
+		for _, agg := range aggs {
+			bucketCandles.Aggregates = append(bucketCandles.Aggregates, &grpcapi.Aggregate{
+				Time:            grpcMillis(agg.Time),
+				Open:            agg.Open,
+				High:            agg.High,
+				Low:             agg.Low,
+				Close:           agg.Close,
+				QuoteVolume_24H: agg.QuoteVolume24,
+			})
+		}
+		candles[int32(bucket)] = bucketCandles
+	}
+	return candles
+}
+
+func grpcPumpEvent(event *PumpEvent) *grpcapi.PumpEvent {
+	pump := &grpcapi.PumpEvent{
+		Id:         event.Id,
+		Symbol:     event.Symbol,
+		Stage:      event.Stage,
+		Start:      grpcMillis(event.Start),
+		StartPrice: event.StartPrice,
+		PeakTime:   grpcMillis(event.PeakTime),
+		PeakPrice:  event.PeakPrice,
+		PeakScore:  event.PeakScore,
+		Price:      event.Price,
+		Score: &grpcapi.PumpScore{
+			Score:        event.Score.Score,
+			Price:        event.Score.Price,
+			Acceleration: event.Score.Acceleration,
+			BuyRatio:     event.Score.BuyRatio,
+			NetVolume:    event.Score.NetVolume,
+			Trades:       event.Score.Trades,
+		},
+		GainPct: event.GainPercent,
+	}
+	if event.End != nil {
+		pump.End = grpcMillis(*event.End)
+	}
+	return pump
+}
+
+func grpcWhaleTrade(trade *WhaleTrade) *grpcapi.WhaleTrade {
+	whale := &grpcapi.WhaleTrade{
+		Symbol:        trade.Symbol,
+		Side:          trade.Side,
+		Price:         trade.Price,
+		Quantity:      trade.Quantity,
+		QuoteQuantity: trade.QuoteQuantity,
+		Usd:           math.NaN(),
+		Threshold:     trade.Threshold,
+		Timestamp:     grpcMillis(trade.Timestamp),
+		ImpactPct:     trade.ImpactPercent,
+	}
+	if trade.USD != nil {
+		whale.Usd = *trade.USD
+	}
+	return whale
+}
+
+// grpcAlert returns the alert of an events feed message, or nil if it is
+// not an alert.
+func grpcAlert(message *WsEventMessage) *grpcapi.Alert {
+	switch event := message.Event.(type) {
+	case *PumpEvent:
+		return &grpcapi.Alert{
+			Type:   message.Type,
+			Symbol: event.Symbol,
+			Event:  &grpcapi.Alert_Pump{Pump: grpcPumpEvent(event)},
+		}
+	case *WhaleTrade:
+		return &grpcapi.Alert{
+			Type:   message.Type,
+			Symbol: event.Symbol,
+			Event:  &grpcapi.Alert_Whale{Whale: grpcWhaleTrade(event)},
+		}
+	}
+	return nil
+}
+
+// grpcFilter returns a set of the values, nil if there are none.
+func grpcFilter(values []string, upper bool) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	filter := map[string]bool{}
+	for _, value := range values {
+		if upper {
+			value = strings.ToUpper(value)
+		}
+		filter[value] = true
+	}
+	return filter
+}
+
+func (s *GrpcServer) GetSymbol(ctx context.Context, request *grpcapi.GetSymbolRequest) (*grpcapi.Ticker, error) {
+	if request.Candles < 0 || request.Candles > symbolMaxLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid candles: %d", request.Candles)
+	}
+	tracker := s.binanceRunner.GetTracker(strings.ToUpper(request.Symbol))
+	if tracker == nil {
+		return nil, status.Errorf(codes.NotFound, "unknown symbol: %s", request.Symbol)
+	}
+	tracker.lock.RLock()
+	defer tracker.lock.RUnlock()
+	ticker := grpcTicker(tracker)
+	if ticker == nil {
+		return nil, status.Errorf(codes.NotFound, "no ticker for symbol: %s", request.Symbol)
+	}
+	if request.Candles > 0 {
+		ticker.Candles = grpcCandles(tracker, int(request.Candles))
+	}
+	return ticker, nil
+}
+
+func (s *GrpcServer) Screener(ctx context.Context, request *grpcapi.ScreenerRequest) (*grpcapi.ScreenerResponse, error) {
+	query := &ScreenerQuery{
+		Sort:      request.Sort,
+		Ascending: request.Ascending,
+		Offset:    int(request.Offset),
+		Limit:     int(request.Limit),
+		MinVolume: request.MinVolume,
+	}
+	if query.Sort == "" {
+		query.Sort = "volume"
+	}
+	if query.Limit == 0 {
+		query.Limit = screenerDefaultLimit
+	}
+	if query.Limit < 0 || query.Limit > screenerMaxLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit: %d", request.Limit)
+	}
+	if query.Offset < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid offset: %d", request.Offset)
+	}
+	for _, quote := range request.QuoteAssets {
+		query.QuoteAssets = append(query.QuoteAssets, strings.ToUpper(quote))
+	}
+	for _, value := range request.Filters {
+		filter, err := ParseScreenerFilter(value)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		query.Filters = append(query.Filters, filter)
+	}
+
+	trackers := s.binanceRunner.GetCache()
+	ranked, total := query.Rank(&trackers)
+	response := &grpcapi.ScreenerResponse{
+		Total: int32(total),
+	}
+	for _, tracker := range ranked {
+		response.Tickers = append(response.Tickers, grpcTicker(tracker))
+	}
+	return response, nil
+}
+
+func (s *GrpcServer) StreamTickers(request *grpcapi.StreamTickersRequest, stream grpc.ServerStreamingServer[grpcapi.TickerUpdate]) error {
+	symbols := grpcFilter(request.Symbols, true)
+	updateInterval := time.Second * time.Duration(request.UpdateInterval)
+	lastUpdate := time.Time{}
+
+	client := newGrpcStreamClient(stream.Context())
+	path, _ := grpc.MethodFromServerStream(stream)
+	wsConnectionTracker.Add("grpc:"+path, client)
+	defer wsConnectionTracker.Del("grpc:"+path, client)
+
+	queue := client.queue
+	s.tickers.Subscribe(queue, "")
+	defer s.tickers.Unsubscribe(queue, "")
+
+	for {
+		select {
+		case <-queue.Ready():
+			for item, ok := queue.Pop(); ok; item, ok = queue.Pop() {
+				if item.update.Proto == nil ||
+					time.Now().Sub(lastUpdate) < updateInterval {
+					continue
+				}
+				update := &grpcapi.TickerUpdate{}
+				for _, entry := range item.update.Proto {
+					ticker := entry.(*grpcapi.Ticker)
+					if symbols != nil && !symbols[ticker.Symbol] {
+						continue
+					}
+					update.Tickers = append(update.Tickers, ticker)
+				}
+				if err := stream.Send(update); err != nil {
+					return err
+				}
+				lastUpdate = time.Now()
+			}
+		case <-client.closeChannel:
+			return status.Error(codes.ResourceExhausted, "client too slow")
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *GrpcServer) StreamTrades(request *grpcapi.StreamTradesRequest, stream grpc.ServerStreamingServer[grpcapi.Trade]) error {
+	symbols := grpcFilter(request.Symbols, true)
+	client := newGrpcStreamClient(stream.Context())
+	client.queue = client.newQueue(WsQueueOptions{
+		Depth:  grpcTradeQueueDepth,
+		Policy: wsQueueOptions.Policy,
+	})
+	return streamFeeds(stream, client, []*EventFeed{s.binanceRunner.TradeFeed},
+		func(message *WsEventMessage) error {
+			trade, ok := message.Event.(binanceapi.StreamAggTrade)
+			if !ok || (symbols != nil && !symbols[trade.Symbol]) {
+				return nil
+			}
+			return stream.Send(&grpcapi.Trade{
+				Symbol:     trade.Symbol,
+				Id:         trade.TradeID,
+				Price:      trade.Price,
+				Quantity:   trade.Quantity,
+				BuyerMaker: trade.BuyerMaker,
+				Timestamp:  trade.TradeTime,
+			})
+		})
+}
+
+func (s *GrpcServer) StreamAlerts(request *grpcapi.StreamAlertsRequest, stream grpc.ServerStreamingServer[grpcapi.Alert]) error {
+	types := grpcFilter(request.Types, false)
+	symbols := grpcFilter(request.Symbols, true)
+	client := newGrpcStreamClient(stream.Context())
+	return streamFeeds(stream, client, s.alertFeeds,
+		func(message *WsEventMessage) error {
+			alert := grpcAlert(message)
+			if alert == nil || (types != nil && !types[alert.Type]) ||
+				(symbols != nil && !symbols[alert.Symbol]) {
+				return nil
+			}
+			return stream.Send(alert)
+		})
+}
+
+// newGrpcStreamClient returns a client for a gRPC stream so it can use the
+// send queues, which identify clients by their request.
+func newGrpcStreamClient(ctx context.Context) *WebSocketClient {
+	return NewStreamClient(grpcRequest(ctx), WsEncodingProto)
+}
+
+// streamFeeds passes the recent and then each new message of feeds to send
+// until the stream ends, send fails, or the client falls behind under the
+// disconnect queue policy.
+func streamFeeds(stream grpc.ServerStream, client *WebSocketClient, feeds []*EventFeed,
+	send func(message *WsEventMessage) error) error {
+	path, _ := grpc.MethodFromServerStream(stream)
+	wsConnectionTracker.Add("grpc:"+path, client)
+	defer wsConnectionTracker.Del("grpc:"+path, client)
+
+	queue := client.queue
+	for _, feed := range feeds {
+		recent := feed.Subscribe(queue, "")
+		defer feed.Unsubscribe(queue, "")
+		for _, message := range recent {
+			if err := send(message); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-queue.Ready():
+			for item, ok := queue.Pop(); ok; item, ok = queue.Pop() {
+				if err := send(item.message.(*WsEventMessage)); err != nil {
+					return err
+				}
+			}
+		case <-client.closeChannel:
+			return status.Error(codes.ResourceExhausted, "client too slow")
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"context"
+	"github.com/crankykernel/binanceapi-go"
+	"gitlab.com/crankykernel/cryptoxscanner/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestGrpcClient serves server with the limits of limiter over an in
+// memory connection and returns a client for it.
+func newTestGrpcClient(t *testing.T, server *GrpcServer, limiter *Limiter) grpcapi.ScannerClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := server.newServer(limiter)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpcapi.NewScannerClient(conn)
+}
+
+func TestGrpcGetSymbol(t *testing.T) {
+	runner := NewBinanceRunner(nil, nil)
+	server := NewGrpcServer(runner, nil)
+
+	now := time.Date(2019, 2, 15, 12, 0, 0, 0, time.UTC)
+	tracker := runner.trackers.GetTracker("ETHBTC")
+	tracker.Update(binanceapi.TickerStreamMessage{
+		Symbol:          "ETHBTC",
+		EventTime:       milliseconds(now),
+		CurrentDayClose: 0.03,
+	})
+	for i := 0; i < 3; i++ {
+		tracker.AddTrade(binanceapi.StreamAggTrade{
+			Symbol:    "ETHBTC",
+			Price:     0.03,
+			Quantity:  1,
+			TradeTime: milliseconds(now.Add(time.Duration(i) * time.Minute)),
+		})
+	}
+	runner.trackers.GetTracker("XRPBTC")
+
+	tests := []struct {
+		symbol  string
+		candles int32
+		code    codes.Code
+		count   int
+	}{
+		{"ethbtc", 0, codes.OK, 0},
+		{"ETHBTC", 2, codes.OK, 2},
+		{"ETHBTC", 10, codes.OK, 3},
+		{"ETHBTC", -1, codes.InvalidArgument, 0},
+		{"ETHBTC", symbolMaxLimit + 1, codes.InvalidArgument, 0},
+		{"LTCBTC", 0, codes.NotFound, 0},
+		// Tracked, but without a ticker yet.
+		{"XRPBTC", 0, codes.NotFound, 0},
+	}
+	for _, test := range tests {
+		ticker, err := server.GetSymbol(context.Background(), &grpcapi.GetSymbolRequest{
+			Symbol:  test.symbol,
+			Candles: test.candles,
+		})
+		if status.Code(err) != test.code {
+			t.Errorf("%s %d: expected %v, got %v", test.symbol, test.candles, test.code, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if ticker.Symbol != "ETHBTC" || ticker.Close != 0.03 {
+			t.Errorf("%s: unexpected ticker %v", test.symbol, ticker)
+		}
+		if count := len(ticker.Candles[1].GetAggregates()); count != test.count {
+			t.Errorf("%s %d: expected %d 1m candles, got %d",
+				test.symbol, test.candles, test.count, count)
+		}
+	}
+}
+
+// TestGrpcGetSymbolConcurrentTrades gets a symbol with its candles while
+// the runner adds trades, for go test -race.
+func TestGrpcGetSymbolConcurrentTrades(t *testing.T) {
+	runner := NewBinanceRunner(nil, nil)
+	server := NewGrpcServer(runner, nil)
+
+	now := time.Now()
+	tracker := runner.trackers.GetTracker("ETHBTC")
+	tracker.Update(binanceapi.TickerStreamMessage{
+		Symbol:          "ETHBTC",
+		EventTime:       milliseconds(now),
+		CurrentDayClose: 0.03,
+	})
+
+	done := make(chan bool)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			tracker.AddTrade(binanceapi.StreamAggTrade{
+				Symbol:    "ETHBTC",
+				Price:     0.03,
+				Quantity:  1,
+				TradeTime: milliseconds(now.Add(time.Duration(i) * time.Second)),
+			})
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		_, err := server.GetSymbol(context.Background(), &grpcapi.GetSymbolRequest{
+			Symbol:  "ETHBTC",
+			Candles: 10,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+// TestGrpcStreamTickers checks that the tickers are built once per update
+// and shared by the stream clients.
+func TestGrpcStreamTickers(t *testing.T) {
+	builds := int32(0)
+	source := make(chan *TickerTrackerMap)
+	tickers := NewWsSourceCache("live", source, WsBuildCompleteMessage, nil,
+		func(trackers *TickerTrackerMap) []interface{} {
+			atomic.AddInt32(&builds, 1)
+			return GrpcBuildTickers(trackers)
+		})
+	go tickers.Run()
+	client := newTestGrpcClient(t, NewGrpcServer(NewBinanceRunner(nil, nil), tickers), NewLimiter(Limits{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	all, err := client.StreamTickers(ctx, &grpcapi.StreamTickersRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := client.StreamTickers(ctx, &grpcapi.StreamTickersRequest{
+		Symbols: []string{"ethbtc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		tickers.lock.RLock()
+		subscribers := len(tickers.subscribers)
+		tickers.lock.RUnlock()
+		if subscribers == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 subscribers, got %d", subscribers)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	now := time.Now()
+	trackers := NewTickerTrackerMap()
+	for _, symbol := range []string{"ETHBTC", "LTCBTC"} {
+		trackers.GetTracker(symbol).Update(binanceapi.TickerStreamMessage{
+			Symbol:          symbol,
+			EventTime:       milliseconds(now),
+			CurrentDayClose: 0.03,
+		})
+	}
+	source <- trackers
+
+	for _, test := range []struct {
+		stream   grpcapi.Scanner_StreamTickersClient
+		expected int
+	}{
+		{all, 2},
+		{filtered, 1},
+	} {
+		update, err := test.stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(update.Tickers) != test.expected {
+			t.Errorf("expected %d tickers, got %v", test.expected, update.Tickers)
+		}
+	}
+	if builds := atomic.LoadInt32(&builds); builds != 1 {
+		t.Errorf("expected the tickers to be built once, got %d", builds)
+	}
+}
+
+func TestGrpcLimits(t *testing.T) {
+	server := NewGrpcServer(NewBinanceRunner(nil, nil), nil, NewEventFeed(0))
+	limiter := NewLimiter(Limits{
+		WsConnectionsPerIP: 1,
+		Api:                RateLimit{Rate: 0.01, Burst: 2},
+	})
+	client := newTestGrpcClient(t, server, limiter)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Calls are limited per method.
+	for i, expected := range []codes.Code{codes.NotFound, codes.NotFound, codes.ResourceExhausted} {
+		_, err := client.GetSymbol(ctx, &grpcapi.GetSymbolRequest{Symbol: "ETHBTC"})
+		if status.Code(err) != expected {
+			t.Errorf("GetSymbol %d: expected %v, got %v", i, expected, err)
+		}
+	}
+	if _, err := client.Screener(ctx, &grpcapi.ScreenerRequest{Limit: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Screener: expected %v, got %v", codes.InvalidArgument, err)
+	}
+
+	// Streams count against the connection limit while open.
+	_, err := client.StreamAlerts(ctx, &grpcapi.StreamAlertsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		limiter.connections.lock.Lock()
+		open := len(limiter.connections.count)
+		limiter.connections.lock.Unlock()
+		if open == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("first stream not opened")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	second, err := client.StreamAlerts(ctx, &grpcapi.StreamAlertsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Recv(); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second stream: expected %v, got %v", codes.ResourceExhausted, err)
+	}
+}
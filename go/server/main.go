@@ -32,12 +32,14 @@ import (
 	"github.com/gobuffalo/packr"
 	"github.com/gorilla/mux"
 	"gitlab.com/crankykernel/cryptoxscanner/binance"
+	"gitlab.com/crankykernel/cryptoxscanner/bus"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
 	"gitlab.com/crankykernel/cryptoxscanner/log"
 	"gitlab.com/crankykernel/cryptoxscanner/version"
-	"math"
 	"math/rand"
 	"net/http"
 	_ "net/http/pprof"
+	"sort"
 	"time"
 )
 
@@ -50,41 +52,164 @@ func init() {
 }
 
 type Options struct {
-	Port uint16
+	Port         uint16
+	GrpcPort     uint16
+	Metrics      MetricsOptions
+	SymbolFilter binance.SymbolFilter
+	WsQueue      WsQueueOptions
+	Limits       Limits
+	Proxy        binance.ProxyOptions
+	Pump         PumpOptions
+	Whales       WhaleOptions
+	Profiles     VolumeProfileOptions
+	Bus          BusOptions
+	Snapshots    SnapshotOptions
+
+	// How often the symbol universe is reloaded, 0 to never.
+	UniverseRefresh time.Duration
 }
 
 var static packr.Box
 
 func ServerMain(options Options) {
+	if err := ConfigureMetrics(options.Metrics); err != nil {
+		log.Fatalf("Invalid metrics configuration: %v", err)
+	}
+	if err := ConfigureTrustedProxies(options.Limits.TrustedProxies); err != nil {
+		log.Fatalf("Invalid limits configuration: %v", err)
+	}
+	wsQueueOptions = options.WsQueue
+	whaleOptions = options.Whales
+	volumeProfileOptions = options.Profiles
 
 	// Start the Binance runner. This is a little bit of a message as the
 	// socket can subscribe to specific symbol feeds directly. This should be
 	// abstracted with some sort of broker.
-	binanceRunner := NewBinanceRunner()
+	universe, err := binance.NewSymbolUniverse(options.SymbolFilter)
+	if err != nil {
+		log.Fatalf("Invalid symbol filter: %v", err)
+	}
+	if options.UniverseRefresh > 0 {
+		go universe.RefreshEvery(options.UniverseRefresh)
+	}
+	rollupStore, err := db.OpenRollupStore("binance-rollups")
+	if err != nil {
+		log.Fatalf("Failed to open rollup store: %v", err)
+	}
+	binanceRunner := NewBinanceRunner(universe, rollupStore)
 	go binanceRunner.Run()
 
-	wsMonitorSourceCache := NewWsSourceCache(binanceRunner.Subscribe(), WsBuildMonitorMessage)
+	wsMonitorSourceCache := NewWsSourceCache("monitor", binanceRunner.Subscribe(),
+		WsBuildMonitorMessage, WsBuildMonitorBinaryMessage, nil)
 	wsMonitorHandler := NewWebSocketHandler(binanceRunner, wsMonitorSourceCache)
 	go wsMonitorSourceCache.Run()
 
-	wsLiveSourceCache := NewWsSourceCache(binanceRunner.Subscribe(), WsBuildCompleteMessage)
+	wsLiveSourceCache := NewWsSourceCache("live", binanceRunner.Subscribe(),
+		WsBuildCompleteMessage, WsBuildTickerBinaryMessage, GrpcBuildTickers)
 	wsLiveHandler := NewWebSocketHandler(binanceRunner, wsLiveSourceCache)
 	go wsLiveSourceCache.Run()
 
+	wsAssetSourceCache := NewWsSourceCache("assets", binanceRunner.Subscribe(),
+		WsBuildAssetMessage, WsBuildAssetBinaryMessage, nil)
+	wsAssetHandler := NewWebSocketHandler(binanceRunner, wsAssetSourceCache)
+	go wsAssetSourceCache.Run()
+
 	binanceWebSocketHandler := NewWebSocketHandler(binanceRunner, nil)
 
-	router := mux.NewRouter()
+	eventStore, err := db.OpenEventStore("binance-events")
+	if err != nil {
+		log.Fatalf("Failed to open event store: %v", err)
+	}
+	eventFeed := NewEventFeed(eventFeedHistory)
+	eventWebSocketHandler := NewEventWebSocketHandler(eventFeed)
+	whaleWebSocketHandler := NewEventWebSocketHandler(binanceRunner.WhaleFeed)
+	pumpDetector := NewPumpDetector(options.Pump, eventStore, eventFeed)
+	go pumpDetector.Run(binanceRunner.Subscribe())
+
+	breadthTracker := NewBreadthTracker()
+	breadthWebSocketHandler := NewEventWebSocketHandler(breadthTracker.Feed)
+	go breadthTracker.Run(binanceRunner.Subscribe())
+
+	var snapshotStore *db.SnapshotStore
+	if options.Snapshots.Interval > 0 {
+		snapshotStore, err = db.OpenSnapshotStore("binance-snapshots", options.Snapshots.Retention)
+		if err != nil {
+			log.Fatalf("Failed to open snapshot store: %v", err)
+		}
+		go NewSnapshotter(options.Snapshots, snapshotStore).Run(binanceRunner.Subscribe())
+	}
 
-	router.HandleFunc("/ws/binance/live", wsLiveHandler.Handle)
-	router.HandleFunc("/ws/binance/monitor", wsMonitorHandler.Handle)
-	router.HandleFunc("/ws/binance/symbol", binanceWebSocketHandler.Handle)
+	wsMuxHandler := NewWsMuxHandler(binanceRunner,
+		wsLiveSourceCache, wsMonitorSourceCache, wsAssetSourceCache)
 
-	router.PathPrefix("/api/1/binance/proxy").Handler(binance.NewApiProxy())
+	router := mux.NewRouter()
+	limiter := NewLimiter(options.Limits)
+
+	router.Handle("/ws/binance", limiter.WebSocket(wsMuxHandler.Handle))
+	router.Handle("/ws/binance/live", limiter.WebSocket(wsLiveHandler.Handle))
+	router.Handle("/ws/binance/monitor", limiter.WebSocket(wsMonitorHandler.Handle))
+	router.Handle("/ws/binance/symbol", limiter.WebSocket(binanceWebSocketHandler.Handle))
+	router.Handle("/ws/binance/assets", limiter.WebSocket(wsAssetHandler.Handle))
+	router.Handle("/ws/binance/events", limiter.WebSocket(eventWebSocketHandler.Handle))
+	router.Handle("/ws/binance/whales", limiter.WebSocket(whaleWebSocketHandler.Handle))
+	router.Handle("/ws/binance/breadth", limiter.WebSocket(breadthWebSocketHandler.Handle))
+
+	// Every feed is also served as Server-Sent Events.
+	for _, source := range []*WsSourceCache{
+		wsLiveSourceCache, wsMonitorSourceCache, wsAssetSourceCache,
+	} {
+		router.Handle("/sse/binance/"+source.Name(),
+			limiter.WebSocket(NewSseHandler(source).Handle))
+	}
 
-	router.HandleFunc("/api/1/ping", pingHandler)
-	router.HandleFunc("/api/1/status/websockets", webSocketsStatusHandler)
+	apiProxy := binance.NewApiProxy(options.Proxy)
+	router.PathPrefix("/api/1/binance/proxy").Handler(limiter.Proxy(apiProxy))
+
+	router.Handle("/api/1/ping", limiter.RouteFunc("/api/1/ping", pingHandler))
+	router.Handle("/api/1/status/websockets",
+		limiter.RouteFunc("/api/1/status/websockets", webSocketsStatusHandler))
+	router.Handle("/api/1/status/proxy",
+		limiter.RouteFunc("/api/1/status/proxy", proxyStatusHandler(apiProxy)))
+	router.Handle("/api/1/openapi.json",
+		limiter.RouteFunc("/api/1/openapi.json", openApiHandler))
+	router.Handle("/api/1/schema/{feed}.json",
+		limiter.RouteFunc("/api/1/schema", schemaHandler))
+
+	router.Handle("/api/1/binance/volume",
+		limiter.Route("/api/1/binance/volume", NewVolumeHandler(binanceRunner)))
+	router.Handle("/api/1/binance/assets",
+		limiter.Route("/api/1/binance/assets", NewAssetHandler(binanceRunner)))
+	router.Handle("/api/1/binance/screener",
+		limiter.Route("/api/1/binance/screener", NewScreenerHandler(binanceRunner, snapshotStore)))
+	router.Handle("/api/1/binance/snapshots",
+		limiter.Route("/api/1/binance/snapshots", NewSnapshotListHandler(options.Snapshots, snapshotStore)))
+	router.Handle("/api/1/binance/snapshot",
+		limiter.Route("/api/1/binance/snapshot", NewSnapshotHandler(snapshotStore)))
+	router.Handle("/api/1/binance/symbol/{symbol}",
+		limiter.Route("/api/1/binance/symbol", NewSymbolHandler(binanceRunner)))
+	router.Handle("/api/1/binance/profile/{symbol}",
+		limiter.Route("/api/1/binance/profile", NewVolumeProfileHandler(binanceRunner)))
+	router.Handle("/api/1/binance/breadth",
+		limiter.RouteFunc("/api/1/binance/breadth", breadthHandler(breadthTracker)))
+	router.Handle("/api/1/binance/events",
+		limiter.Route("/api/1/binance/events", NewEventHandler(eventStore)))
+
+	if options.Bus.Sink.Type != "" {
+		sink, err := bus.Open(options.Bus.Sink)
+		if err != nil {
+			log.Fatalf("Failed to open message bus: %v", err)
+		}
+		NewBusPublisher(sink, options.Bus).Run(binanceRunner,
+			eventFeed, binanceRunner.WhaleFeed)
+	}
 
-	router.Handle("/api/1/binance/volume", NewVolumeHandler(binanceRunner))
+	if options.GrpcPort != 0 {
+		grpcServer := NewGrpcServer(binanceRunner, wsLiveSourceCache,
+			eventFeed, binanceRunner.WhaleFeed)
+		go func() {
+			log.Fatal(grpcServer.ListenAndServe(options.GrpcPort, limiter))
+		}()
+	}
 
 	static := packr.NewBox("../../webapp/dist")
 	staticServer := http.FileServer(static)
@@ -118,54 +243,90 @@ func NewVolumeHandler(binanceRunner *BinanceRunner) *VolumeHandler {
 
 func (h *VolumeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	lastTracker := h.binanceRunner.GetCache()
-	data := map[string]interface{}{}
+	response := VolumeResponse{
+		Data: map[string]*VolumeEntry{},
+	}
 	for symbol := range lastTracker.Trackers {
 		tracker := lastTracker.Trackers[symbol]
-		ticker := map[string]interface{}{}
-		ticker["nvh"] = lastTracker.Trackers[symbol].Histogram.NetVolume
-		ticker["bvh"] = lastTracker.Trackers[symbol].Histogram.BuyVolume
-		ticker["vh"] = lastTracker.Trackers[symbol].Histogram.Volume
-		ticker["vol"] = tracker.LastTick().TotalQuoteVolume
-		ticker["priceChange1h"] = tracker.Metrics[60].PriceChangePercent
-		ticker["nv60"] = tracker.Metrics[60].NetVolume
-		ticker["v60"] = tracker.Metrics[60].TotalVolume
-
-		ticker["v24h"] = tracker.Histogram.Volume24
-
-		if tracker.Metrics[60].TotalTrades > 0 {
-			ticker["t60pb"] = float64(tracker.Metrics[60].BuyTrades) / float64(tracker.Metrics[60].TotalTrades)
-		} else {
-			ticker["t60pb"] = float64(0)
+		ticker := &VolumeEntry{
+			Quote:                 tracker.QuoteAsset,
+			Volume:                tracker.LastTick().TotalQuoteVolume,
+			VolumeUSD:             tracker.H24Metrics.USD.Total,
+			VolumeBTC:             tracker.H24Metrics.BTC.Total,
+			NetVolumeHistogram:    tracker.Histogram.NetVolume,
+			NetVolumeHistogramUSD: tracker.Histogram.USD.NetVolume,
+			NetVolumeHistogramBTC: tracker.Histogram.BTC.NetVolume,
+			BuyVolumeHistogram:    tracker.Histogram.BuyVolume,
+			BuyVolumeHistogramUSD: tracker.Histogram.USD.BuyVolume,
+			BuyVolumeHistogramBTC: tracker.Histogram.BTC.BuyVolume,
+			VolumeHistogram:       tracker.Histogram.Volume,
+			VolumeHistogramUSD:    tracker.Histogram.USD.Volume,
+			VolumeHistogramBTC:    tracker.Histogram.BTC.Volume,
+			Volume24Histogram:     tracker.Histogram.Volume24,
+			Volume24HistogramUSD:  tracker.Histogram.USD.Volume24,
+			Volume24HistogramBTC:  tracker.Histogram.BTC.Volume24,
 		}
 
-		if math.IsNaN(tracker.Metrics[15].RSI) {
-			ticker["rsi15"] = 0
-		} else {
-			ticker["rsi15"] = tracker.Metrics[15].RSI
+		// The hour and 15 minute fields are only sent if those buckets
+		// are configured.
+		if metrics, ok := tracker.Metrics[60]; ok {
+			// Copied so the response does not point into the tracker.
+			hour := *metrics
+			buyRatio := float64(0)
+			if hour.TotalTrades > 0 {
+				buyRatio = float64(hour.BuyTrades) / float64(hour.TotalTrades)
+			}
+			ticker.PriceChangePercent1h = &hour.PriceChangePercent
+			ticker.NetVolume60 = &hour.NetVolume
+			ticker.NetVolume60USD = &hour.USD.Net
+			ticker.NetVolume60BTC = &hour.BTC.Net
+			ticker.Volume60 = &hour.TotalVolume
+			ticker.Volume60USD = &hour.USD.Total
+			ticker.Volume60BTC = &hour.BTC.Total
+			ticker.Trades60 = &hour.TotalTrades
+			ticker.BuyTradeRatio60 = &buyRatio
+		}
+		if metrics, ok := tracker.Metrics[15]; ok {
+			rsi := float64(0)
+			if metrics.RSIKnown {
+				rsi = metrics.RSI
+			}
+			ticker.RSI15 = &rsi
 		}
 
-		ticker["t60"] = tracker.Metrics[60].TotalTrades
-		data[symbol] = ticker
-	}
-	response := map[string]interface{}{
-		"data": data,
+		response.Data[symbol] = ticker
 	}
+	w.Header().Add("content-type", "application/json")
 	encoder := json.NewEncoder(w)
-	encoder.Encode(response)
+	if err := encoder.Encode(response); err != nil {
+		log.WithError(err).WithField("handler", "volume").
+			Errorf("Failed to encode response to JSON")
+	}
 }
 
 func pingHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("content-type", "application/json")
 	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(map[string]interface{}{
-		"version":     version.BuildNumberAsInt(),
-		"buildNumber": version.BuildNumberAsInt(),
+	if err := encoder.Encode(PingResponse{
+		Version:     version.BuildNumberAsInt(),
+		BuildNumber: version.BuildNumberAsInt(),
 	}); err != nil {
 		log.WithError(err).WithField("handler", "ping").
 			Errorf("Failed to encode response to JSON")
 	}
 }
 
+func proxyStatusHandler(proxy *binance.ApiProxy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("content-type", "application/json")
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(proxy.Status()); err != nil {
+			log.WithError(err).WithField("handler", "proxy-status").
+				Errorf("Failed to encode response to JSON")
+		}
+	}
+}
+
 func webSocketsStatusHandler(w http.ResponseWriter, r *http.Request) {
 	wsConnectionTracker.Lock.RLock()
 	defer wsConnectionTracker.Lock.RUnlock()
@@ -180,6 +341,9 @@ func webSocketsStatusHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	clients := make(map[string][]string)
+	connections := []WsConnectionStatus{}
+	dropped := wsConnectionTracker.Dropped
+	disconnected := wsConnectionTracker.Disconnected
 
 	for client := range wsConnectionTracker.Clients {
 
@@ -191,16 +355,43 @@ func webSocketsStatusHandler(w http.ResponseWriter, r *http.Request) {
 		hash.Write(salt)
 		remoteAddr := hex.EncodeToString(hash.Sum(nil))[0:8]
 
+		connection := WsConnectionStatus{
+			Id:           client.id,
+			Client:       remoteAddr,
+			Paths:        []string{},
+			Queued:       client.queue.Len(),
+			Dropped:      client.queue.Dropped(),
+			Disconnected: client.queue.Overflowed(),
+		}
 		for path := range wsConnectionTracker.Clients[client] {
 			clients[remoteAddr] = append(
 				clients[remoteAddr], path)
+			connection.Paths = append(connection.Paths, path)
+		}
+		sort.Strings(connection.Paths)
+		connections = append(connections, connection)
+
+		dropped += connection.Dropped
+		if connection.Disconnected {
+			disconnected++
 		}
 	}
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].Id < connections[j].Id
+	})
 
+	w.Header().Add("content-type", "application/json")
 	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(map[string]interface{}{
-		"paths":   paths,
-		"clients": clients,
+	if err := encoder.Encode(WebSocketsStatusResponse{
+		Paths:        paths,
+		Clients:      clients,
+		Connections:  connections,
+		Dropped:      dropped,
+		Disconnected: disconnected,
+		Queue: WsQueueStatus{
+			Depth:  wsQueueOptions.Depth,
+			Policy: wsQueueOptions.Policy.String(),
+		},
 	}); err != nil {
 		log.WithError(err).WithField("handler", "ws-status").
 			Errorf("Failed to encode response to JSON")
@@ -0,0 +1,210 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"gitlab.com/crankykernel/cryptoxscanner/binance"
+	"math"
+	"time"
+)
+
+// The symbol the market metrics compare against, besides the market
+// average.
+const marketBenchmark = "BTCUSDT"
+
+// Minutes of 1 minute returns the market metrics are calculated from.
+const marketHistory = 60
+
+// Minimum number of returns for a beta or correlation.
+const marketMinSamples = 10
+
+// minuteReturns returns the returns of the last completed minutes, index
+// 0 being the most recent, from the 1 minute aggregates. Minutes after the
+// last trade have a return of 0, minutes before the first are NaN.
+func (t *TickerTracker) minuteReturns(now time.Time, minutes int) []float64 {
+	returns := make([]float64, minutes)
+	aggs := t.Aggs[1]
+	current := now.Truncate(time.Minute)
+	closeAt := func(k int) float64 {
+		if len(aggs) == 0 {
+			return math.NaN()
+		}
+		openTime := current.Add(-time.Duration(k) * time.Minute)
+		last := aggs[len(aggs)-1]
+		if openTime.After(last.Time) {
+			return last.Close
+		}
+		i := int(openTime.Sub(aggs[0].Time) / time.Minute)
+		if i < 0 || i >= len(aggs) || !aggs[i].Time.Equal(openTime) {
+			return math.NaN()
+		}
+		return aggs[i].Close
+	}
+	for k := 1; k <= minutes; k++ {
+		previous := closeAt(k + 1)
+		if previous > 0 {
+			returns[k-1] = closeAt(k)/previous - 1
+		} else {
+			returns[k-1] = math.NaN()
+		}
+	}
+	return returns
+}
+
+// usdMinuteReturns returns the minute returns of a tracker in USD using
+// the USDT market of its quote asset, nil if the quote asset has none.
+func usdMinuteReturns(trackers *TickerTrackerMap, tracker *TickerTracker,
+	quoteReturns map[string][]float64, now time.Time) []float64 {
+	returns := tracker.minuteReturns(now, marketHistory)
+	if binance.IsUsdAsset(tracker.QuoteAsset) {
+		return returns
+	}
+	quote, ok := quoteReturns[tracker.QuoteAsset]
+	if !ok {
+		quoteTracker, exists := trackers.Trackers[tracker.QuoteAsset+"USDT"]
+		if exists {
+			quote = quoteTracker.minuteReturns(now, marketHistory)
+		}
+		quoteReturns[tracker.QuoteAsset] = quote
+	}
+	if quote == nil {
+		return nil
+	}
+	for i := range returns {
+		returns[i] = (1+returns[i])*(1+quote[i]) - 1
+	}
+	return returns
+}
+
+// regress returns the beta and correlation of returns against benchmark
+// over the values where both are known.
+func regress(returns []float64, benchmark []float64) (float64, float64) {
+	n := 0.0
+	sumX, sumY, sumXX, sumYY, sumXY := 0.0, 0.0, 0.0, 0.0, 0.0
+	for i := range returns {
+		x, y := benchmark[i], returns[i]
+		if math.IsNaN(x) || math.IsNaN(y) {
+			continue
+		}
+		n++
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumYY += y * y
+		sumXY += x * y
+	}
+	if n < marketMinSamples {
+		return math.NaN(), math.NaN()
+	}
+	covariance := sumXY/n - (sumX/n)*(sumY/n)
+	varianceX := sumXX/n - (sumX/n)*(sumX/n)
+	varianceY := sumYY/n - (sumY/n)*(sumY/n)
+	if varianceX <= 0 {
+		return math.NaN(), math.NaN()
+	}
+	beta := covariance / varianceX
+	if varianceY <= 0 {
+		return Round3(beta), math.NaN()
+	}
+	return Round3(beta), Round3(covariance / math.Sqrt(varianceX*varianceY))
+}
+
+// relativeStrength returns the compounded return of returns less that of
+// benchmark, in percent, over the values where both are known.
+func relativeStrength(returns []float64, benchmark []float64) float64 {
+	growth := 1.0
+	benchmarkGrowth := 1.0
+	n := 0
+	for i := range returns {
+		if math.IsNaN(returns[i]) || math.IsNaN(benchmark[i]) {
+			continue
+		}
+		growth *= 1 + returns[i]
+		benchmarkGrowth *= 1 + benchmark[i]
+		n++
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return Round3((growth/benchmarkGrowth - 1) * 100)
+}
+
+// CalculateMarketMetrics sets the beta, correlation and relative strength
+// of each tracker against BTCUSDT and the equally weighted average of all
+// symbols, from the USD returns of the last completed minutes. Each bucket
+// uses the returns of its own number of minutes, so the beta and
+// correlation are only available for the buckets of at least
+// marketMinSamples minutes.
+func CalculateMarketMetrics(trackers *TickerTrackerMap, now time.Time) {
+	quoteReturns := map[string][]float64{}
+	returns := map[string][]float64{}
+	for symbol, tracker := range trackers.Trackers {
+		if r := usdMinuteReturns(trackers, tracker, quoteReturns, now); r != nil {
+			returns[symbol] = r
+		}
+	}
+
+	market := make([]float64, marketHistory)
+	for i := range market {
+		sum := 0.0
+		count := 0
+		for _, r := range returns {
+			if !math.IsNaN(r[i]) {
+				sum += r[i]
+				count++
+			}
+		}
+		if count > 0 {
+			market[i] = sum / float64(count)
+		} else {
+			market[i] = math.NaN()
+		}
+	}
+	btc := returns[marketBenchmark]
+
+	for symbol, tracker := range trackers.Trackers {
+		r := returns[symbol]
+		tracker.lock.Lock()
+		for _, bucket := range Buckets {
+			metrics := tracker.Metrics[bucket]
+			metrics.BetaBTC = math.NaN()
+			metrics.CorrelationBTC = math.NaN()
+			metrics.RelativeStrengthBTC = math.NaN()
+			metrics.BetaMarket = math.NaN()
+			metrics.CorrelationMarket = math.NaN()
+			metrics.RelativeStrengthMarket = math.NaN()
+			if r == nil || bucket > marketHistory {
+				continue
+			}
+			if btc != nil {
+				metrics.BetaBTC, metrics.CorrelationBTC = regress(r[:bucket], btc[:bucket])
+				metrics.RelativeStrengthBTC = relativeStrength(r[:bucket], btc[:bucket])
+			}
+			metrics.BetaMarket, metrics.CorrelationMarket = regress(r[:bucket], market[:bucket])
+			metrics.RelativeStrengthMarket = relativeStrength(r[:bucket], market[:bucket])
+		}
+		tracker.lock.Unlock()
+	}
+}
@@ -0,0 +1,161 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// sameFloat returns true if a and b are within 1e-9 or both NaN.
+func sameFloat(a float64, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestMinuteReturns(t *testing.T) {
+	now := time.Date(2019, 2, 15, 12, 0, 30, 0, time.UTC)
+	aggs := func(closes ...float64) []Aggregate {
+		start := now.Truncate(time.Minute).Add(-time.Duration(len(closes)+1) * time.Minute)
+		aggs := []Aggregate{}
+		for i, close := range closes {
+			aggs = append(aggs, Aggregate{Time: start.Add(time.Duration(i) * time.Minute), Close: close})
+		}
+		return aggs
+	}
+	nan := math.NaN()
+	tests := []struct {
+		name     string
+		aggs     []Aggregate
+		expected []float64
+	}{
+		// The minute after the last aggregate had no trades, and the
+		// returns before the first are unknown.
+		{"returns", aggs(100, 110, 99), []float64{0, 99.0/110 - 1, 0.1, nan, nan}},
+		{"no aggregates", nil, []float64{nan, nan, nan, nan, nan}},
+	}
+	for _, test := range tests {
+		tracker := NewTickerTracker("ETHUSDT")
+		tracker.Aggs[1] = test.aggs
+		returns := tracker.minuteReturns(now, len(test.expected))
+		for i := range test.expected {
+			if !sameFloat(returns[i], test.expected[i]) {
+				t.Errorf("%s: expected %v, got %v", test.name, test.expected, returns)
+				break
+			}
+		}
+	}
+}
+
+func TestUsdMinuteReturns(t *testing.T) {
+	now := time.Date(2019, 2, 15, 12, 0, 30, 0, time.UTC)
+	trackers := NewTickerTrackerMap()
+	market := func(symbol string, quote string, closes ...float64) *TickerTracker {
+		tracker := trackers.GetTracker(symbol)
+		tracker.QuoteAsset = quote
+		start := now.Truncate(time.Minute).Add(-time.Duration(len(closes)) * time.Minute)
+		for i, close := range closes {
+			tracker.Aggs[1] = append(tracker.Aggs[1], Aggregate{
+				Time: start.Add(time.Duration(i) * time.Minute), Close: close})
+		}
+		return tracker
+	}
+	market("BTCUSDT", "USDT", 100, 110)
+	eth := market("ETHBTC", "BTC", 0.05, 0.055)
+	xyz := market("XYZTRY", "TRY", 10, 11)
+
+	returns := usdMinuteReturns(trackers, eth, map[string][]float64{}, now)
+	if !sameFloat(returns[0], 0.21) {
+		t.Errorf("expected 21%% from both the pair and the quote, got %v", returns[0])
+	}
+	if returns := usdMinuteReturns(trackers, xyz, map[string][]float64{}, now); returns != nil {
+		t.Errorf("expected no returns without a USDT market for the quote, got %v", returns)
+	}
+}
+
+func TestRegress(t *testing.T) {
+	benchmark := []float64{0.01, -0.02, 0.03, 0.005, -0.01, 0.02, -0.015, 0.0, 0.025, -0.005, 0.01, -0.03}
+	line := func(slope float64, offset float64) []float64 {
+		returns := make([]float64, len(benchmark))
+		for i, x := range benchmark {
+			returns[i] = slope*x + offset
+		}
+		return returns
+	}
+	nan := math.NaN()
+	withNaN := line(2, 0)
+	withNaN[0], withNaN[5] = nan, nan
+	tests := []struct {
+		name        string
+		returns     []float64
+		benchmark   []float64
+		beta        float64
+		correlation float64
+	}{
+		{"double", line(2, 0), benchmark, 2, 1},
+		{"inverse half", line(-0.5, 0.001), benchmark, -0.5, -1},
+		{"unknown returns skipped", withNaN, benchmark, 2, 1},
+		{"too few", line(2, 0)[:9], benchmark[:9], nan, nan},
+		{"flat benchmark", line(2, 0), make([]float64, len(benchmark)), nan, nan},
+		{"no price moves", line(0, 0), benchmark, 0, nan},
+		{
+			"uncorrelated",
+			[]float64{1, 1, -1, -1, 1, 1, -1, -1, 1, 1, -1, -1},
+			[]float64{1, -1, 1, -1, 1, -1, 1, -1, 1, -1, 1, -1},
+			0, 0,
+		},
+	}
+	for _, test := range tests {
+		beta, correlation := regress(test.returns, test.benchmark)
+		if !sameFloat(beta, test.beta) || !sameFloat(correlation, test.correlation) {
+			t.Errorf("%s: expected beta %v and correlation %v, got %v and %v",
+				test.name, test.beta, test.correlation, beta, correlation)
+		}
+	}
+}
+
+func TestRelativeStrength(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name      string
+		returns   []float64
+		benchmark []float64
+		expected  float64
+	}{
+		{"compounded", []float64{0.1, 0.1}, []float64{0.1, 0}, 10},
+		{"same", []float64{0.1, -0.05}, []float64{0.1, -0.05}, 0},
+		{"weaker", []float64{-0.5}, []float64{1}, -75},
+		{"unknown skipped", []float64{0.1, nan}, []float64{0, 0.5}, 10},
+		{"none known", []float64{nan, 0.1}, []float64{0.1, nan}, nan},
+	}
+	for _, test := range tests {
+		if strength := relativeStrength(test.returns, test.benchmark); !sameFloat(strength, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, strength)
+		}
+	}
+}
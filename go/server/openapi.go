@@ -0,0 +1,384 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"gitlab.com/crankykernel/cryptoxscanner/binance"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// The OpenAPI 3 document of the REST API is served at
+// /api/1/openapi.json. The response schemas are generated from the
+// response types in api.go, and the live feed entry from the same types as
+// the feed schemas, so they can't drift from what the handlers send.
+
+type openApiParameter struct {
+	Name        string
+	In          string
+	Type        string
+	Description string
+	Required    bool
+}
+
+type openApiPath struct {
+	Path        string
+	OperationId string
+	Summary     string
+	Parameters  []openApiParameter
+	Schema      string
+	ContentType string
+
+	// Error responses other than rate limiting, by status.
+	Errors map[int]string
+}
+
+var openApiSchemaTypes = map[string]reflect.Type{
+	"Ping":             reflect.TypeOf(PingResponse{}),
+	"WebSocketsStatus": reflect.TypeOf(WebSocketsStatusResponse{}),
+	"ProxyStatus":      reflect.TypeOf(binance.ProxyStatus{}),
+	"Volume":           reflect.TypeOf(VolumeResponse{}),
+	"VolumeProfiles":   reflect.TypeOf(VolumeProfileResponse{}),
+	"Breadth":          reflect.TypeOf(BreadthResponse{}),
+	"Events":           reflect.TypeOf(EventsResponse{}),
+	"Screener":         reflect.TypeOf(ScreenerResponse{}),
+	"SnapshotList":     reflect.TypeOf(SnapshotListResponse{}),
+	"Snapshot":         reflect.TypeOf(MetricSnapshot{}),
+}
+
+func queryParameter(name string, typ string, description string) openApiParameter {
+	return openApiParameter{
+		Name:        name,
+		In:          "query",
+		Type:        typ,
+		Description: description,
+	}
+}
+
+func pathParameter(name string, description string) openApiParameter {
+	return openApiParameter{
+		Name:        name,
+		In:          "path",
+		Type:        "string",
+		Description: description,
+		Required:    true,
+	}
+}
+
+var openApiPaths = []openApiPath{
+	{
+		Path:        "/api/1/ping",
+		OperationId: "getPing",
+		Summary:     "Server version.",
+		Schema:      "Ping",
+	},
+	{
+		Path:        "/api/1/status/websockets",
+		OperationId: "getWebSocketsStatus",
+		Summary:     "Connected WebSocket clients.",
+		Schema:      "WebSocketsStatus",
+	},
+	{
+		Path:        "/api/1/status/proxy",
+		OperationId: "getProxyStatus",
+		Summary:     "State of the Binance API proxy.",
+		Schema:      "ProxyStatus",
+	},
+	{
+		Path:        "/api/1/schema/{feed}.json",
+		OperationId: "getFeedSchema",
+		Summary:     "JSON Schema of the messages of a feed.",
+		Parameters: []openApiParameter{
+			pathParameter("feed", "live or monitor."),
+		},
+		ContentType: "application/schema+json",
+		Errors: map[int]string{
+			404: "Not found.",
+		},
+	},
+	{
+		Path:        "/api/1/openapi.json",
+		OperationId: "getOpenApi",
+		Summary:     "This document.",
+	},
+	{
+		Path:        "/api/1/binance/volume",
+		OperationId: "getVolume",
+		Summary:     "Volume summary of all symbols.",
+		Schema:      "Volume",
+	},
+	{
+		Path:        "/api/1/binance/assets",
+		OperationId: "getAssets",
+		Summary:     "Metrics aggregated by base asset.",
+		Parameters: []openApiParameter{
+			queryParameter("asset", "string", "Comma separated assets, default all."),
+		},
+		Schema: "Assets",
+	},
+	{
+		Path:        "/api/1/binance/screener",
+		OperationId: "getScreener",
+		Summary:     "Live feed entries ranked by a metric.",
+		Parameters: []openApiParameter{
+			queryParameter("sort", "string", "Metric to rank by, default volume."),
+			queryParameter("order", "string", "desc (default) or asc."),
+			queryParameter("limit", "integer", "Number of entries, default 50, at most 1000."),
+			queryParameter("offset", "integer", "Number of ranked entries to skip."),
+			queryParameter("quote", "string", "Comma separated quote assets."),
+			queryParameter("min_volume", "number", "Minimum 24 hour volume in the quote asset."),
+			queryParameter("filter", "string", "Metric conditions such as nv_15>1000, comma separated or repeated."),
+			queryParameter("fields", "string", "Comma separated metrics to include."),
+			queryParameter("at", "string", "Screen the most recent snapshot taken at or before this time, unix seconds or RFC 3339."),
+		},
+		Schema: "Screener",
+		Errors: map[int]string{
+			400: "Invalid parameters.",
+			404: "No snapshot at or before at.",
+		},
+	},
+	{
+		Path:        "/api/1/binance/snapshots",
+		OperationId: "getSnapshots",
+		Summary:     "Times of the stored metric snapshots.",
+		Parameters: []openApiParameter{
+			queryParameter("since", "string", "Unix seconds or RFC 3339, default 24 hours ago."),
+			queryParameter("until", "string", "Unix seconds or RFC 3339, default now."),
+		},
+		Schema: "SnapshotList",
+		Errors: map[int]string{
+			400: "Invalid parameters.",
+		},
+	},
+	{
+		Path:        "/api/1/binance/snapshot",
+		OperationId: "getSnapshot",
+		Summary:     "The live feed entries of every symbol as stored in a snapshot.",
+		Parameters: []openApiParameter{
+			{
+				Name:        "at",
+				In:          "query",
+				Type:        "string",
+				Description: "The most recent snapshot taken at or before this time is returned, unix seconds or RFC 3339.",
+				Required:    true,
+			},
+			queryParameter("fields", "string", "Comma separated metrics to include."),
+		},
+		Schema: "Snapshot",
+		Errors: map[int]string{
+			400: "Invalid parameters.",
+			404: "No snapshot at or before at.",
+		},
+	},
+	{
+		Path:        "/api/1/binance/symbol/{symbol}",
+		OperationId: "getSymbol",
+		Summary:     "State of one symbol.",
+		Parameters: []openApiParameter{
+			pathParameter("symbol", "Symbol, for example ETHBTC."),
+			queryParameter("include", "string", "Comma separated sections: metrics, histograms, ticks, candles and trades. Default all."),
+			queryParameter("fields", "string", "Comma separated metrics to include."),
+			queryParameter("ticks", "integer", "Number of the most recent ticks, default 60."),
+			queryParameter("candles", "integer", "Number of the most recent candles of each bucket, default 60."),
+			queryParameter("trades", "integer", "Number of the most recent trades, default 60."),
+		},
+		Schema: "Symbol",
+		Errors: map[int]string{
+			400: "Invalid parameters.",
+			404: "Not found.",
+		},
+	},
+	{
+		Path:        "/api/1/binance/profile/{symbol}",
+		OperationId: "getVolumeProfile",
+		Summary:     "Volume profiles of one symbol.",
+		Parameters: []openApiParameter{
+			pathParameter("symbol", "Symbol, for example ETHBTC."),
+			queryParameter("window", "integer", "Window in minutes, default the configured windows."),
+			queryParameter("bins", "integer", "Number of price levels."),
+		},
+		Schema: "VolumeProfiles",
+		Errors: map[int]string{
+			400: "Invalid parameters.",
+			404: "Not found.",
+		},
+	},
+	{
+		Path:        "/api/1/binance/breadth",
+		OperationId: "getBreadth",
+		Summary:     "Market breadth.",
+		Parameters: []openApiParameter{
+			queryParameter("history", "boolean", "Include the recent history."),
+		},
+		Schema: "Breadth",
+	},
+	{
+		Path:        "/api/1/binance/events",
+		OperationId: "getEvents",
+		Summary:     "Stored events such as pumps.",
+		Parameters: []openApiParameter{
+			queryParameter("type", "string", "Event type, for example pump."),
+			queryParameter("symbol", "string", "Symbol, for example ETHBTC."),
+			queryParameter("since", "integer", "Unix time in seconds, default 24 hours ago."),
+			queryParameter("limit", "integer", "Number of events, default 100, at most 1000."),
+		},
+		Schema: "Events",
+	},
+	{
+		Path:        "/api/1/binance/proxy/{path}",
+		OperationId: "getProxy",
+		Summary:     "Cached proxy to the allowed public endpoints of the Binance API, responses are passed through.",
+		Parameters: []openApiParameter{
+			pathParameter("path", "Binance API path, for example api/v3/depth."),
+		},
+		Errors: map[int]string{
+			403: "Endpoint not allowed.",
+			502: "Binance API unavailable.",
+			504: "Binance API timed out.",
+		},
+	},
+}
+
+func openApiRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func openApiSchemas() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	for name, t := range openApiSchemaTypes {
+		schemas[name] = schemaType(t)
+	}
+	schemas["Entry"] = EntrySchema(reflect.TypeOf(CompleteEntry{}))
+
+	// The screener, snapshot and symbol responses hold entries as maps, which
+	// reflection can't describe.
+	screener := schemas["Screener"].(map[string]interface{})
+	screener["properties"].(map[string]interface{})["results"] = map[string]interface{}{
+		"type":  "array",
+		"items": openApiRef("Entry"),
+	}
+	snapshot := schemas["Snapshot"].(map[string]interface{})
+	snapshot["properties"].(map[string]interface{})["tickers"] = map[string]interface{}{
+		"type":  "array",
+		"items": openApiRef("Entry"),
+	}
+	object := map[string]interface{}{"type": "object"}
+	array := map[string]interface{}{"type": "array", "items": object}
+	schemas["Symbol"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"symbol":     map[string]interface{}{"type": "string"},
+			"base":       map[string]interface{}{"type": "string"},
+			"quote":      map[string]interface{}{"type": "string"},
+			"metrics":    openApiRef("Entry"),
+			"histograms": object,
+			"ticks":      array,
+			"candles":    map[string]interface{}{"type": "object", "additionalProperties": array},
+			"trades":     array,
+		},
+		"required": []string{"symbol", "base", "quote"},
+	}
+	schemas["Assets"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data": map[string]interface{}{
+				"type":                 "object",
+				"description":          "Keyed by asset.",
+				"additionalProperties": object,
+			},
+		},
+		"required": []string{"data"},
+	}
+	return schemas
+}
+
+// OpenApiSpec returns the OpenAPI 3 document of the REST API.
+func OpenApiSpec() map[string]interface{} {
+	paths := map[string]interface{}{}
+	for _, path := range openApiPaths {
+		parameters := []interface{}{}
+		for _, parameter := range path.Parameters {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        parameter.Name,
+				"in":          parameter.In,
+				"description": parameter.Description,
+				"required":    parameter.Required,
+				"schema":      map[string]interface{}{"type": parameter.Type},
+			})
+		}
+		contentType := path.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		schema := map[string]interface{}{}
+		if path.Schema != "" {
+			schema = openApiRef(path.Schema)
+		}
+		responses := map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "OK",
+				"content": map[string]interface{}{
+					contentType: map[string]interface{}{"schema": schema},
+				},
+			},
+			"429": map[string]interface{}{"description": "Rate limited."},
+		}
+		for status, description := range path.Errors {
+			responses[strconv.Itoa(status)] = map[string]interface{}{"description": description}
+		}
+		paths[path.Path] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": path.OperationId,
+				"summary":     path.Summary,
+				"parameters":  parameters,
+				"responses":   responses,
+			},
+		}
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "cryptoxscanner",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": openApiSchemas(),
+		},
+	}
+}
+
+func openApiHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("content-type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(OpenApiSpec()); err != nil {
+		log.WithError(err).WithField("handler", "openapi").
+			Errorf("Failed to encode response to JSON")
+	}
+}
@@ -0,0 +1,297 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"gitlab.com/crankykernel/cryptoxscanner/binance"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validateSchema checks a value decoded from JSON against a schema of the
+// OpenAPI document, returning the violations. Only the keywords the
+// document uses are supported, others are reported so they can't be
+// silently ignored.
+func validateSchema(spec map[string]interface{}, schema map[string]interface{},
+	value interface{}, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		components := spec["components"].(map[string]interface{})
+		resolved, ok := components["schemas"].(map[string]interface{})[name].(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: unresolved $ref %s", path, ref)}
+		}
+		return validateSchema(spec, resolved, value, path)
+	}
+	for keyword := range schema {
+		switch keyword {
+		case "type", "format", "nullable", "properties", "required", "items",
+			"additionalProperties", "description":
+		default:
+			return []string{fmt.Sprintf("%s: unsupported keyword %s", path, keyword)}
+		}
+	}
+
+	if value == nil {
+		if schema["nullable"] == true || schema["type"] == nil {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null is not nullable", path)}
+	}
+
+	errors := []string{}
+	switch schema["type"] {
+	case nil:
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object, got %T", path, value)}
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := object[name.(string)]; !ok {
+					errors = append(errors, fmt.Sprintf("%s: missing %s", path, name))
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range object {
+			if propertySchema, ok := properties[name].(map[string]interface{}); ok {
+				errors = append(errors, validateSchema(spec, propertySchema, property, path+"."+name)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				errors = append(errors, validateSchema(spec, additional, property, path+"."+name)...)
+			case bool:
+				if !additional {
+					errors = append(errors, fmt.Sprintf("%s: unexpected property %s", path, name))
+				}
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array, got %T", path, value)}
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range array {
+				errors = append(errors, validateSchema(spec, items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected string, got %T", path, value)}
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				errors = append(errors, fmt.Sprintf("%s: invalid date-time %q", path, s))
+			}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{fmt.Sprintf("%s: expected number, got %T", path, value)}
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return []string{fmt.Sprintf("%s: expected integer, got %v", path, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected boolean, got %T", path, value)}
+		}
+	default:
+		return []string{fmt.Sprintf("%s: unsupported type %v", path, schema["type"])}
+	}
+	return errors
+}
+
+// newContractRouter routes the documented paths to their handlers, backed
+// by test trackers and stores in a temporary directory.
+func newContractRouter(t *testing.T, now time.Time) *mux.Router {
+	t.Chdir(t.TempDir())
+
+	trackers, _ := newTestTrackers(now)
+	runner := NewBinanceRunner(nil, nil)
+	runner.trackers = trackers
+	runner.Cached.Trackers = trackers.Trackers
+
+	snapshotStore, err := db.OpenSnapshotStore("snapshots", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewSnapshotter(DefaultSnapshotOptions, snapshotStore).save(NewMetricSnapshot(trackers, now))
+
+	eventStore, err := db.OpenEventStore("events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pump, _ := json.Marshal(&PumpEvent{Id: "1", Symbol: "ETHBTC", Stage: "start", Start: now})
+	if err := eventStore.Save("1", "pump", "ETHBTC", now, pump); err != nil {
+		t.Fatal(err)
+	}
+
+	breadthTracker := NewBreadthTracker()
+	breadthTracker.Feed.Publish("breadth", CalculateBreadth(trackers, now))
+
+	apiProxy := binance.NewApiProxy(binance.DefaultProxyOptions)
+
+	router := mux.NewRouter()
+	router.PathPrefix("/api/1/binance/proxy").Handler(apiProxy)
+	router.HandleFunc("/api/1/ping", pingHandler)
+	router.HandleFunc("/api/1/status/websockets", webSocketsStatusHandler)
+	router.HandleFunc("/api/1/status/proxy", proxyStatusHandler(apiProxy))
+	router.HandleFunc("/api/1/openapi.json", openApiHandler)
+	router.HandleFunc("/api/1/schema/{feed}.json", schemaHandler)
+	router.Handle("/api/1/binance/volume", NewVolumeHandler(runner))
+	router.Handle("/api/1/binance/assets", NewAssetHandler(runner))
+	router.Handle("/api/1/binance/screener", NewScreenerHandler(runner, snapshotStore))
+	router.Handle("/api/1/binance/snapshots", NewSnapshotListHandler(DefaultSnapshotOptions, snapshotStore))
+	router.Handle("/api/1/binance/snapshot", NewSnapshotHandler(snapshotStore))
+	router.Handle("/api/1/binance/symbol/{symbol}", NewSymbolHandler(runner))
+	router.Handle("/api/1/binance/profile/{symbol}", NewVolumeProfileHandler(runner))
+	router.HandleFunc("/api/1/binance/breadth", breadthHandler(breadthTracker))
+	router.Handle("/api/1/binance/events", NewEventHandler(eventStore))
+	return router
+}
+
+// TestOpenApiContract requests each documented path and checks that the
+// status is documented and that successful responses match the schema of
+// the document served at /api/1/openapi.json.
+func TestOpenApiContract(t *testing.T) {
+	now := time.Now()
+	router := newContractRouter(t, now)
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest("GET", "/api/1/openapi.json", nil))
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal(response.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v", err)
+	}
+	paths := spec["paths"].(map[string]interface{})
+
+	at := strconv.FormatInt(now.Unix(), 10)
+	requests := []struct {
+		path   string
+		url    string
+		status int
+	}{
+		{"/api/1/ping", "/api/1/ping", 200},
+		{"/api/1/status/websockets", "/api/1/status/websockets", 200},
+		{"/api/1/status/proxy", "/api/1/status/proxy", 200},
+		{"/api/1/schema/{feed}.json", "/api/1/schema/live.json", 200},
+		{"/api/1/schema/{feed}.json", "/api/1/schema/monitor.json", 200},
+		{"/api/1/schema/{feed}.json", "/api/1/schema/other.json", 404},
+		{"/api/1/openapi.json", "/api/1/openapi.json", 200},
+		{"/api/1/binance/volume", "/api/1/binance/volume", 200},
+		{"/api/1/binance/assets", "/api/1/binance/assets", 200},
+		{"/api/1/binance/assets", "/api/1/binance/assets?asset=ETH", 200},
+		{"/api/1/binance/screener", "/api/1/binance/screener", 200},
+		{"/api/1/binance/screener", "/api/1/binance/screener?quote=BTC&sort=nv_15&filter=volume>0", 200},
+		{"/api/1/binance/screener", "/api/1/binance/screener?at=" + at, 200},
+		{"/api/1/binance/screener", "/api/1/binance/screener?at=1", 404},
+		{"/api/1/binance/screener", "/api/1/binance/screener?order=up", 400},
+		{"/api/1/binance/snapshots", "/api/1/binance/snapshots", 200},
+		{"/api/1/binance/snapshots", "/api/1/binance/snapshots?since=yesterday", 400},
+		{"/api/1/binance/snapshot", "/api/1/binance/snapshot?at=" + at, 200},
+		{"/api/1/binance/snapshot", "/api/1/binance/snapshot", 400},
+		{"/api/1/binance/snapshot", "/api/1/binance/snapshot?at=1", 404},
+		{"/api/1/binance/symbol/{symbol}", "/api/1/binance/symbol/ETHBTC", 200},
+		{"/api/1/binance/symbol/{symbol}", "/api/1/binance/symbol/ETHBTC?ticks=1001", 400},
+		{"/api/1/binance/symbol/{symbol}", "/api/1/binance/symbol/XRPBTC", 404},
+		{"/api/1/binance/profile/{symbol}", "/api/1/binance/profile/ETHBTC", 200},
+		{"/api/1/binance/profile/{symbol}", "/api/1/binance/profile/ETHBTC?bins=0", 400},
+		{"/api/1/binance/profile/{symbol}", "/api/1/binance/profile/XRPBTC", 404},
+		{"/api/1/binance/breadth", "/api/1/binance/breadth?history=true", 200},
+		{"/api/1/binance/events", "/api/1/binance/events", 200},
+		{"/api/1/binance/proxy/{path}", "/api/1/binance/proxy/api/v3/account", 403},
+	}
+
+	covered := map[string]bool{}
+	for _, request := range requests {
+		covered[request.path] = true
+		operation, ok := paths[request.path].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s is not documented", request.path)
+			continue
+		}
+		responses := operation["get"].(map[string]interface{})["responses"].(map[string]interface{})
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest("GET", request.url, nil))
+		if response.Code != request.status {
+			t.Errorf("%s: expected status %d, got %d: %s", request.url,
+				request.status, response.Code, response.Body.String())
+			continue
+		}
+		documented, ok := responses[strconv.Itoa(response.Code)].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: status %d is not documented", request.url, response.Code)
+			continue
+		}
+		if response.Code != http.StatusOK {
+			continue
+		}
+
+		content := documented["content"].(map[string]interface{})
+		contentType := response.Header().Get("content-type")
+		media, ok := content[contentType].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: content type %s is not documented", request.url, contentType)
+			continue
+		}
+		var body interface{}
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Errorf("%s: invalid JSON: %v", request.url, err)
+			continue
+		}
+		errors := validateSchema(spec, media["schema"].(map[string]interface{}), body, "response")
+		sort.Strings(errors)
+		for i, err := range errors {
+			if i == 10 {
+				t.Errorf("%s: %d more", request.url, len(errors)-i)
+				break
+			}
+			t.Errorf("%s: %s", request.url, err)
+		}
+	}
+
+	for path := range paths {
+		if !covered[path] {
+			t.Errorf("%s is documented but not requested", path)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"math"
+	"time"
+)
+
+// The bucket, in minutes, the pump score is calculated over.
+const pumpBucket = 1
+
+// Anomaly score at which a component of the pump score is saturated.
+const pumpZScoreScale = 6
+
+// Price acceleration, in percent per minute, at which its component is
+// saturated.
+const pumpAccelerationScale = 1
+
+// PumpScore rates how much the recent activity of a symbol looks like a
+// pump, from 0 to 100. The components are each scaled to 0..1; missing
+// components count as 0.
+type PumpScore struct {
+	Score float64 `json:"score" msgpack:"score"`
+
+	// Anomaly score of the price rise.
+	Price float64 `json:"price" msgpack:"price"`
+
+	// Change of the last minute less the change of the minute before.
+	Acceleration float64 `json:"acceleration" msgpack:"acceleration"`
+
+	// Share of buy trades above one half.
+	BuyRatio float64 `json:"buy_ratio" msgpack:"buy_ratio"`
+
+	// Anomaly score of the volume weighted by the share of net buying.
+	NetVolume float64 `json:"net_volume" msgpack:"net_volume"`
+
+	// Anomaly score of the number of trades.
+	Trades float64 `json:"trades" msgpack:"trades"`
+}
+
+func clamp01(value float64) float64 {
+	if math.IsNaN(value) || value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
+
+// CalculatePumpScore sets the pump score from the metrics of the pump
+// bucket. It must be called after CalculateAnomalies.
+func (t *TickerTracker) CalculatePumpScore() {
+	metrics := t.Metrics[pumpBucket]
+	score := PumpScore{}
+
+	if metrics.PriceChangePercent > 0 {
+		score.Price = clamp01(metrics.PriceZScore / pumpZScoreScale)
+	}
+
+	// The change over two buckets less the change over one is roughly the
+	// change of the preceding bucket.
+	previous := t.Metrics[pumpBucket*2]
+	if previous != nil {
+		change := metrics.PriceChangePercent
+		acceleration := change - (previous.PriceChangePercent - change)
+		score.Acceleration = clamp01(acceleration / pumpAccelerationScale)
+	}
+
+	if metrics.TotalTrades > 0 {
+		ratio := float64(metrics.BuyTrades) / float64(metrics.TotalTrades)
+		score.BuyRatio = clamp01((ratio - 0.5) / 0.4)
+	}
+
+	if metrics.TotalVolume > 0 {
+		share := metrics.NetVolume / metrics.TotalVolume
+		score.NetVolume = clamp01(metrics.VolumeZScore / pumpZScoreScale * share)
+	}
+
+	score.Trades = clamp01(metrics.TradeRateZScore / pumpZScoreScale)
+
+	score.Score = Round3(100 * (0.3*score.Price +
+		0.15*score.Acceleration +
+		0.15*score.BuyRatio +
+		0.2*score.NetVolume +
+		0.2*score.Trades))
+	score.Price = Round3(score.Price)
+	score.Acceleration = Round3(score.Acceleration)
+	score.BuyRatio = Round3(score.BuyRatio)
+	score.NetVolume = Round3(score.NetVolume)
+	score.Trades = Round3(score.Trades)
+	t.Pump = score
+}
+
+// Stages of a pump event.
+const (
+	PumpStageStart = "start"
+	PumpStagePeak  = "peak"
+	PumpStageFade  = "fade"
+)
+
+type PumpOptions struct {
+	// Score at which an event starts.
+	StartScore float64
+
+	// An event fades once the score has stayed below this for FadeAfter.
+	EndScore  float64
+	FadeAfter time.Duration
+
+	// Fraction of the gain given back from the high that marks the peak,
+	// and that ends the event.
+	PeakRetrace float64
+	EndRetrace  float64
+
+	// Events are ended after this long regardless.
+	MaxDuration time.Duration
+}
+
+var DefaultPumpOptions = PumpOptions{
+	StartScore:  60,
+	EndScore:    30,
+	FadeAfter:   time.Minute * 2,
+	PeakRetrace: 0.25,
+	EndRetrace:  0.5,
+	MaxDuration: time.Hour,
+}
+
+// PumpEvent is a detected pump. It is published and stored when it starts,
+// when the peak is passed and when it fades.
+type PumpEvent struct {
+	Id         string     `json:"id" msgpack:"id"`
+	Symbol     string     `json:"symbol" msgpack:"symbol"`
+	Stage      string     `json:"stage" msgpack:"stage"`
+	Start      time.Time  `json:"start" msgpack:"start"`
+	StartPrice float64    `json:"start_price" msgpack:"start_price"`
+	PeakTime   time.Time  `json:"peak_time" msgpack:"peak_time"`
+	PeakPrice  float64    `json:"peak_price" msgpack:"peak_price"`
+	PeakScore  float64    `json:"peak_score" msgpack:"peak_score"`
+	End        *time.Time `json:"end,omitempty" msgpack:"end,omitempty"`
+	Price      float64    `json:"price" msgpack:"price"`
+	Score      PumpScore  `json:"score" msgpack:"score"`
+
+	// Gain from the start price to the peak price.
+	GainPercent float64 `json:"gain_pct" msgpack:"gain_pct"`
+
+	// Last time the score was at or above the end score.
+	lastActive time.Time
+}
+
+// retrace returns the fraction of the gain given back from the peak.
+func (e *PumpEvent) retrace() float64 {
+	gain := e.PeakPrice - e.StartPrice
+	if gain <= 0 {
+		return 0
+	}
+	return (e.PeakPrice - e.Price) / gain
+}
+
+// PumpDetector follows the pump score of each symbol and tracks pump
+// events through their start, peak and fade.
+type PumpDetector struct {
+	options PumpOptions
+	store   *db.EventStore
+	feed    *EventFeed
+	active  map[string]*PumpEvent
+}
+
+func NewPumpDetector(options PumpOptions, store *db.EventStore, feed *EventFeed) *PumpDetector {
+	return &PumpDetector{
+		options: options,
+		store:   store,
+		feed:    feed,
+		active:  map[string]*PumpEvent{},
+	}
+}
+
+func (d *PumpDetector) Run(channel chan *TickerTrackerMap) {
+	for trackers := range channel {
+		now := time.Now()
+		for _, tracker := range trackers.Trackers {
+			d.update(now, tracker)
+		}
+		for symbol, event := range d.active {
+			if _, exists := trackers.Trackers[symbol]; !exists {
+				d.end(now, event)
+			}
+		}
+	}
+}
+
+func (d *PumpDetector) update(now time.Time, tracker *TickerTracker) {
+	last := tracker.LastTick()
+	if last == nil {
+		return
+	}
+	price := last.CurrentDayClose
+	score := tracker.Pump
+
+	event := d.active[tracker.Symbol]
+	if event == nil {
+		if score.Score < d.options.StartScore {
+			return
+		}
+
+		// Start from the price before the move that triggered the
+		// event.
+		startPrice := price
+		change := tracker.Metrics[pumpBucket].PriceChangePercent
+		if change > -100 {
+			startPrice = Round8(price / (1 + change/100))
+		}
+		event = &PumpEvent{
+			Id:         fmt.Sprintf("%s-%d", tracker.Symbol, now.Unix()),
+			Symbol:     tracker.Symbol,
+			Stage:      PumpStageStart,
+			Start:      now,
+			StartPrice: startPrice,
+			PeakTime:   now,
+			PeakPrice:  price,
+			PeakScore:  score.Score,
+			Price:      price,
+			Score:      score,
+			lastActive: now,
+		}
+		event.GainPercent = gainPercent(event)
+		d.active[tracker.Symbol] = event
+		d.publish(event)
+		return
+	}
+
+	event.Price = price
+	event.Score = score
+	if price > event.PeakPrice {
+		event.PeakPrice = price
+		event.PeakTime = now
+		event.GainPercent = gainPercent(event)
+	}
+	if score.Score > event.PeakScore {
+		event.PeakScore = score.Score
+	}
+	if score.Score >= d.options.EndScore {
+		event.lastActive = now
+	}
+
+	if now.Sub(event.lastActive) >= d.options.FadeAfter ||
+		event.retrace() >= d.options.EndRetrace ||
+		now.Sub(event.Start) >= d.options.MaxDuration {
+		d.end(now, event)
+		return
+	}
+
+	if event.Stage == PumpStageStart && event.retrace() >= d.options.PeakRetrace {
+		event.Stage = PumpStagePeak
+		d.publish(event)
+	}
+}
+
+func (d *PumpDetector) end(now time.Time, event *PumpEvent) {
+	event.Stage = PumpStageFade
+	event.End = &now
+	delete(d.active, event.Symbol)
+	d.publish(event)
+}
+
+func (d *PumpDetector) publish(event *PumpEvent) {
+	log.WithFields(log.Fields{
+		"symbol": event.Symbol,
+		"stage":  event.Stage,
+		"score":  event.Score.Score,
+		"gain":   event.GainPercent,
+	}).Infof("Pump event.")
+
+	// Publish a copy as the event continues to be updated.
+	message := *event
+	d.feed.Publish("pump", &message)
+
+	if d.store != nil {
+		body, err := json.Marshal(&message)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to encode pump event")
+			return
+		}
+		if err := d.store.Save(event.Id, "pump", event.Symbol, event.Start, body); err != nil {
+			log.WithError(err).Errorf("Failed to save pump event")
+		}
+	}
+}
+
+func gainPercent(event *PumpEvent) float64 {
+	if event.StartPrice <= 0 {
+		return 0
+	}
+	return Round3((event.PeakPrice - event.StartPrice) / event.StartPrice * 100)
+}
@@ -0,0 +1,402 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long a client is told to wait when it is over its connection limit.
+const wsConnectionRetryAfter = 10
+
+// RateLimit is a token bucket rate limit. A zero rate disables it.
+type RateLimit struct {
+	// Requests per second.
+	Rate float64
+
+	// Requests that can be made at once after being idle.
+	Burst int
+}
+
+// Limits protect a public deployment from clients opening too many
+// connections or making too many requests. Limits are per client IP.
+type Limits struct {
+	// WebSocket connections per IP, 0 for no limit.
+	WsConnectionsPerIP int
+
+	// The default limit for REST routes, and the limit for the Binance
+	// proxy.
+	Api   RateLimit
+	Proxy RateLimit
+
+	// Limits for specific routes, by path.
+	Routes map[string]RateLimit
+
+	// Addresses or CIDR networks of the reverse proxies whose
+	// X-Forwarded-For and X-Real-IP headers are used to identify clients.
+	TrustedProxies []string
+}
+
+var DefaultLimits = Limits{
+	WsConnectionsPerIP: 16,
+	Api: RateLimit{
+		Rate:  10,
+		Burst: 20,
+	},
+	Proxy: RateLimit{
+		Rate:  2,
+		Burst: 10,
+	},
+	TrustedProxies: []string{"127.0.0.0/8", "::1/128"},
+}
+
+// The networks of the trusted reverse proxies.
+var trustedProxies []*net.IPNet
+
+// ConfigureTrustedProxies sets the reverse proxies whose forwarding headers
+// are trusted.
+func ConfigureTrustedProxies(proxies []string) error {
+	networks := []*net.IPNet{}
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy address: %s", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy network: %s", proxy)
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies = networks
+	return nil
+}
+
+func isTrustedProxy(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// addressHost strips the port, if any, from an address.
+func addressHost(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+}
+
+// requestRemoteHost returns the host of the client making a request. The
+// headers set by a reverse proxy are only used when the request comes from
+// a trusted proxy, in which case the client is the right-most
+// X-Forwarded-For entry that is not itself a trusted proxy.
+func requestRemoteHost(r *http.Request) string {
+	host := addressHost(r.RemoteAddr)
+	if !isTrustedProxy(host) {
+		return host
+	}
+	if forwardedFor := r.Header.Values("x-forwarded-for"); len(forwardedFor) > 0 {
+		hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := addressHost(hops[i])
+			if hop == "" {
+				continue
+			}
+			host = hop
+			if !isTrustedProxy(hop) {
+				break
+			}
+		}
+		return host
+	}
+	if realIp := addressHost(r.Header.Get("x-real-ip")); realIp != "" {
+		return realIp
+	}
+	return host
+}
+
+func writeTooManyRequests(w http.ResponseWriter, retryAfter int) {
+	w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
+	http.Error(w, http.StatusText(http.StatusTooManyRequests),
+		http.StatusTooManyRequests)
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// ipRateLimiter keeps a token bucket per client IP.
+type ipRateLimiter struct {
+	limit     RateLimit
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	lock      sync.Mutex
+}
+
+func newIpRateLimiter(limit RateLimit) *ipRateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &ipRateLimiter{
+		limit:     limit,
+		buckets:   map[string]*tokenBucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token for the host. If there is none, false is returned
+// along with the number of seconds until there will be.
+func (l *ipRateLimiter) Allow(host string) (bool, int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	burst := float64(l.limit.Burst)
+
+	// Forget hosts whose bucket has refilled.
+	if now.Sub(l.lastSweep) > time.Minute {
+		for key, bucket := range l.buckets {
+			if bucket.tokens+now.Sub(bucket.last).Seconds()*l.limit.Rate >= burst {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket := l.buckets[host]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		l.buckets[host] = bucket
+	}
+	bucket.tokens = math.Min(burst,
+		bucket.tokens+now.Sub(bucket.last).Seconds()*l.limit.Rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false, int(math.Ceil((1 - bucket.tokens) / l.limit.Rate))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// ipConnectionLimiter counts the open connections per client IP.
+type ipConnectionLimiter struct {
+	max   int
+	count map[string]int
+	lock  sync.Mutex
+}
+
+func (l *ipConnectionLimiter) Acquire(host string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.count[host] >= l.max {
+		return false
+	}
+	l.count[host]++
+	return true
+}
+
+func (l *ipConnectionLimiter) Release(host string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.count[host]--
+	if l.count[host] <= 0 {
+		delete(l.count, host)
+	}
+}
+
+// Limiter applies Limits to HTTP handlers and the gRPC API.
+type Limiter struct {
+	limits      Limits
+	connections *ipConnectionLimiter
+
+	// Rate limiters of the gRPC methods, by full method name.
+	methods     map[string]*ipRateLimiter
+	methodsLock sync.Mutex
+}
+
+func NewLimiter(limits Limits) *Limiter {
+	return &Limiter{
+		limits: limits,
+		connections: &ipConnectionLimiter{
+			max:   limits.WsConnectionsPerIP,
+			count: map[string]int{},
+		},
+		methods: map[string]*ipRateLimiter{},
+	}
+}
+
+func (l *Limiter) rateLimit(limit RateLimit, handler http.Handler) http.Handler {
+	if limit.Rate <= 0 {
+		return handler
+	}
+	limiter := newIpRateLimiter(limit)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := requestRemoteHost(r)
+		if ok, retryAfter := limiter.Allow(host); !ok {
+			log.Debugf("Rate limiting %s on %s", host, r.URL.Path)
+			writeTooManyRequests(w, retryAfter)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Route rate limits requests to a REST route, using the limit configured
+// for the path or the default.
+func (l *Limiter) Route(path string, handler http.Handler) http.Handler {
+	limit, ok := l.limits.Routes[path]
+	if !ok {
+		limit = l.limits.Api
+	}
+	return l.rateLimit(limit, handler)
+}
+
+func (l *Limiter) RouteFunc(path string, handler http.HandlerFunc) http.Handler {
+	return l.Route(path, handler)
+}
+
+// Proxy rate limits requests to the Binance API proxy.
+func (l *Limiter) Proxy(handler http.Handler) http.Handler {
+	return l.rateLimit(l.limits.Proxy, handler)
+}
+
+// WebSocket limits the number of WebSocket connections a client can have
+// open on handler. The handler is expected to return when the connection
+// closes.
+func (l *Limiter) WebSocket(handler http.HandlerFunc) http.Handler {
+	if l.limits.WsConnectionsPerIP <= 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := requestRemoteHost(r)
+		if !l.connections.Acquire(host) {
+			log.Infof("Rejecting websocket connection from %s: too many connections", host)
+			writeTooManyRequests(w, wsConnectionRetryAfter)
+			return
+		}
+		defer l.connections.Release(host)
+		handler(w, r)
+	})
+}
+
+// methodLimiter returns the rate limiter of a gRPC method, using the limit
+// configured for the method name or the default. Nil is returned if the
+// method is not rate limited.
+func (l *Limiter) methodLimiter(method string) *ipRateLimiter {
+	l.methodsLock.Lock()
+	defer l.methodsLock.Unlock()
+	limiter, ok := l.methods[method]
+	if !ok {
+		limit, ok := l.limits.Routes[method]
+		if !ok {
+			limit = l.limits.Api
+		}
+		if limit.Rate > 0 {
+			limiter = newIpRateLimiter(limit)
+		}
+		l.methods[method] = limiter
+	}
+	return limiter
+}
+
+// allowGrpc takes a token for a call to method by host. If there is none,
+// the retry delay is set in the header and an error returned.
+func (l *Limiter) allowGrpc(ctx context.Context, method string, host string) error {
+	limiter := l.methodLimiter(method)
+	if limiter == nil {
+		return nil
+	}
+	if ok, retryAfter := limiter.Allow(host); !ok {
+		log.Debugf("Rate limiting %s on %s", host, method)
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", fmt.Sprintf("%d", retryAfter)))
+		return status.Errorf(codes.ResourceExhausted,
+			"too many requests, retry after %d seconds", retryAfter)
+	}
+	return nil
+}
+
+// GrpcServerOptions returns the interceptors that apply the limits to the
+// gRPC API. Calls are rate limited per method like the REST routes, and
+// streams also count against the connection limit of the WebSockets.
+func (l *Limiter) GrpcServerOptions() []grpc.ServerOption {
+	unary := func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.allowGrpc(ctx, info.FullMethod, grpcRemoteHost(ctx)); err != nil {
+			return nil, err
+		}
+		return handler(ctx, request)
+	}
+	stream := func(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		host := grpcRemoteHost(stream.Context())
+		if err := l.allowGrpc(stream.Context(), info.FullMethod, host); err != nil {
+			return err
+		}
+		if l.limits.WsConnectionsPerIP > 0 {
+			if !l.connections.Acquire(host) {
+				log.Infof("Rejecting gRPC stream from %s: too many connections", host)
+				grpc.SetHeader(stream.Context(), metadata.Pairs("retry-after",
+					fmt.Sprintf("%d", wsConnectionRetryAfter)))
+				return status.Error(codes.ResourceExhausted, "too many connections")
+			}
+			defer l.connections.Release(host)
+		}
+		return handler(server, stream)
+	}
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(unary),
+		grpc.StreamInterceptor(stream),
+	}
+}
@@ -0,0 +1,85 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestRemoteHost(t *testing.T) {
+	if err := ConfigureTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16", "fd00::/8"}); err != nil {
+		t.Fatal(err)
+	}
+	defer ConfigureTrustedProxies(DefaultLimits.TrustedProxies)
+
+	tests := []struct {
+		remoteAddr   string
+		forwardedFor []string
+		realIp       string
+		expected     string
+	}{
+		// Headers from untrusted peers are ignored.
+		{"1.2.3.4:5000", nil, "", "1.2.3.4"},
+		{"1.2.3.4:5000", []string{"5.6.7.8"}, "5.6.7.8", "1.2.3.4"},
+		{"[2001:db8::1]:5000", []string{"5.6.7.8"}, "", "2001:db8::1"},
+
+		// The right-most entry that is not a trusted proxy.
+		{"10.0.0.1:5000", []string{"5.6.7.8"}, "", "5.6.7.8"},
+		{"10.0.0.1:5000", []string{"9.9.9.9, 5.6.7.8, 192.168.1.1"}, "", "5.6.7.8"},
+		{"10.0.0.1:5000", []string{"9.9.9.9", "5.6.7.8,192.168.1.1"}, "", "5.6.7.8"},
+		{"[fd00::2]:5000", []string{"2001:db8::2, fd00::3"}, "", "2001:db8::2"},
+		{"10.0.0.1:5000", []string{"[2001:db8::2]:4000"}, "", "2001:db8::2"},
+		{"10.0.0.1:5000", []string{"192.168.1.2, 192.168.1.1"}, "", "192.168.1.2"},
+
+		// X-Real-IP when there is no X-Forwarded-For.
+		{"10.0.0.1:5000", nil, "5.6.7.8", "5.6.7.8"},
+		{"10.0.0.1:5000", nil, "", "10.0.0.1"},
+		{"10.0.0.2:5000", nil, "5.6.7.8", "10.0.0.2"},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remoteAddr
+		for _, value := range test.forwardedFor {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if test.realIp != "" {
+			r.Header.Set("X-Real-IP", test.realIp)
+		}
+		if host := requestRemoteHost(r); host != test.expected {
+			t.Errorf("%s %v %q: expected %s, got %s", test.remoteAddr,
+				test.forwardedFor, test.realIp, test.expected, host)
+		}
+	}
+}
+
+func TestConfigureTrustedProxies(t *testing.T) {
+	defer ConfigureTrustedProxies(DefaultLimits.TrustedProxies)
+	for _, proxies := range [][]string{{"localhost"}, {"10.0.0.0/33"}} {
+		if err := ConfigureTrustedProxies(proxies); err == nil {
+			t.Errorf("expected an error for %v", proxies)
+		}
+	}
+}
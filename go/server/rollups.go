@@ -0,0 +1,273 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"math"
+	"time"
+)
+
+// Windows, in minutes, of the rolling metrics. Unlike the buckets they are
+// calculated from per minute rollups, which are kept for a day and
+// persisted so they survive a restart.
+var RollingWindows = []int{120, 240, 1440}
+
+// How long rollups are kept in memory.
+const rollupHistory = 24 * time.Hour
+
+// MinuteRollup is the trade activity of a tracker over one minute.
+type MinuteRollup struct {
+	Minute      int64
+	BuyVolume   float64
+	SellVolume  float64
+	BuyTrades   uint32
+	SellTrades  uint32
+	PriceVolume float64
+	Quantity    float64
+}
+
+// RollingMetrics are the trade metrics of a rolling window. Values that
+// need trades are NaN without any.
+type RollingMetrics struct {
+	TotalVolume float64 `msgpack:"total_volume"`
+	NetVolume   float64 `msgpack:"nv"`
+	BuyVolume   float64 `msgpack:"bv"`
+	SellVolume  float64 `msgpack:"sv"`
+	Trades      uint64  `msgpack:"trades"`
+
+	// Share of the trades that were buys.
+	BuyRatio float64 `msgpack:"buy_ratio"`
+
+	Vwap float64 `msgpack:"vwap"`
+
+	// Share of the minutes of the window the scanner has rollups for,
+	// less than 1 until it has a full window of history or if it was down
+	// during the window. See CalculateRollupCoverage.
+	Coverage float64 `msgpack:"coverage"`
+}
+
+// addRollup counts a trade in the rollup of its minute. Trades in minutes
+// that were restored from the rollup store are already counted.
+func (t *TickerTracker) addRollup(trade *binanceapi.StreamAggTrade) {
+	minute := trade.Timestamp().Truncate(time.Minute)
+	if !minute.After(t.rollupsRestored) {
+		return
+	}
+	if len(t.Rollups) == 0 || t.Rollups[len(t.Rollups)-1].Minute < minute.Unix() {
+		t.Rollups = append(t.Rollups, MinuteRollup{Minute: minute.Unix()})
+	}
+
+	// Trades may arrive slightly out of order.
+	i := len(t.Rollups) - 1
+	for i > 0 && t.Rollups[i].Minute > minute.Unix() {
+		i--
+	}
+	if t.Rollups[i].Minute != minute.Unix() {
+		return
+	}
+	rollup := &t.Rollups[i]
+	if trade.BuyerMaker {
+		rollup.SellVolume += trade.QuoteQuantity()
+		rollup.SellTrades++
+	} else {
+		rollup.BuyVolume += trade.QuoteQuantity()
+		rollup.BuyTrades++
+	}
+	rollup.PriceVolume += trade.Price * trade.Quantity
+	rollup.Quantity += trade.Quantity
+}
+
+// CalculateRollingMetrics prunes the rollups and sets the metrics of the
+// rolling windows.
+func (t *TickerTracker) CalculateRollingMetrics(now time.Time) {
+	chop := 0
+	oldest := now.Add(-rollupHistory).Unix()
+	for chop < len(t.Rollups) && t.Rollups[chop].Minute < oldest {
+		chop++
+	}
+	if chop > 0 {
+		t.Rollups = t.Rollups[chop:]
+	}
+
+	rolling := map[int]*RollingMetrics{}
+	for _, window := range RollingWindows {
+		since := now.Add(-time.Duration(window) * time.Minute)
+		metrics := &RollingMetrics{}
+		priceVolume := float64(0)
+		quantity := float64(0)
+		buyTrades := uint64(0)
+		for i := len(t.Rollups) - 1; i >= 0 && t.Rollups[i].Minute >= since.Unix(); i-- {
+			rollup := t.Rollups[i]
+			metrics.BuyVolume += rollup.BuyVolume
+			metrics.SellVolume += rollup.SellVolume
+			metrics.Trades += uint64(rollup.BuyTrades) + uint64(rollup.SellTrades)
+			buyTrades += uint64(rollup.BuyTrades)
+			priceVolume += rollup.PriceVolume
+			quantity += rollup.Quantity
+		}
+		metrics.TotalVolume = metrics.BuyVolume + metrics.SellVolume
+		metrics.NetVolume = metrics.BuyVolume - metrics.SellVolume
+		metrics.BuyRatio = math.NaN()
+		if metrics.Trades > 0 {
+			metrics.BuyRatio = Round3(float64(buyTrades) / float64(metrics.Trades))
+		}
+		metrics.Vwap = math.NaN()
+		if quantity > 0 {
+			metrics.Vwap = Round8(priceVolume / quantity)
+		}
+		rolling[window] = metrics
+	}
+	t.Rolling = rolling
+}
+
+// CalculateRollupCoverage sets the coverage of the rolling metrics of the
+// trackers. A minute is covered if any tracker has a rollup for it, as
+// the market trades every minute, so minutes the scanner was down for,
+// including those between the rollups restored at startup, are not. It
+// must be called after the rolling metrics are calculated.
+func CalculateRollupCoverage(trackers *TickerTrackerMap, now time.Time) {
+	current := now.Truncate(time.Minute).Unix()
+
+	// Indexed by the age of the minute, the current one first.
+	covered := make([]bool, int(rollupHistory/time.Minute))
+	for _, tracker := range trackers.Trackers {
+		tracker.lock.RLock()
+		for i := len(tracker.Rollups) - 1; i >= 0; i-- {
+			age := (current - tracker.Rollups[i].Minute) / 60
+			if age >= int64(len(covered)) {
+				break
+			}
+			if age >= 0 {
+				covered[age] = true
+			}
+		}
+		tracker.lock.RUnlock()
+	}
+
+	coverage := map[int]float64{}
+	for _, window := range RollingWindows {
+		minutes := 0
+		for age := 0; age < window && age < len(covered); age++ {
+			if covered[age] {
+				minutes++
+			}
+		}
+		coverage[window] = Round3(float64(minutes) / float64(window))
+	}
+
+	for _, tracker := range trackers.Trackers {
+		tracker.lock.Lock()
+		for window, metrics := range tracker.Rolling {
+			metrics.Coverage = coverage[window]
+		}
+		tracker.lock.Unlock()
+	}
+}
+
+// restoreRollups loads the persisted rollups of the last day into the
+// trackers. Trades restored afterwards from the trade cache are not
+// counted again for the minutes the rollups cover.
+func (b *BinanceRunner) restoreRollups() {
+	if b.rollups == nil {
+		return
+	}
+	rollups, err := b.rollups.LoadSince(time.Now().Add(-rollupHistory))
+	if err != nil {
+		log.WithError(err).Errorf("Failed to load rollups")
+		return
+	}
+	b.addRestoredRollups(rollups, b.universe.Contains)
+	log.Infof("Restored %d rollups.", len(rollups))
+}
+
+// addRestoredRollups adds the rollups of the symbols keep returns true
+// for to the trackers.
+func (b *BinanceRunner) addRestoredRollups(rollups []db.Rollup, keep func(symbol string) bool) {
+	last := time.Time{}
+	for _, r := range rollups {
+		if !keep(r.Symbol) {
+			continue
+		}
+		tracker := b.trackers.GetTracker(r.Symbol)
+		tracker.Rollups = append(tracker.Rollups, MinuteRollup{
+			Minute:      r.Minute.Unix(),
+			BuyVolume:   r.BuyVolume,
+			SellVolume:  r.SellVolume,
+			BuyTrades:   uint32(r.BuyTrades),
+			SellTrades:  uint32(r.SellTrades),
+			PriceVolume: r.PriceVolume,
+			Quantity:    r.Quantity,
+		})
+		if r.Minute.After(last) {
+			last = r.Minute
+		}
+	}
+
+	// The saved minutes are complete, also for the symbols without trades
+	// in them.
+	for _, tracker := range b.trackers.Trackers {
+		tracker.rollupsRestored = last
+	}
+	b.rollupsSaved = last.Add(time.Minute)
+}
+
+// saveRollups saves the minutes that have completed since the last save.
+func (b *BinanceRunner) saveRollups(now time.Time) {
+	current := now.Truncate(time.Minute)
+	if b.rollups == nil || !current.After(b.rollupsSaved) {
+		return
+	}
+	rollups := []db.Rollup{}
+	for symbol, tracker := range b.trackers.Trackers {
+		for i := len(tracker.Rollups) - 1; i >= 0; i-- {
+			r := tracker.Rollups[i]
+			minute := time.Unix(r.Minute, 0)
+			if minute.Before(b.rollupsSaved) {
+				break
+			}
+			if !minute.Before(current) {
+				continue
+			}
+			rollups = append(rollups, db.Rollup{
+				Symbol:      symbol,
+				Minute:      minute,
+				BuyVolume:   r.BuyVolume,
+				SellVolume:  r.SellVolume,
+				BuyTrades:   uint64(r.BuyTrades),
+				SellTrades:  uint64(r.SellTrades),
+				PriceVolume: r.PriceVolume,
+				Quantity:    r.Quantity,
+			})
+		}
+	}
+	if err := b.rollups.Save(rollups); err != nil {
+		log.WithError(err).Errorf("Failed to save rollups")
+		return
+	}
+	b.rollupsSaved = current
+}
@@ -0,0 +1,262 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var rollupStart = time.Date(2019, 2, 15, 12, 0, 0, 0, time.UTC)
+
+func rollupTrade(at time.Duration, price float64, quantity float64, sell bool) binanceapi.StreamAggTrade {
+	return binanceapi.StreamAggTrade{
+		Symbol:     "ETHBTC",
+		Price:      price,
+		Quantity:   quantity,
+		TradeTime:  milliseconds(rollupStart.Add(at)),
+		BuyerMaker: sell,
+	}
+}
+
+func TestAddRollup(t *testing.T) {
+	minute := rollupStart.Unix()
+	tests := []struct {
+		name     string
+		restored time.Time
+		trades   []binanceapi.StreamAggTrade
+		expected []MinuteRollup
+	}{
+		{
+			name: "buys and sells",
+			trades: []binanceapi.StreamAggTrade{
+				rollupTrade(10*time.Second, 2, 1, false),
+				rollupTrade(20*time.Second, 4, 2, true),
+				rollupTrade(70*time.Second, 2, 3, false),
+			},
+			expected: []MinuteRollup{
+				{Minute: minute, BuyVolume: 2, SellVolume: 8, BuyTrades: 1, SellTrades: 1, PriceVolume: 10, Quantity: 3},
+				{Minute: minute + 60, BuyVolume: 6, BuyTrades: 1, PriceVolume: 6, Quantity: 3},
+			},
+		},
+		{
+			name: "out of order",
+			trades: []binanceapi.StreamAggTrade{
+				rollupTrade(70*time.Second, 2, 1, false),
+				rollupTrade(50*time.Second, 2, 1, false),
+			},
+			expected: []MinuteRollup{
+				{Minute: minute + 60, BuyVolume: 2, BuyTrades: 1, PriceVolume: 2, Quantity: 1},
+			},
+		},
+		{
+			name:     "restored minutes",
+			restored: rollupStart,
+			trades: []binanceapi.StreamAggTrade{
+				rollupTrade(10*time.Second, 2, 1, false),
+				rollupTrade(70*time.Second, 2, 1, true),
+			},
+			expected: []MinuteRollup{
+				{Minute: minute + 60, SellVolume: 2, SellTrades: 1, PriceVolume: 2, Quantity: 1},
+			},
+		},
+	}
+	for _, test := range tests {
+		tracker := NewTickerTracker("ETHBTC")
+		tracker.rollupsRestored = test.restored
+		for i := range test.trades {
+			tracker.addRollup(&test.trades[i])
+		}
+		if !reflect.DeepEqual(tracker.Rollups, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, tracker.Rollups)
+		}
+	}
+}
+
+func TestCalculateRollingMetrics(t *testing.T) {
+	now := rollupStart.Add(30 * time.Second)
+	rollup := func(age int, buys uint32, sells uint32) MinuteRollup {
+		return MinuteRollup{
+			Minute:      rollupStart.Unix() - int64(age)*60,
+			BuyVolume:   float64(buys),
+			SellVolume:  float64(sells),
+			BuyTrades:   buys,
+			SellTrades:  sells,
+			PriceVolume: float64(buys+sells) * 2,
+			Quantity:    float64(buys + sells),
+		}
+	}
+
+	tracker := NewTickerTracker("ETHBTC")
+	tracker.Rollups = []MinuteRollup{
+		rollup(1440, 5, 5),
+		rollup(1000, 1, 1),
+		rollup(200, 0, 2),
+		rollup(0, 3, 1),
+	}
+	tracker.CalculateRollingMetrics(now)
+
+	if len(tracker.Rollups) != 3 {
+		t.Errorf("expected the rollup older than a day to be pruned, got %d rollups",
+			len(tracker.Rollups))
+	}
+	tests := []struct {
+		window   int
+		expected RollingMetrics
+	}{
+		{120, RollingMetrics{TotalVolume: 4, NetVolume: 2, BuyVolume: 3, SellVolume: 1, Trades: 4, BuyRatio: 0.75, Vwap: 2}},
+		{240, RollingMetrics{TotalVolume: 6, NetVolume: 0, BuyVolume: 3, SellVolume: 3, Trades: 6, BuyRatio: 0.5, Vwap: 2}},
+		{1440, RollingMetrics{TotalVolume: 8, NetVolume: 0, BuyVolume: 4, SellVolume: 4, Trades: 8, BuyRatio: 0.5, Vwap: 2}},
+	}
+	for _, test := range tests {
+		if metrics := tracker.Rolling[test.window]; *metrics != test.expected {
+			t.Errorf("%d: expected %+v, got %+v", test.window, test.expected, *metrics)
+		}
+	}
+
+	empty := NewTickerTracker("XRPBTC")
+	empty.CalculateRollingMetrics(now)
+	metrics := empty.Rolling[120]
+	if !math.IsNaN(metrics.BuyRatio) || !math.IsNaN(metrics.Vwap) {
+		t.Errorf("expected NaN buy ratio and vwap without trades, got %+v", *metrics)
+	}
+}
+
+func TestCalculateRollupCoverage(t *testing.T) {
+	now := rollupStart.Add(30 * time.Second)
+	minutes := func(from int, to int) []MinuteRollup {
+		rollups := []MinuteRollup{}
+		for age := from; age >= to; age-- {
+			rollups = append(rollups, MinuteRollup{Minute: rollupStart.Unix() - int64(age)*60})
+		}
+		return rollups
+	}
+	tests := []struct {
+		name     string
+		rollups  [][]MinuteRollup
+		expected map[int]float64
+	}{
+		{
+			name:     "recent start",
+			rollups:  [][]MinuteRollup{minutes(59, 0)},
+			expected: map[int]float64{120: 0.5, 240: 0.25, 1440: 0.042},
+		},
+		{
+			name:     "full day",
+			rollups:  [][]MinuteRollup{minutes(1439, 0)},
+			expected: map[int]float64{120: 1, 240: 1, 1440: 1},
+		},
+		{
+			// Down for the hour before the last one.
+			name:     "downtime",
+			rollups:  [][]MinuteRollup{minutes(1439, 120), minutes(59, 0)},
+			expected: map[int]float64{120: 0.5, 240: 0.75, 1440: 0.958},
+		},
+		{
+			name:     "minutes of other symbols",
+			rollups:  [][]MinuteRollup{minutes(119, 60), minutes(59, 0)},
+			expected: map[int]float64{120: 1, 240: 0.5, 1440: 0.083},
+		},
+	}
+	for _, test := range tests {
+		trackers := NewTickerTrackerMap()
+		symbols := []string{"ETHBTC", "XRPBTC"}
+		for i, rollups := range test.rollups {
+			trackers.GetTracker(symbols[i]).Rollups = rollups
+		}
+		for _, tracker := range trackers.Trackers {
+			tracker.CalculateRollingMetrics(now)
+		}
+		CalculateRollupCoverage(trackers, now)
+		for symbol, tracker := range trackers.Trackers {
+			for window, coverage := range test.expected {
+				if got := tracker.Rolling[window].Coverage; got != coverage {
+					t.Errorf("%s: %s: expected coverage %v for %d, got %v",
+						test.name, symbol, coverage, window, got)
+				}
+			}
+		}
+	}
+}
+
+func TestRestoreRollups(t *testing.T) {
+	t.Chdir(t.TempDir())
+	store, err := db.OpenRollupStore("rollups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The store expires rollups by the current time.
+	saved := time.Now().Truncate(time.Minute).Add(-time.Hour)
+	err = store.Save([]db.Rollup{
+		{Symbol: "ETHBTC", Minute: saved.Add(-time.Minute), BuyVolume: 1, BuyTrades: 1, PriceVolume: 1, Quantity: 1},
+		{Symbol: "ETHBTC", Minute: saved, SellVolume: 2, SellTrades: 2, PriceVolume: 2, Quantity: 2},
+		{Symbol: "DELISTED", Minute: saved, BuyVolume: 1, BuyTrades: 1, PriceVolume: 1, Quantity: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rollups, err := store.LoadSince(saved.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &BinanceRunner{trackers: NewTickerTrackerMap()}
+	runner.trackers.GetTracker("XRPBTC")
+	runner.addRestoredRollups(rollups, func(symbol string) bool {
+		return symbol != "DELISTED"
+	})
+
+	if _, ok := runner.trackers.Trackers["DELISTED"]; ok {
+		t.Errorf("expected no tracker for a symbol not in the universe")
+	}
+	expected := []MinuteRollup{
+		{Minute: saved.Add(-time.Minute).Unix(), BuyVolume: 1, BuyTrades: 1, PriceVolume: 1, Quantity: 1},
+		{Minute: saved.Unix(), SellVolume: 2, SellTrades: 2, PriceVolume: 2, Quantity: 2},
+	}
+	if rollups := runner.trackers.Trackers["ETHBTC"].Rollups; !reflect.DeepEqual(rollups, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rollups)
+	}
+	for symbol, tracker := range runner.trackers.Trackers {
+		if !tracker.rollupsRestored.Equal(saved) {
+			t.Errorf("%s: expected restored up to %v, got %v", symbol, saved, tracker.rollupsRestored)
+		}
+	}
+	if !runner.rollupsSaved.Equal(saved.Add(time.Minute)) {
+		t.Errorf("expected saved up to %v, got %v", saved.Add(time.Minute), runner.rollupsSaved)
+	}
+
+	// The trade cache replays trades of the restored minutes too.
+	tracker := runner.trackers.GetTracker("ETHBTC")
+	for _, at := range []time.Time{saved.Add(10 * time.Second), saved.Add(70 * time.Second)} {
+		tracker.addRollup(&binanceapi.StreamAggTrade{Price: 1, Quantity: 1, TradeTime: milliseconds(at)})
+	}
+	if len(tracker.Rollups) != 3 || tracker.Rollups[1].BuyTrades != 0 || tracker.Rollups[2].BuyTrades != 1 {
+		t.Errorf("expected only the trade after the restored minutes added, got %+v", tracker.Rollups)
+	}
+}
@@ -0,0 +1,242 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The JSON Schemas of the feeds are generated from the entry types and
+// served at /api/1/schema/{feed}.json. Each describes the TickerStream
+// message of the feed, with the entry itself under definitions/entry as
+// that is what the symbol socket sends.
+
+var schemaEntryTypes = map[string]reflect.Type{
+	"live":    reflect.TypeOf(CompleteEntry{}),
+	"monitor": reflect.TypeOf(MonitorEntry{}),
+}
+
+// entryFieldName returns the wire name of a field given by tag, with the
+// bucket formatted in for bucket fields and its label for window fields
+// and bucket fields formatted with %s, or an empty string if the field is
+// not sent.
+func entryFieldName(field reflect.StructField, tag string, bucket int) string {
+	name := strings.Split(field.Tag.Get(tag), ",")[0]
+	if name == "" || name == "-" {
+		return ""
+	}
+	if tag == "bucket" && !strings.Contains(name, "%s") {
+		return fmt.Sprintf(name, bucket)
+	}
+	if tag == "bucket" || tag == "window" {
+		return fmt.Sprintf(name, bucketLabel(bucket))
+	}
+	return name
+}
+
+func schemaType(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return map[string]interface{}{}
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{
+			"type":   "string",
+			"format": "date-time",
+		}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaType(t.Elem()),
+		}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaType(t.Elem()),
+		}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		addSchemaProperties(properties, &required, t, "json", 0)
+		markNullable(properties, t)
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// addSchemaProperties adds the fields of struct type t named by the given
+// tag to properties. Pointer and omitempty fields are optional, all others
+// required.
+func addSchemaProperties(properties map[string]interface{}, required *[]string,
+	t reflect.Type, tag string, bucket int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			addSchemaProperties(properties, required, field.Type, tag, bucket)
+			continue
+		}
+		name := entryFieldName(field, tag, bucket)
+		if name == "" {
+			continue
+		}
+		property := schemaType(field.Type)
+		if doc := field.Tag.Get("doc"); doc != "" {
+			if tag == "bucket" {
+				doc = fmt.Sprintf("%s Over %d minutes.", doc, bucket)
+			} else if tag == "window" {
+				doc = fmt.Sprintf("%s Over the last %s.", doc, bucketLabel(bucket))
+			}
+			property["description"] = doc
+		}
+		properties[name] = property
+		if field.Type.Kind() != reflect.Ptr && !strings.Contains(field.Tag.Get(tag), ",omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// markNullable marks the properties of struct type t that are encoded as
+// null when nil, in the OpenAPI way.
+func markNullable(properties map[string]interface{}, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := entryFieldName(field, "json", 0)
+		property, ok := properties[name].(map[string]interface{})
+		if !ok || strings.Contains(field.Tag.Get("json"), ",omitempty") {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			if field.Type != reflect.TypeOf(json.RawMessage{}) {
+				property["nullable"] = true
+			}
+		}
+	}
+}
+
+// EntrySchema returns the JSON Schema of an entry type.
+func EntrySchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	addSchemaProperties(properties, &required, t, "json", 0)
+	if t == reflect.TypeOf(CompleteEntry{}) {
+		for _, bucket := range Buckets {
+			addSchemaProperties(properties, &required,
+				reflect.TypeOf(CompleteBucketEntry{}), "bucket", bucket)
+		}
+
+		// Buckets too long for an anomaly baseline never have scores.
+		for _, name := range anomalyFieldNames(Buckets) {
+			delete(properties, name)
+		}
+
+		// Rolling metrics are sent once the tracker has been
+		// recalculated.
+		for _, window := range RollingWindows {
+			optional := []string{}
+			addSchemaProperties(properties, &optional,
+				reflect.TypeOf(CompleteRollingEntry{}), "window", window)
+		}
+
+		// Profiles are left out until the window has trades.
+		for _, window := range volumeProfileOptions.Windows {
+			optional := []string{}
+			addSchemaProperties(properties, &optional,
+				reflect.TypeOf(CompleteProfileEntry{}), "bucket", window)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// FeedSchema returns the JSON Schema of the messages of the named feed, or
+// nil if there is no such feed.
+func FeedSchema(feed string) map[string]interface{} {
+	entryType, ok := schemaEntryTypes[feed]
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$id":     fmt.Sprintf("/api/1/schema/%s.json", feed),
+		"title":   fmt.Sprintf("Binance %s feed, version %d", feed, TickerSchemaVersion),
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"version": map[string]interface{}{
+				"type":  "integer",
+				"const": TickerSchemaVersion,
+			},
+			"tickers": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"$ref": "#/definitions/entry"},
+			},
+		},
+		"required": []string{"version", "tickers"},
+		"definitions": map[string]interface{}{
+			"entry": EntrySchema(entryType),
+		},
+	}
+}
+
+func schemaHandler(w http.ResponseWriter, r *http.Request) {
+	schema := FeedSchema(mux.Vars(r)["feed"])
+	if schema == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Add("content-type", "application/schema+json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		log.WithError(err).WithField("handler", "schema").
+			Errorf("Failed to encode response to JSON")
+	}
+}
@@ -0,0 +1,375 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default and maximum number of results in a screener response.
+const screenerDefaultLimit = 50
+const screenerMaxLimit = 1000
+
+// Comparison operators of screener filters, two character operators
+// first so they are matched before their one character prefixes.
+var screenerOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// ScreenerFilter is a condition on a numeric metric, for example
+// nv_15>1000.
+type ScreenerFilter struct {
+	Field    string
+	Operator string
+	Value    float64
+}
+
+func ParseScreenerFilter(filter string) (ScreenerFilter, error) {
+	for _, operator := range screenerOperators {
+		i := strings.Index(filter, operator)
+		if i < 1 {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(filter[i+len(operator):]), 64)
+		if err != nil {
+			return ScreenerFilter{}, fmt.Errorf("invalid value in filter %q", filter)
+		}
+		return ScreenerFilter{
+			Field:    strings.TrimSpace(filter[:i]),
+			Operator: operator,
+			Value:    value,
+		}, nil
+	}
+	return ScreenerFilter{}, fmt.Errorf("invalid filter %q", filter)
+}
+
+// Match returns false if the entry does not have the field or it is not
+// numeric.
+func (f ScreenerFilter) Match(entry map[string]interface{}) bool {
+	value, ok := numericField(entry, f.Field)
+	if !ok {
+		return false
+	}
+	switch f.Operator {
+	case ">=":
+		return value >= f.Value
+	case "<=":
+		return value <= f.Value
+	case "!=":
+		return value != f.Value
+	case ">":
+		return value > f.Value
+	case "<":
+		return value < f.Value
+	default:
+		return value == f.Value
+	}
+}
+
+// numericField returns the value of field as a float64 if it is of any
+// numeric kind, such as the uint64 trade counts.
+func numericField(entry map[string]interface{}, field string) (float64, bool) {
+	value := reflect.ValueOf(entry[field])
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), true
+	}
+	return 0, false
+}
+
+type ScreenerQuery struct {
+	Sort        string
+	Ascending   bool
+	Offset      int
+	Limit       int
+	QuoteAssets []string
+	MinVolume   float64
+	Filters     []ScreenerFilter
+	Fields      []string
+}
+
+// ParseScreenerQuery reads a screener query from the request parameters:
+//
+//	sort: metric to rank by, default volume
+//	order: asc or desc, default desc
+//	limit, offset: page of the ranked results
+//	quote: comma separated quote assets
+//	min_volume: minimum 24 hour volume in the quote asset
+//	filter: metric conditions such as nv_15>1000, comma separated or
+//	  repeated
+//	fields: comma separated metrics to include in the results
+func ParseScreenerQuery(r *http.Request) (*ScreenerQuery, error) {
+	query := &ScreenerQuery{
+		Sort:   "volume",
+		Limit:  screenerDefaultLimit,
+		Fields: splitParam(r.FormValue("fields")),
+	}
+	if value := r.FormValue("sort"); value != "" {
+		query.Sort = value
+	}
+	switch r.FormValue("order") {
+	case "", "desc":
+	case "asc":
+		query.Ascending = true
+	default:
+		return nil, fmt.Errorf("invalid order %q", r.FormValue("order"))
+	}
+	if value := r.FormValue("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > screenerMaxLimit {
+			return nil, fmt.Errorf("invalid limit %q", value)
+		}
+		query.Limit = limit
+	}
+	if value := r.FormValue("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid offset %q", value)
+		}
+		query.Offset = offset
+	}
+	for _, quote := range splitParam(r.FormValue("quote")) {
+		query.QuoteAssets = append(query.QuoteAssets, strings.ToUpper(quote))
+	}
+	if value := r.FormValue("min_volume"); value != "" {
+		minVolume, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_volume %q", value)
+		}
+		query.MinVolume = minVolume
+	}
+	for _, param := range r.Form["filter"] {
+		for _, value := range splitParam(param) {
+			filter, err := ParseScreenerFilter(value)
+			if err != nil {
+				return nil, err
+			}
+			query.Filters = append(query.Filters, filter)
+		}
+	}
+	return query, nil
+}
+
+func (q *ScreenerQuery) match(quote string, entry map[string]interface{}) bool {
+	if len(q.QuoteAssets) > 0 {
+		found := false
+		for _, asset := range q.QuoteAssets {
+			if quote == asset {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if volume, _ := numericField(entry, "volume"); volume < q.MinVolume {
+		return false
+	}
+	for _, filter := range q.Filters {
+		if !filter.Match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+type screenerMatch struct {
+	// Nil for the entries of a snapshot.
+	tracker *TickerTracker
+
+	symbol string
+	quote  string
+	entry  map[string]interface{}
+}
+
+func liveScreenerCandidates(trackers *TickerTrackerMap) []screenerMatch {
+	candidates := []screenerMatch{}
+	for _, tracker := range trackers.Trackers {
+		entry := WsBuildCompleteEntry(tracker)
+		if entry == nil {
+			continue
+		}
+		candidates = append(candidates, screenerMatch{
+			tracker: tracker,
+			symbol:  tracker.Symbol,
+			quote:   tracker.QuoteAsset,
+			entry:   entry,
+		})
+	}
+	return candidates
+}
+
+func snapshotScreenerCandidates(snapshot *MetricSnapshot) []screenerMatch {
+	candidates := []screenerMatch{}
+	for _, entry := range snapshot.Tickers {
+		symbol, _ := entry["symbol"].(string)
+		quote, _ := entry["quote"].(string)
+		candidates = append(candidates, screenerMatch{
+			symbol: symbol,
+			quote:  quote,
+			entry:  entry,
+		})
+	}
+	return candidates
+}
+
+// Rank returns the trackers of the page of matching entries, ranked by
+// the sort metric, and the number of entries that matched. Entries
+// without the sort metric are ranked last.
+func (q *ScreenerQuery) Rank(trackers *TickerTrackerMap) ([]*TickerTracker, int) {
+	matches, total := q.rank(liveScreenerCandidates(trackers))
+	ranked := []*TickerTracker{}
+	for _, match := range matches {
+		ranked = append(ranked, match.tracker)
+	}
+	return ranked, total
+}
+
+func (q *ScreenerQuery) rank(candidates []screenerMatch) ([]screenerMatch, int) {
+	matches := []screenerMatch{}
+	for _, candidate := range candidates {
+		if q.match(candidate.quote, candidate.entry) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, aok := numericField(matches[i].entry, q.Sort)
+		b, bok := numericField(matches[j].entry, q.Sort)
+		if aok != bok {
+			return aok
+		}
+		if aok && a != b {
+			if q.Ascending {
+				return a < b
+			}
+			return a > b
+		}
+		return matches[i].symbol < matches[j].symbol
+	})
+
+	total := len(matches)
+	if q.Offset >= total {
+		return []screenerMatch{}, total
+	}
+	matches = matches[q.Offset:]
+	if len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+	}
+	return matches, total
+}
+
+// Run returns the page of matching entries as Rank does, with the rank of
+// each entry and only the requested fields.
+func (q *ScreenerQuery) Run(trackers *TickerTrackerMap) ([]map[string]interface{}, int) {
+	return q.results(q.rank(liveScreenerCandidates(trackers)))
+}
+
+// RunSnapshot is Run on the entries of a snapshot.
+func (q *ScreenerQuery) RunSnapshot(snapshot *MetricSnapshot) ([]map[string]interface{}, int) {
+	return q.results(q.rank(snapshotScreenerCandidates(snapshot)))
+}
+
+func (q *ScreenerQuery) results(matches []screenerMatch, total int) ([]map[string]interface{}, int) {
+	entries := []map[string]interface{}{}
+	for i, match := range matches {
+		entry := match.entry
+		if len(q.Fields) > 0 {
+			filtered := map[string]interface{}{"symbol": entry["symbol"]}
+			for _, field := range q.Fields {
+				if value, ok := entry[field]; ok {
+					filtered[field] = value
+				}
+			}
+			entry = filtered
+		}
+		entry["rank"] = q.Offset + i + 1
+		entries = append(entries, entry)
+	}
+	return entries, total
+}
+
+// ScreenerHandler serves a ranked and filtered list of the live feed
+// entries at /api/1/binance/screener. With the at parameter the most
+// recent snapshot taken at or before that time is screened instead.
+type ScreenerHandler struct {
+	binanceRunner *BinanceRunner
+	snapshots     *db.SnapshotStore
+}
+
+func NewScreenerHandler(binanceRunner *BinanceRunner, snapshots *db.SnapshotStore) *ScreenerHandler {
+	return &ScreenerHandler{
+		binanceRunner: binanceRunner,
+		snapshots:     snapshots,
+	}
+}
+
+func (h *ScreenerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query, err := ParseScreenerQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := ScreenerResponse{
+		Offset: query.Offset,
+		Limit:  query.Limit,
+	}
+	if at := r.FormValue("at"); at != "" {
+		snapshot, status, err := loadSnapshotParam(h.snapshots, at)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		response.Results, response.Total = query.RunSnapshot(snapshot)
+		response.Timestamp = &snapshot.Timestamp
+	} else {
+		trackers := h.binanceRunner.GetCache()
+		response.Results, response.Total = query.Run(&trackers)
+	}
+	response.Count = len(response.Results)
+
+	w.Header().Add("content-type", "application/json")
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		log.WithError(err).WithField("handler", "screener").
+			Errorf("Failed to encode response to JSON")
+	}
+}
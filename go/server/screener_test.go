@@ -0,0 +1,157 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseScreenerFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected ScreenerFilter
+		err      bool
+	}{
+		{"nv_15>1000", ScreenerFilter{"nv_15", ">", 1000}, false},
+		{"nv_15>=1000", ScreenerFilter{"nv_15", ">=", 1000}, false},
+		{"rsi_1h <= 30.5", ScreenerFilter{"rsi_1h", "<=", 30.5}, false},
+		{"wt_15!=0", ScreenerFilter{"wt_15", "!=", 0}, false},
+		{"trades_2h=-1", ScreenerFilter{"trades_2h", "=", -1}, false},
+		{"volume", ScreenerFilter{}, true},
+		{">1000", ScreenerFilter{}, true},
+		{"volume>abc", ScreenerFilter{}, true},
+	}
+	for _, test := range tests {
+		filter, err := ParseScreenerFilter(test.filter)
+		if (err != nil) != test.err {
+			t.Errorf("%q: unexpected error %v", test.filter, err)
+			continue
+		}
+		if filter != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.filter, test.expected, filter)
+		}
+	}
+}
+
+func TestParseScreenerQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *ScreenerQuery
+	}{
+		{"", &ScreenerQuery{Sort: "volume", Limit: screenerDefaultLimit,
+			Fields: []string{}}},
+		{"sort=nv_15&order=asc&limit=10&offset=20", &ScreenerQuery{
+			Sort: "nv_15", Ascending: true, Limit: 10, Offset: 20,
+			Fields: []string{}}},
+		{"quote=btc,usdt&min_volume=5&fields=close,nv_15", &ScreenerQuery{
+			Sort: "volume", Limit: screenerDefaultLimit,
+			QuoteAssets: []string{"BTC", "USDT"}, MinVolume: 5,
+			Fields: []string{"close", "nv_15"}}},
+		{"filter=nv_15>1,wt_15>0&filter=trades_2h<10", &ScreenerQuery{
+			Sort: "volume", Limit: screenerDefaultLimit, Fields: []string{},
+			Filters: []ScreenerFilter{
+				{"nv_15", ">", 1},
+				{"wt_15", ">", 0},
+				{"trades_2h", "<", 10},
+			}}},
+		{"order=up", nil},
+		{"limit=0", nil},
+		{"limit=1001", nil},
+		{"offset=-1", nil},
+		{"min_volume=x", nil},
+		{"filter=nv_15", nil},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/api/1/binance/screener?"+test.query, nil)
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		query, err := ParseScreenerQuery(r)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(query, test.expected) {
+			t.Errorf("%q: expected %+v, got %+v", test.query, test.expected, query)
+		}
+	}
+}
+
+// TestScreenerUintFields filters and ranks on the uint64 trade counts.
+func TestScreenerUintFields(t *testing.T) {
+	snapshot := &MetricSnapshot{
+		Tickers: []map[string]interface{}{
+			{"symbol": "ETHBTC", "quote": "BTC", "volume": 10.0,
+				"wt_15": uint64(3), "trades_2h": uint64(100)},
+			{"symbol": "LTCBTC", "quote": "BTC", "volume": 20.0,
+				"wt_15": uint64(0), "trades_2h": uint64(300)},
+			{"symbol": "XRPBTC", "quote": "BTC", "volume": 30.0,
+				"trades_2h": uint64(200)},
+			{"symbol": "BNBUSDT", "quote": "USDT", "volume": 40,
+				"wt_15": uint32(1), "trades_2h": uint32(50)},
+		},
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"sort=trades_2h", []string{"LTCBTC", "XRPBTC", "ETHBTC", "BNBUSDT"}},
+		{"sort=trades_2h&order=asc", []string{"BNBUSDT", "ETHBTC", "XRPBTC", "LTCBTC"}},
+		// Entries without the sort field are ranked last.
+		{"sort=wt_15", []string{"ETHBTC", "BNBUSDT", "LTCBTC", "XRPBTC"}},
+		{"filter=wt_15>0", []string{"BNBUSDT", "ETHBTC"}},
+		{"filter=trades_2h>=200&sort=volume&order=asc", []string{"LTCBTC", "XRPBTC"}},
+		{"filter=wt_15=0", []string{"LTCBTC"}},
+		{"min_volume=25", []string{"BNBUSDT", "XRPBTC"}},
+		{"quote=btc&sort=trades_2h&limit=1&offset=1", []string{"XRPBTC"}},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/api/1/binance/screener?"+test.query, nil)
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		query, err := ParseScreenerQuery(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, _ := query.RunSnapshot(snapshot)
+		symbols := []string{}
+		for _, result := range results {
+			symbols = append(symbols, result["symbol"].(string))
+		}
+		if !reflect.DeepEqual(symbols, test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.query, test.expected, symbols)
+		}
+	}
+}
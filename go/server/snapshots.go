@@ -0,0 +1,278 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/db"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Snapshots of the live feed entries of every symbol are persisted at a
+// fixed interval, so the screener can be run on the market as it was at
+// a past time, for time travel in the UI and for offline research.
+
+type SnapshotOptions struct {
+	// Time between snapshots, 0 to disable them. Snapshots are aligned
+	// to multiples of the interval.
+	Interval time.Duration
+
+	// How long snapshots are kept.
+	Retention time.Duration
+}
+
+var DefaultSnapshotOptions = SnapshotOptions{
+	Interval:  5 * time.Minute,
+	Retention: 7 * 24 * time.Hour,
+}
+
+// MetricSnapshot is the live feed entries of every symbol at one time,
+// ordered by symbol. The entries also have the quote asset under quote.
+type MetricSnapshot struct {
+	Timestamp time.Time                `json:"timestamp" doc:"Time the snapshot was taken."`
+	Tickers   []map[string]interface{} `json:"tickers" doc:"Entries of the live feed with their quote asset, ordered by symbol."`
+}
+
+// NewMetricSnapshot builds a snapshot of the trackers. Snapshots are
+// stored by the second, so the time is truncated to match.
+func NewMetricSnapshot(trackers *TickerTrackerMap, timestamp time.Time) *MetricSnapshot {
+	snapshot := &MetricSnapshot{
+		Timestamp: timestamp.Truncate(time.Second),
+		Tickers:   []map[string]interface{}{},
+	}
+	for _, tracker := range trackers.Trackers {
+		entry := WsBuildCompleteEntry(tracker)
+		if entry == nil {
+			continue
+		}
+		entry["quote"] = tracker.QuoteAsset
+		snapshot.Tickers = append(snapshot.Tickers, entry)
+	}
+	sort.Slice(snapshot.Tickers, func(i, j int) bool {
+		return snapshot.Tickers[i]["symbol"].(string) < snapshot.Tickers[j]["symbol"].(string)
+	})
+	return snapshot
+}
+
+// Snapshotter saves a snapshot of the trackers once per interval.
+type Snapshotter struct {
+	options SnapshotOptions
+	store   *db.SnapshotStore
+
+	// Start of the interval of the last snapshot.
+	last time.Time
+}
+
+func NewSnapshotter(options SnapshotOptions, store *db.SnapshotStore) *Snapshotter {
+	return &Snapshotter{
+		options: options,
+		store:   store,
+	}
+}
+
+func (s *Snapshotter) Run(channel chan *TickerTrackerMap) {
+	for trackers := range channel {
+		now := time.Now()
+		if !s.due(now) {
+			continue
+		}
+
+		// The entries are built before the next update, encoding and
+		// saving them can take a while so it's left to a goroutine.
+		snapshot := NewMetricSnapshot(trackers, now)
+		go s.save(snapshot)
+	}
+}
+
+// due returns true for the first time in each interval, which the
+// snapshot is then taken at.
+func (s *Snapshotter) due(now time.Time) bool {
+	start := now.Truncate(s.options.Interval)
+	if !start.After(s.last) {
+		return false
+	}
+	s.last = start
+	return true
+}
+
+func (s *Snapshotter) save(snapshot *MetricSnapshot) {
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to encode snapshot")
+		return
+	}
+	if err := s.store.Save(snapshot.Timestamp, body); err != nil {
+		log.WithError(err).Errorf("Failed to save snapshot")
+	}
+}
+
+// parseTimeParam parses a time in unix seconds or RFC 3339.
+func parseTimeParam(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", value)
+	}
+	return t, nil
+}
+
+// loadSnapshotParam loads the most recent snapshot taken at or before the
+// time at. On error the HTTP status to respond with is returned too.
+func loadSnapshotParam(store *db.SnapshotStore, at string) (*MetricSnapshot, int, error) {
+	if store == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("snapshots are disabled")
+	}
+	t, err := parseTimeParam(at)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	_, body, err := store.LoadAt(t)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to load snapshot")
+		return nil, http.StatusInternalServerError,
+			errors.New(http.StatusText(http.StatusInternalServerError))
+	}
+	if body == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("no snapshot at or before %v", t.UTC())
+	}
+	snapshot := &MetricSnapshot{}
+	if err := json.Unmarshal(body, snapshot); err != nil {
+		log.WithError(err).Errorf("Failed to decode snapshot")
+		return nil, http.StatusInternalServerError,
+			errors.New(http.StatusText(http.StatusInternalServerError))
+	}
+	return snapshot, 0, nil
+}
+
+// SnapshotListHandler serves the times of the stored snapshots at
+// /api/1/binance/snapshots. Parameters: since and until, unix seconds or
+// RFC 3339, default the last 24 hours.
+type SnapshotListHandler struct {
+	options SnapshotOptions
+	store   *db.SnapshotStore
+}
+
+func NewSnapshotListHandler(options SnapshotOptions, store *db.SnapshotStore) *SnapshotListHandler {
+	return &SnapshotListHandler{
+		options: options,
+		store:   store,
+	}
+}
+
+func (h *SnapshotListHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	response := SnapshotListResponse{
+		Interval:   int64(h.options.Interval / time.Second),
+		Timestamps: []time.Time{},
+	}
+	if h.store != nil {
+		until := time.Now()
+		since := until.Add(-time.Hour * 24)
+		for param, value := range map[string]*time.Time{
+			"since": &since,
+			"until": &until,
+		} {
+			if r.FormValue(param) == "" {
+				continue
+			}
+			t, err := parseTimeParam(r.FormValue(param))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			*value = t
+		}
+		timestamps, err := h.store.Times(since, until)
+		if err != nil {
+			log.WithError(err).WithField("handler", "snapshots").
+				Errorf("Failed to query snapshots")
+			http.Error(w, http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError)
+			return
+		}
+		response.Timestamps = timestamps
+	}
+
+	w.Header().Add("content-type", "application/json")
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		log.WithError(err).WithField("handler", "snapshots").
+			Errorf("Failed to encode response to JSON")
+	}
+}
+
+// SnapshotHandler serves the most recent snapshot taken at or before the
+// time given by the at parameter at /api/1/binance/snapshot. The fields
+// parameter limits the metrics of the entries.
+type SnapshotHandler struct {
+	store *db.SnapshotStore
+}
+
+func NewSnapshotHandler(store *db.SnapshotStore) *SnapshotHandler {
+	return &SnapshotHandler{
+		store: store,
+	}
+}
+
+func (h *SnapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	at := r.FormValue("at")
+	if at == "" {
+		http.Error(w, "missing at", http.StatusBadRequest)
+		return
+	}
+	snapshot, status, err := loadSnapshotParam(h.store, at)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if fields := splitParam(r.FormValue("fields")); len(fields) > 0 {
+		for i, entry := range snapshot.Tickers {
+			filtered := map[string]interface{}{
+				"symbol": entry["symbol"],
+				"quote":  entry["quote"],
+			}
+			for _, field := range fields {
+				if value, ok := entry[field]; ok {
+					filtered[field] = value
+				}
+			}
+			snapshot.Tickers[i] = filtered
+		}
+	}
+
+	w.Header().Add("content-type", "application/json")
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(snapshot); err != nil {
+		log.WithError(err).WithField("handler", "snapshot").
+			Errorf("Failed to encode response to JSON")
+	}
+}
@@ -0,0 +1,148 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"gitlab.com/crankykernel/cryptoxscanner/db"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSnapshotterDue(t *testing.T) {
+	snapshotter := NewSnapshotter(SnapshotOptions{Interval: 5 * time.Minute}, nil)
+	start := time.Date(2019, 2, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at  time.Duration
+		due bool
+	}{
+		{3 * time.Minute, true},
+		{4*time.Minute + 59*time.Second, false},
+		{5 * time.Minute, true},
+		{5*time.Minute + time.Second, false},
+		{9 * time.Minute, false},
+		// Late in the next interval, and early in the one after.
+		{14 * time.Minute, true},
+		{15 * time.Minute, true},
+		{15 * time.Minute, false},
+	}
+	for _, test := range tests {
+		if due := snapshotter.due(start.Add(test.at)); due != test.due {
+			t.Errorf("%v: expected due %v, got %v", test.at, test.due, due)
+		}
+	}
+}
+
+// openTestSnapshotStore opens a snapshot store in a temporary directory.
+func openTestSnapshotStore(t *testing.T) *db.SnapshotStore {
+	t.Chdir(t.TempDir())
+	store, err := db.OpenSnapshotStore("snapshots", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	store := openTestSnapshotStore(t)
+	snapshotter := NewSnapshotter(DefaultSnapshotOptions, store)
+	now := time.Now().Truncate(time.Second)
+	snapshots := []*MetricSnapshot{}
+	for i, close := range []float64{0.03, 0.04} {
+		snapshot := &MetricSnapshot{
+			Timestamp: now.Add(time.Duration(i-1) * time.Minute),
+			Tickers: []map[string]interface{}{
+				{"symbol": "ETHBTC", "quote": "BTC", "close": close},
+			},
+		}
+		snapshotter.save(snapshot)
+		snapshots = append(snapshots, snapshot)
+	}
+
+	tests := []struct {
+		at       time.Time
+		expected *MetricSnapshot
+	}{
+		{snapshots[0].Timestamp, snapshots[0]},
+		{snapshots[0].Timestamp.Add(30 * time.Second), snapshots[0]},
+		{snapshots[1].Timestamp, snapshots[1]},
+		{now.Add(time.Hour), snapshots[1]},
+	}
+	for _, test := range tests {
+		snapshot, _, err := loadSnapshotParam(store, strconv.FormatInt(test.at.Unix(), 10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !snapshot.Timestamp.Equal(test.expected.Timestamp) ||
+			!reflect.DeepEqual(snapshot.Tickers, test.expected.Tickers) {
+			t.Errorf("%v: expected %+v, got %+v", test.at, test.expected, snapshot)
+		}
+	}
+
+	times, err := store.Times(now.Add(-time.Hour), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 2 || !times[0].Equal(snapshots[0].Timestamp) {
+		t.Errorf("expected the times of both snapshots, got %v", times)
+	}
+}
+
+func TestLoadSnapshotParam(t *testing.T) {
+	store := openTestSnapshotStore(t)
+	now := time.Now().Truncate(time.Second)
+	if err := store.Save(now.Add(-time.Minute), []byte(`{"tickers": []}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(now, []byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		store  *db.SnapshotStore
+		at     string
+		status int
+	}{
+		{"ok", store, strconv.FormatInt(now.Add(-time.Minute).Unix(), 10), 0},
+		{"rfc 3339", store, now.Add(-time.Second).Format(time.RFC3339), 0},
+		{"disabled", nil, strconv.FormatInt(now.Unix(), 10), http.StatusNotFound},
+		{"invalid time", store, "yesterday", http.StatusBadRequest},
+		{"before the first", store, "1", http.StatusNotFound},
+		{"invalid body", store, strconv.FormatInt(now.Unix(), 10), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		snapshot, status, err := loadSnapshotParam(test.store, test.at)
+		if status != test.status || (err == nil) != (test.status == 0) {
+			t.Errorf("%s: expected status %d, got %d %v", test.name, test.status, status, err)
+			continue
+		}
+		if err == nil && snapshot == nil {
+			t.Errorf("%s: expected a snapshot", test.name)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The feeds are also served as Server-Sent Events for clients behind
+// proxies that break WebSockets. The events carry the same JSON as the
+// feed sockets, or in delta mode the same channel and delta messages,
+// with the feed seq as the event id.
+
+// Interval of the comments sent to keep idle connections open through
+// proxies.
+const sseKeepAliveInterval = time.Second * 15
+
+// How long a write may take before the client is taken as gone, as for the
+// feed sockets.
+const sseWriteTimeout = time.Second * 6
+
+// SseHandler serves a feed as Server-Sent Events. It takes the same
+// updateInterval and delta parameters as the feed sockets. A client
+// reconnecting with Last-Event-ID is sent the deltas it missed in delta
+// mode, if they are still kept, otherwise the latest update.
+type SseHandler struct {
+	source *WsSourceCache
+}
+
+func NewSseHandler(source *WsSourceCache) *SseHandler {
+	return &SseHandler{
+		source: source,
+	}
+}
+
+// NewStreamClient returns a client without a WebSocket connection, so
+// streams over other transports can use the send queues and be listed
+// with the WebSocket clients.
+func NewStreamClient(r *http.Request, encoding WsEncoding) *WebSocketClient {
+	client := &WebSocketClient{
+		id:           nextWsClientId(),
+		closeChannel: make(chan bool, 1),
+		r:            r,
+		encoding:     encoding,
+	}
+	client.queue = client.newQueue(wsQueueOptions)
+	return client
+}
+
+func (h *SseHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if _, ok := w.(http.Flusher); !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	updateInterval, err := strconv.ParseInt(r.FormValue("updateInterval"), 10, 64)
+	if err != nil {
+		updateInterval = 0
+	}
+	lastUpdate := time.Time{}
+
+	// EventSource sends the header when reconnecting, the parameter is
+	// for clients that have to reconnect themselves.
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.FormValue("lastEventId")
+	}
+	lastSeq, err := strconv.ParseUint(lastEventId, 10, 64)
+	if err != nil {
+		lastSeq = 0
+	}
+
+	var deltaState *wsDeltaState
+	if r.FormValue("delta") != "" && r.FormValue("delta") != "0" {
+		deltaState = &wsDeltaState{seq: lastSeq}
+	}
+
+	client := NewStreamClient(r, WsEncodingJSON)
+	log.Infof("Server-Sent Events connected to %s: RemoteAddr=%v",
+		r.URL.String(), client.GetRemoteAddr())
+	wsConnectionTracker.Add(r.URL.String(), client)
+	defer wsConnectionTracker.Del(r.URL.String(), client)
+
+	queue := client.queue
+	h.source.Subscribe(queue, "")
+	defer h.source.Unsubscribe(queue, "")
+
+	w.Header().Set("content-type", "text/event-stream")
+	w.Header().Set("cache-control", "no-cache")
+	w.Header().Set("x-accel-buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := sseFlush(w); err != nil {
+		goto Done
+	}
+
+	// Catch up right away rather than waiting for the next update. The
+	// latest update may not have JSON if there were no JSON subscribers
+	// when it was built, then the next one is waited for.
+	if last := h.source.Last(); last != nil && last.Seq != lastSeq {
+		if err := h.writeUpdate(w, last, deltaState); err != nil {
+			goto Done
+		}
+		lastUpdate = time.Now()
+		if err := sseFlush(w); err != nil {
+			goto Done
+		}
+	}
+
+	{
+		keepAlive := time.NewTicker(sseKeepAliveInterval)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-queue.Ready():
+				for item, ok := queue.Pop(); ok; item, ok = queue.Pop() {
+					if time.Now().Sub(lastUpdate) < time.Second*time.Duration(updateInterval) {
+						continue
+					}
+					if err := h.writeUpdate(w, item.update, deltaState); err != nil {
+						goto Done
+					}
+					lastUpdate = time.Now()
+				}
+				if err := sseFlush(w); err != nil {
+					goto Done
+				}
+			case <-keepAlive.C:
+				if err := sseWrite(w, ": keep-alive\n\n"); err != nil {
+					goto Done
+				}
+				if err := sseFlush(w); err != nil {
+					goto Done
+				}
+			case <-client.closeChannel:
+				goto Done
+			case <-r.Context().Done():
+				goto Done
+			}
+		}
+	}
+Done:
+	log.Infof("Server-Sent Events connection closed: %v", client.GetRemoteAddr())
+}
+
+func (h *SseHandler) writeUpdate(w http.ResponseWriter, update *WsSourceUpdate, deltaState *wsDeltaState) error {
+	payload := update.Payload
+	if deltaState != nil {
+		delta, snapshot := deltaState.next(h.source, update)
+		switch {
+		case snapshot:
+			payload = update.Payload
+		case delta == nil:
+			return nil
+		case delta == update.Delta:
+			payload = update.DeltaPayload
+		default:
+			var err error
+			if payload, err = json.Marshal(delta); err != nil {
+				return err
+			}
+		}
+	}
+	if payload == nil {
+		// Built before this client subscribed.
+		return nil
+	}
+	return sseWrite(w, "id: %d\ndata: %s\n\n", update.Seq, payload)
+}
+
+// setSseWriteDeadline sets the deadline of the next writes to the client,
+// if the connection supports it.
+func setSseWriteDeadline(w http.ResponseWriter) error {
+	err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// sseWrite writes to the client within the write timeout. An error,
+// including a timeout of a client that stopped reading, is a disconnect.
+func sseWrite(w http.ResponseWriter, format string, args ...interface{}) error {
+	if err := setSseWriteDeadline(w); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, format, args...)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to write Server-Sent Events message")
+	}
+	return err
+}
+
+// sseFlush flushes the buffered writes to the client within the write
+// timeout.
+func sseFlush(w http.ResponseWriter) error {
+	if err := setSseWriteDeadline(w); err != nil {
+		return err
+	}
+	err := http.NewResponseController(w).Flush()
+	if err != nil {
+		log.WithError(err).Errorf("Failed to flush Server-Sent Events message")
+	}
+	return err
+}
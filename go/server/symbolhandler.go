@@ -0,0 +1,258 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Sections of the symbol endpoint response.
+var symbolSections = []string{"metrics", "histograms", "ticks", "candles", "trades"}
+
+// Default and maximum number of ticks, candles per interval and trades in
+// a symbol endpoint response.
+const symbolDefaultLimit = 60
+const symbolMaxLimit = 1000
+
+// SymbolHandler serves the state of one tracker at
+// /api/1/binance/symbol/{symbol}. Parameters:
+//
+//	include: comma separated sections, default all
+//	fields: comma separated metrics to include, default all
+//	ticks, candles, trades: number of the most recent to include
+type SymbolHandler struct {
+	binanceRunner *BinanceRunner
+}
+
+func NewSymbolHandler(binanceRunner *BinanceRunner) *SymbolHandler {
+	return &SymbolHandler{
+		binanceRunner: binanceRunner,
+	}
+}
+
+func splitParam(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func limitParam(r *http.Request, name string) (int, bool) {
+	value := r.FormValue(name)
+	if value == "" {
+		return symbolDefaultLimit, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 || limit > symbolMaxLimit {
+		return 0, false
+	}
+	return limit, true
+}
+
+func (h *SymbolHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(mux.Vars(r)["symbol"])
+	tracker := h.binanceRunner.GetTracker(symbol)
+	if tracker == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	include := map[string]bool{}
+	for _, section := range symbolSections {
+		include[section] = r.FormValue("include") == ""
+	}
+	for _, section := range splitParam(r.FormValue("include")) {
+		if _, ok := include[section]; !ok {
+			http.Error(w, "unknown section: "+section, http.StatusBadRequest)
+			return
+		}
+		include[section] = true
+	}
+
+	limits := map[string]int{}
+	for _, name := range []string{"ticks", "candles", "trades"} {
+		limit, ok := limitParam(r, name)
+		if !ok {
+			http.Error(w, "invalid "+name, http.StatusBadRequest)
+			return
+		}
+		limits[name] = limit
+	}
+
+	// The runner updates the tracker as trades arrive, so the response is
+	// built under its read lock and encoded after.
+	tracker.lock.RLock()
+	if tracker.LastTick() == nil {
+		tracker.lock.RUnlock()
+		http.NotFound(w, r)
+		return
+	}
+	response := map[string]interface{}{
+		"symbol": tracker.Symbol,
+		"base":   tracker.BaseAsset,
+		"quote":  tracker.QuoteAsset,
+	}
+	if include["metrics"] {
+		response["metrics"] = symbolMetrics(tracker, splitParam(r.FormValue("fields")))
+	}
+	if include["histograms"] {
+		response["histograms"] = symbolHistograms(tracker)
+	}
+	if include["ticks"] {
+		response["ticks"] = symbolTicks(tracker, limits["ticks"])
+	}
+	if include["candles"] {
+		response["candles"] = symbolCandles(tracker, limits["candles"])
+	}
+	if include["trades"] {
+		response["trades"] = symbolTrades(tracker, limits["trades"])
+	}
+	tracker.lock.RUnlock()
+
+	w.Header().Add("content-type", "application/json")
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		log.WithError(err).WithField("handler", "symbol").
+			Errorf("Failed to encode response to JSON")
+	}
+}
+
+// symbolMetrics returns the entry of the live feed, limited to fields if
+// any are given.
+func symbolMetrics(tracker *TickerTracker, fields []string) map[string]interface{} {
+	entry := NewCompleteEntry(tracker).Map()
+	if len(fields) == 0 {
+		return entry
+	}
+	filtered := map[string]interface{}{}
+	for _, field := range fields {
+		if value, ok := entry[field]; ok {
+			filtered[field] = value
+		}
+	}
+	return filtered
+}
+
+func normalizedHistogramMap(h NormalizedHistogram) map[string]interface{} {
+	return map[string]interface{}{
+		"volume":      h.Volume,
+		"buy_volume":  h.BuyVolume,
+		"sell_volume": h.SellVolume,
+		"net_volume":  h.NetVolume,
+		"volume_24h":  h.Volume24,
+	}
+}
+
+// symbolHistograms returns the per minute histograms, index 0 being the
+// current minute.
+func symbolHistograms(tracker *TickerTracker) map[string]interface{} {
+	return map[string]interface{}{
+		"trades":      tracker.Histogram.TradeCount,
+		"buy_trades":  tracker.Histogram.BuyTradeCount,
+		"sell_trades": tracker.Histogram.SellTradeCount,
+		"volume":      tracker.Histogram.Volume,
+		"buy_volume":  tracker.Histogram.BuyVolume,
+		"sell_volume": tracker.Histogram.SellVolume,
+		"net_volume":  tracker.Histogram.NetVolume,
+		"volume_24h":  tracker.Histogram.Volume24,
+		"usd":         normalizedHistogramMap(tracker.Histogram.USD),
+		"btc":         normalizedHistogramMap(tracker.Histogram.BTC),
+	}
+}
+
+func symbolTicks(tracker *TickerTracker, limit int) []map[string]interface{} {
+	ticks := tracker.Ticks
+	if len(ticks) > limit {
+		ticks = ticks[len(ticks)-limit:]
+	}
+	entries := []map[string]interface{}{}
+	for _, tick := range ticks {
+		entries = append(entries, map[string]interface{}{
+			"timestamp":        tick.Timestamp(),
+			"close":            tick.CurrentDayClose,
+			"bid":              tick.Bid,
+			"ask":              tick.Ask,
+			"high":             tick.HighPrice,
+			"low":              tick.LowPrice,
+			"volume":           tick.TotalQuoteVolume,
+			"price_change_pct": tick.PriceChangePercent,
+		})
+	}
+	return entries
+}
+
+// symbolCandles returns the aggregates of each bucket, keyed by bucket
+// label.
+func symbolCandles(tracker *TickerTracker, limit int) map[string]interface{} {
+	candles := map[string]interface{}{}
+	for _, bucket := range Buckets {
+		aggs := tracker.Aggs[bucket]
+		if len(aggs) > limit {
+			aggs = aggs[len(aggs)-limit:]
+		}
+		entries := []map[string]interface{}{}
+		for _, agg := range aggs {
+			entries = append(entries, map[string]interface{}{
+				"time":  agg.Time,
+				"open":  agg.Open,
+				"high":  agg.High,
+				"low":   agg.Low,
+				"close": agg.Close,
+			})
+		}
+		candles[bucketLabel(bucket)] = entries
+	}
+	return candles
+}
+
+func symbolTrades(tracker *TickerTracker, limit int) []map[string]interface{} {
+	trades := tracker.Trades
+	if len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+	entries := []map[string]interface{}{}
+	for _, trade := range trades {
+		side := "buy"
+		if trade.BuyerMaker {
+			side = "sell"
+		}
+		entries = append(entries, map[string]interface{}{
+			"id":        trade.TradeID,
+			"timestamp": trade.Timestamp(),
+			"price":     trade.Price,
+			"quantity":  trade.Quantity,
+			"side":      side,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,144 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSymbolCandles(t *testing.T) {
+	start := time.Date(2019, 2, 15, 12, 0, 0, 0, time.UTC)
+	tracker := NewTickerTracker("ETHBTC")
+	for _, trade := range []struct {
+		offset time.Duration
+		price  float64
+	}{
+		{10 * time.Second, 1},
+		{50 * time.Second, 3},
+		{90 * time.Second, 2},
+		{130 * time.Second, 5},
+		{185 * time.Second, 4},
+	} {
+		tracker.AddTrade(binanceapi.StreamAggTrade{
+			Symbol:    "ETHBTC",
+			Price:     trade.price,
+			Quantity:  1,
+			TradeTime: milliseconds(start.Add(trade.offset)),
+		})
+	}
+
+	type candle struct {
+		minute                 int
+		open, high, low, close float64
+	}
+	tests := []struct {
+		label    string
+		limit    int
+		expected []candle
+	}{
+		{"1m", 10, []candle{{0, 1, 3, 1, 3}, {1, 3, 2, 2, 2}, {2, 2, 5, 5, 5}, {3, 5, 4, 4, 4}}},
+		{"1m", 2, []candle{{2, 2, 5, 5, 5}, {3, 5, 4, 4, 4}}},
+		{"2m", 10, []candle{{0, 1, 3, 1, 2}, {2, 2, 5, 4, 4}}},
+		{"5m", 10, []candle{{0, 1, 5, 1, 4}}},
+		{"5m", 0, []candle{}},
+	}
+	for _, test := range tests {
+		candles := symbolCandles(tracker, test.limit)[test.label].([]map[string]interface{})
+		if len(candles) != len(test.expected) {
+			t.Errorf("%s limit %d: expected %d candles, got %d",
+				test.label, test.limit, len(test.expected), len(candles))
+			continue
+		}
+		for i, expected := range test.expected {
+			got := candles[i]
+			if !got["time"].(time.Time).Equal(start.Add(time.Duration(expected.minute)*time.Minute)) ||
+				got["open"] != expected.open || got["high"] != expected.high ||
+				got["low"] != expected.low || got["close"] != expected.close {
+				t.Errorf("%s limit %d candle %d: expected %+v, got %v",
+					test.label, test.limit, i, expected, got)
+			}
+		}
+	}
+}
+
+// TestSymbolHandlerConcurrentTrades requests a symbol while the runner
+// adds trades and recalculates, for go test -race.
+func TestSymbolHandlerConcurrentTrades(t *testing.T) {
+	runner := NewBinanceRunner(nil, nil)
+	router := mux.NewRouter()
+	router.Handle("/api/1/binance/symbol/{symbol}", NewSymbolHandler(runner))
+
+	now := time.Now()
+	tracker := runner.trackers.GetTracker("ETHBTC")
+	tracker.Update(binanceapi.TickerStreamMessage{
+		Symbol:          "ETHBTC",
+		EventTime:       milliseconds(now),
+		CurrentDayClose: 0.03,
+	})
+
+	done := make(chan bool)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			timestamp := now.Add(time.Duration(i) * time.Second)
+			tracker.AddTrade(binanceapi.StreamAggTrade{
+				Symbol:    "ETHBTC",
+				Price:     0.03 + float64(i%10)*0.0001,
+				Quantity:  1,
+				TradeTime: milliseconds(timestamp),
+			})
+			if i%10 == 0 {
+				tracker.RecalculateAt(timestamp)
+				runner.trackers.GetTracker("XRPBTC")
+				runner.trackers.Prune(func(symbol string) bool {
+					return symbol == "ETHBTC"
+				})
+			}
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest("GET", "/api/1/binance/symbol/ETHBTC", nil))
+		if response.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", response.Code)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,356 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"time"
+)
+
+// TickerSchemaVersion is the version of the ticker entry format and is sent
+// in the version field of each TickerStream. Version 2 names the RSI fields
+// after their bucket in minutes, rsi_1 rather than rsi_60. Version 3 sends
+// MessagePack clients the same entries, see WsEncoding. Version 4 names the
+// RSI fields after the bucket label, rsi_1m and rsi_1h, as rsi_60 was the
+// one minute RSI before version 2 and the hour RSI in versions 2 and 3.
+const TickerSchemaVersion = 4
+
+// MonitorEntry is an entry of the monitor feed. It is also the common part
+// of the entries of the live and symbol feeds. The msgpack tags name the
+// fields for MessagePack clients and match the JSON names.
+type MonitorEntry struct {
+	Symbol    string   `json:"symbol" msgpack:"symbol" doc:"Symbol, for example ETHBTC."`
+	Close     float64  `json:"close" msgpack:"close" doc:"Last price."`
+	Bid       float64  `json:"bid" msgpack:"bid" doc:"Best bid price."`
+	Ask       float64  `json:"ask" msgpack:"ask" doc:"Best ask price."`
+	High      float64  `json:"high" msgpack:"high" doc:"24 hour high price."`
+	Low       float64  `json:"low" msgpack:"low" doc:"24 hour low price."`
+	Volume    float64  `json:"volume" msgpack:"volume" doc:"24 hour volume in the quote asset."`
+	VolumeUSD *float64 `json:"volume_usd,omitempty" msgpack:"volume_usd,omitempty" doc:"24 hour volume in USD, if a conversion rate for the quote asset is known."`
+	VolumeBTC *float64 `json:"volume_btc,omitempty" msgpack:"volume_btc,omitempty" doc:"24 hour volume in BTC, if a conversion rate for the quote asset is known."`
+
+	PriceChangePercent  map[string]float64 `json:"price_change_pct" msgpack:"price_change_pct" doc:"Price change in percent keyed by bucket, for example 5m or 1h, and 24h."`
+	VolumeChangePercent map[string]float64 `json:"volume_change_pct" msgpack:"volume_change_pct" doc:"Volume change in percent keyed by bucket, for example 5m or 1h."`
+
+	Timestamp time.Time `json:"timestamp" msgpack:"timestamp" doc:"Time of the last ticker update."`
+}
+
+// CompleteEntry is an entry of the live and symbol feeds.
+type CompleteEntry struct {
+	MonitorEntry
+
+	Range24        float64 `json:"r_24" msgpack:"r_24" doc:"24 hour price range."`
+	RangePercent24 float64 `json:"rp_24" msgpack:"rp_24" doc:"24 hour price range in percent of the low."`
+
+	PumpScore float64 `json:"pump_score" msgpack:"pump_score" doc:"Pump score from 0 to 100 over the last minute."`
+
+	// Metrics keyed by bucket in minutes.
+	Buckets map[int]*CompleteBucketEntry `json:"-" msgpack:"buckets"`
+
+	// Volume profiles keyed by window in minutes.
+	Profiles map[int]*CompleteProfileEntry `json:"-" msgpack:"profiles"`
+
+	// Rolling metrics keyed by window in minutes.
+	Rolling map[int]*CompleteRollingEntry `json:"-" msgpack:"rolling"`
+}
+
+// CompleteBucketEntry holds the metrics of a CompleteEntry for one bucket.
+// They are sent flattened into the entry with the bucket in minutes
+// formatted into the key, l_1, l_2 and so on, or to MessagePack clients
+// under buckets by the name without the bucket. Fields that require trades
+// or a conversion rate are left out until available.
+type CompleteBucketEntry struct {
+	Low          float64 `bucket:"l_%d" msgpack:"l" doc:"Low price."`
+	High         float64 `bucket:"h_%d" msgpack:"h" doc:"High price."`
+	Range        float64 `bucket:"r_%d" msgpack:"r" doc:"Price range."`
+	RangePercent float64 `bucket:"rp_%d" msgpack:"rp" doc:"Price range in percent of the low."`
+
+	Vwap        *float64 `bucket:"vwap_%dm" msgpack:"vwap,omitempty" doc:"Volume weighted average price."`
+	VwapStdDev  *float64 `bucket:"vwap_sd_%dm" msgpack:"vwap_sd,omitempty" doc:"Volume weighted standard deviation of the price."`
+	VwapUpper   *float64 `bucket:"vwap_upper_%dm" msgpack:"vwap_upper,omitempty" doc:"Upper VWAP band, two standard deviations above the VWAP."`
+	VwapLower   *float64 `bucket:"vwap_lower_%dm" msgpack:"vwap_lower,omitempty" doc:"Lower VWAP band, two standard deviations below the VWAP."`
+	TotalVolume *float64 `bucket:"total_volume_%d" msgpack:"total_volume,omitempty" doc:"Traded volume in the quote asset."`
+	NetVolume   *float64 `bucket:"nv_%d" msgpack:"nv,omitempty" doc:"Buy volume less sell volume in the quote asset."`
+	BuyVolume   *float64 `bucket:"bv_%d" msgpack:"bv,omitempty" doc:"Buy volume in the quote asset."`
+	SellVolume  *float64 `bucket:"sv_%d" msgpack:"sv,omitempty" doc:"Sell volume in the quote asset."`
+
+	TotalVolumeUSD *float64 `bucket:"total_volume_usd_%d" msgpack:"total_volume_usd,omitempty" doc:"Traded volume in USD."`
+	NetVolumeUSD   *float64 `bucket:"nv_usd_%d" msgpack:"nv_usd,omitempty" doc:"Buy volume less sell volume in USD."`
+	BuyVolumeUSD   *float64 `bucket:"bv_usd_%d" msgpack:"bv_usd,omitempty" doc:"Buy volume in USD."`
+	SellVolumeUSD  *float64 `bucket:"sv_usd_%d" msgpack:"sv_usd,omitempty" doc:"Sell volume in USD."`
+
+	TotalVolumeBTC *float64 `bucket:"total_volume_btc_%d" msgpack:"total_volume_btc,omitempty" doc:"Traded volume in BTC."`
+	NetVolumeBTC   *float64 `bucket:"nv_btc_%d" msgpack:"nv_btc,omitempty" doc:"Buy volume less sell volume in BTC."`
+	BuyVolumeBTC   *float64 `bucket:"bv_btc_%d" msgpack:"bv_btc,omitempty" doc:"Buy volume in BTC."`
+	SellVolumeBTC  *float64 `bucket:"sv_btc_%d" msgpack:"sv_btc,omitempty" doc:"Sell volume in BTC."`
+
+	// Named after the bucket label so the names of version 1, where
+	// rsi_60 was the one minute bucket, are not mistaken for these.
+	RSI *float64 `bucket:"rsi_%s" msgpack:"rsi,omitempty" doc:"Relative strength index."`
+
+	PriceZScore     *float64 `bucket:"zp_%d" msgpack:"zp,omitempty" doc:"Anomaly score of the price change against earlier windows."`
+	VolumeZScore    *float64 `bucket:"zv_%d" msgpack:"zv,omitempty" doc:"Anomaly score of the traded volume against earlier windows."`
+	TradeRateZScore *float64 `bucket:"zt_%d" msgpack:"zt,omitempty" doc:"Anomaly score of the number of trades against earlier windows."`
+
+	BetaBTC                *float64 `bucket:"beta_btc_%d" msgpack:"beta_btc,omitempty" doc:"Beta of the USD returns against BTCUSDT."`
+	CorrelationBTC         *float64 `bucket:"corr_btc_%d" msgpack:"corr_btc,omitempty" doc:"Correlation of the USD returns with BTCUSDT."`
+	RelativeStrengthBTC    *float64 `bucket:"rs_btc_%d" msgpack:"rs_btc,omitempty" doc:"USD return less that of BTCUSDT, in percent."`
+	BetaMarket             *float64 `bucket:"beta_mkt_%d" msgpack:"beta_mkt,omitempty" doc:"Beta of the USD returns against the market average."`
+	CorrelationMarket      *float64 `bucket:"corr_mkt_%d" msgpack:"corr_mkt,omitempty" doc:"Correlation of the USD returns with the market average."`
+	RelativeStrengthMarket *float64 `bucket:"rs_mkt_%d" msgpack:"rs_mkt,omitempty" doc:"USD return less the market average, in percent."`
+
+	WhaleBuyVolume  *float64 `bucket:"wbv_%d" msgpack:"wbv,omitempty" doc:"Buy volume of whale trades in the quote asset."`
+	WhaleSellVolume *float64 `bucket:"wsv_%d" msgpack:"wsv,omitempty" doc:"Sell volume of whale trades in the quote asset."`
+	WhaleTrades     *uint64  `bucket:"wt_%d" msgpack:"wt,omitempty" doc:"Number of whale trades."`
+}
+
+// CompleteProfileEntry holds the volume profile of a CompleteEntry for one
+// window. Like the bucket metrics they are sent flattened into the entry
+// with the window in minutes formatted into the key.
+type CompleteProfileEntry struct {
+	PointOfControl float64 `bucket:"poc_%d" msgpack:"poc" doc:"Price with the most traded volume."`
+	ValueAreaHigh  float64 `bucket:"vah_%d" msgpack:"vah" doc:"High of the value area around the point of control."`
+	ValueAreaLow   float64 `bucket:"val_%d" msgpack:"val" doc:"Low of the value area around the point of control."`
+}
+
+// CompleteRollingEntry holds the rolling metrics of a CompleteEntry for one
+// window. They are sent flattened into the entry with the window formatted
+// into the key as hours, nv_2h, nv_24h and so on.
+type CompleteRollingEntry struct {
+	TotalVolume float64  `window:"total_volume_%s" msgpack:"total_volume" doc:"Traded volume in the quote asset."`
+	NetVolume   float64  `window:"nv_%s" msgpack:"nv" doc:"Buy volume less sell volume in the quote asset."`
+	BuyVolume   float64  `window:"bv_%s" msgpack:"bv" doc:"Buy volume in the quote asset."`
+	SellVolume  float64  `window:"sv_%s" msgpack:"sv" doc:"Sell volume in the quote asset."`
+	Trades      uint64   `window:"trades_%s" msgpack:"trades" doc:"Number of trades."`
+	BuyRatio    *float64 `window:"buy_ratio_%s" msgpack:"buy_ratio,omitempty" doc:"Share of the trades that were buys."`
+	Vwap        *float64 `window:"vwap_%s" msgpack:"vwap,omitempty" doc:"Volume weighted average price."`
+	Coverage    float64  `window:"coverage_%s" msgpack:"coverage" doc:"Share of the window covered by history, less than 1 after a recent start or downtime."`
+}
+
+func NewCompleteRollingEntry(metrics *RollingMetrics) *CompleteRollingEntry {
+	return &CompleteRollingEntry{
+		TotalVolume: Round8(metrics.TotalVolume),
+		NetVolume:   Round8(metrics.NetVolume),
+		BuyVolume:   Round8(metrics.BuyVolume),
+		SellVolume:  Round8(metrics.SellVolume),
+		Trades:      metrics.Trades,
+		BuyRatio:    knownFloat(metrics.BuyRatio),
+		Vwap:        knownFloat(metrics.Vwap),
+		Coverage:    metrics.Coverage,
+	}
+}
+
+func NewCompleteProfileEntry(profile *VolumeProfile) *CompleteProfileEntry {
+	return &CompleteProfileEntry{
+		PointOfControl: profile.PointOfControl,
+		ValueAreaHigh:  profile.ValueAreaHigh,
+		ValueAreaLow:   profile.ValueAreaLow,
+	}
+}
+
+func optionalFloat(value float64) *float64 {
+	return &value
+}
+
+// knownFloat is like optionalFloat but returns nil for NaN, which metrics
+// are set to when there is not enough data to calculate them.
+func knownFloat(value float64) *float64 {
+	if math.IsNaN(value) {
+		return nil
+	}
+	return &value
+}
+
+func NewMonitorEntry(tracker *TickerTracker) *MonitorEntry {
+	last := tracker.LastTick()
+	if last == nil {
+		return nil
+	}
+	entry := &MonitorEntry{
+		Symbol: tracker.Symbol,
+		Close:  last.CurrentDayClose,
+		Bid:    last.Bid,
+		Ask:    last.Ask,
+		High:   last.HighPrice,
+		Low:    last.LowPrice,
+		Volume: last.TotalQuoteVolume,
+
+		PriceChangePercent: map[string]float64{
+			"24h": last.PriceChangePercent,
+		},
+		VolumeChangePercent: map[string]float64{},
+
+		Timestamp: last.Timestamp(),
+	}
+	for _, bucket := range Buckets {
+		entry.PriceChangePercent[bucketLabel(bucket)] = tracker.Metrics[bucket].PriceChangePercent
+		entry.VolumeChangePercent[bucketLabel(bucket)] = tracker.Metrics[bucket].VolumeChangePercent
+	}
+	if tracker.QuoteUSD > 0 {
+		entry.VolumeUSD = optionalFloat(Round8(last.TotalQuoteVolume * tracker.QuoteUSD))
+	}
+	if tracker.QuoteBTC > 0 {
+		entry.VolumeBTC = optionalFloat(Round8(last.TotalQuoteVolume * tracker.QuoteBTC))
+	}
+	return entry
+}
+
+func NewCompleteEntry(tracker *TickerTracker) *CompleteEntry {
+	monitor := NewMonitorEntry(tracker)
+	if monitor == nil {
+		return nil
+	}
+	entry := &CompleteEntry{
+		MonitorEntry: *monitor,
+		Range24:      tracker.H24Metrics.Range,
+		PumpScore:    tracker.Pump.Score,
+		Buckets:      map[int]*CompleteBucketEntry{},
+		Profiles:     map[int]*CompleteProfileEntry{},
+		Rolling:      map[int]*CompleteRollingEntry{},
+	}
+	if !math.IsNaN(tracker.H24Metrics.RangePercent) {
+		entry.RangePercent24 = tracker.H24Metrics.RangePercent
+	}
+
+	for _, bucket := range Buckets {
+		metrics := tracker.Metrics[bucket]
+		bucketEntry := &CompleteBucketEntry{
+			Low:          metrics.Low,
+			High:         metrics.High,
+			Range:        metrics.Range,
+			RangePercent: metrics.RangePercent,
+		}
+		if tracker.HaveVwap {
+			bucketEntry.Vwap = knownFloat(Round8(metrics.Vwap))
+			bucketEntry.VwapStdDev = knownFloat(Round8(metrics.VwapStdDev))
+			bucketEntry.VwapUpper = knownFloat(Round8(metrics.Vwap + vwapBandDeviations*metrics.VwapStdDev))
+			bucketEntry.VwapLower = knownFloat(Round8(metrics.Vwap - vwapBandDeviations*metrics.VwapStdDev))
+		}
+		if tracker.HaveTotalVolume {
+			bucketEntry.TotalVolume = optionalFloat(Round8(metrics.TotalVolume))
+			if tracker.QuoteUSD > 0 {
+				bucketEntry.TotalVolumeUSD = optionalFloat(Round8(metrics.USD.Total))
+			}
+			if tracker.QuoteBTC > 0 {
+				bucketEntry.TotalVolumeBTC = optionalFloat(Round8(metrics.BTC.Total))
+			}
+		}
+		if tracker.HaveNetVolume {
+			bucketEntry.NetVolume = optionalFloat(Round8(metrics.NetVolume))
+			bucketEntry.BuyVolume = optionalFloat(Round8(metrics.BuyVolume))
+			bucketEntry.SellVolume = optionalFloat(Round8(metrics.SellVolume))
+			if tracker.QuoteUSD > 0 {
+				bucketEntry.NetVolumeUSD = optionalFloat(Round8(metrics.USD.Net))
+				bucketEntry.BuyVolumeUSD = optionalFloat(Round8(metrics.USD.Buy))
+				bucketEntry.SellVolumeUSD = optionalFloat(Round8(metrics.USD.Sell))
+			}
+			if tracker.QuoteBTC > 0 {
+				bucketEntry.NetVolumeBTC = optionalFloat(Round8(metrics.BTC.Net))
+				bucketEntry.BuyVolumeBTC = optionalFloat(Round8(metrics.BTC.Buy))
+				bucketEntry.SellVolumeBTC = optionalFloat(Round8(metrics.BTC.Sell))
+			}
+			bucketEntry.WhaleBuyVolume = optionalFloat(Round8(metrics.WhaleBuyVolume))
+			bucketEntry.WhaleSellVolume = optionalFloat(Round8(metrics.WhaleSellVolume))
+			whaleTrades := metrics.WhaleTrades
+			bucketEntry.WhaleTrades = &whaleTrades
+		}
+		if metrics.RSIKnown {
+			bucketEntry.RSI = optionalFloat(Round8(metrics.RSI))
+		}
+		bucketEntry.PriceZScore = knownFloat(metrics.PriceZScore)
+		bucketEntry.VolumeZScore = knownFloat(metrics.VolumeZScore)
+		bucketEntry.TradeRateZScore = knownFloat(metrics.TradeRateZScore)
+		bucketEntry.BetaBTC = knownFloat(metrics.BetaBTC)
+		bucketEntry.CorrelationBTC = knownFloat(metrics.CorrelationBTC)
+		bucketEntry.RelativeStrengthBTC = knownFloat(metrics.RelativeStrengthBTC)
+		bucketEntry.BetaMarket = knownFloat(metrics.BetaMarket)
+		bucketEntry.CorrelationMarket = knownFloat(metrics.CorrelationMarket)
+		bucketEntry.RelativeStrengthMarket = knownFloat(metrics.RelativeStrengthMarket)
+		entry.Buckets[bucket] = bucketEntry
+	}
+
+	for window, profile := range tracker.Profiles {
+		entry.Profiles[window] = NewCompleteProfileEntry(profile)
+	}
+	for window, metrics := range tracker.Rolling {
+		entry.Rolling[window] = NewCompleteRollingEntry(metrics)
+	}
+
+	return entry
+}
+
+// Map returns the entry in the form it is sent to JSON clients, which is
+// also what delta mode and field selection work on.
+func (e *MonitorEntry) Map() map[string]interface{} {
+	m := map[string]interface{}{}
+	addEntryFields(m, reflect.ValueOf(e).Elem(), "json", 0)
+	return m
+}
+
+func (e *CompleteEntry) Map() map[string]interface{} {
+	m := map[string]interface{}{}
+	addEntryFields(m, reflect.ValueOf(e).Elem(), "json", 0)
+	for bucket, bucketEntry := range e.Buckets {
+		addEntryFields(m, reflect.ValueOf(bucketEntry).Elem(), "bucket", bucket)
+	}
+	for window, profileEntry := range e.Profiles {
+		addEntryFields(m, reflect.ValueOf(profileEntry).Elem(), "bucket", window)
+	}
+	for window, rollingEntry := range e.Rolling {
+		addEntryFields(m, reflect.ValueOf(rollingEntry).Elem(), "window", window)
+	}
+	return m
+}
+
+func (e CompleteEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Map())
+}
+
+// addEntryFields adds the fields of struct v named by the given tag to m.
+// Nil pointers are left out.
+func addEntryFields(m map[string]interface{}, v reflect.Value, tag string, bucket int) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if field.Anonymous {
+			addEntryFields(m, value, tag, bucket)
+			continue
+		}
+		name := entryFieldName(field, tag, bucket)
+		if name == "" {
+			continue
+		}
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+		m[name] = value.Interface()
+	}
+}
@@ -0,0 +1,165 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"github.com/crankykernel/binanceapi-go"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The golden files in testdata hold the wire format of the feed entries
+// and the feed schemas. After an intended change to the format, rewrite
+// them with:
+//
+//	go test ./server -run Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden files")
+
+// The time the golden trackers are recalculated at.
+var goldenNow = time.Date(2019, 2, 15, 12, 0, 30, 0, time.UTC)
+
+func milliseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// newTestTracker returns a tracker with 3 hours of trades and an hour of
+// ticks around price up to now, the same on every run for the same now.
+func newTestTracker(symbol string, quote string, price float64, now time.Time) *TickerTracker {
+	tracker := NewTickerTracker(symbol)
+	tracker.QuoteAsset = quote
+
+	start := now.Add(-3 * time.Hour)
+	for i := 0; start.Add(time.Duration(i) * 20 * time.Second).Before(now); i++ {
+		timestamp := start.Add(time.Duration(i) * 20 * time.Second)
+		tracker.AddTrade(binanceapi.StreamAggTrade{
+			Symbol:     symbol,
+			TradeID:    int64(i),
+			Price:      price * (1 + 0.01*math.Sin(float64(i)/7)),
+			Quantity:   float64(1 + i%5),
+			TradeTime:  milliseconds(timestamp),
+			BuyerMaker: i%3 == 0,
+		})
+	}
+
+	start = now.Add(-70 * time.Minute)
+	for i := 0; start.Add(time.Duration(i) * 30 * time.Second).Before(now); i++ {
+		timestamp := start.Add(time.Duration(i) * 30 * time.Second)
+		close := price * (1 + 0.01*math.Sin(float64(i)/11))
+		tracker.Update(binanceapi.TickerStreamMessage{
+			Symbol:             symbol,
+			EventTime:          milliseconds(timestamp),
+			PriceChangePercent: 1.5,
+			CurrentDayClose:    close,
+			Bid:                close * 0.999,
+			Ask:                close * 1.001,
+			HighPrice:          price * 1.05,
+			LowPrice:           price * 0.95,
+			TotalQuoteVolume:   1000 + float64(i)*10,
+		})
+	}
+	return tracker
+}
+
+// newTestTrackers returns an ETHBTC tracker, and the BTCUSDT tracker its
+// USD metrics and the market metrics are calculated with, recalculated
+// at now.
+func newTestTrackers(now time.Time) (*TickerTrackerMap, *TickerTracker) {
+	trackers := NewTickerTrackerMap()
+	btc := newTestTracker("BTCUSDT", "USDT", 3600, now)
+	btc.QuoteUSD = 1
+	btc.QuoteBTC = 1.0 / 3600
+	trackers.Trackers[btc.Symbol] = btc
+	eth := newTestTracker("ETHBTC", "BTC", 0.034, now)
+	eth.QuoteUSD = 3600
+	eth.QuoteBTC = 1
+	trackers.Trackers[eth.Symbol] = eth
+	for _, tracker := range trackers.Trackers {
+		tracker.RecalculateAt(now)
+	}
+	CalculateMarketMetrics(trackers, now)
+	CalculateRollupCoverage(trackers, now)
+	return trackers, eth
+}
+
+func checkGolden(t *testing.T, name string, v interface{}) {
+	actual, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual = append(actual, '\n')
+	filename := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := ioutil.WriteFile(filename, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(actual, expected) {
+		return
+	}
+	actualLines := strings.Split(string(actual), "\n")
+	expectedLines := strings.Split(string(expected), "\n")
+	for i := 0; i < len(actualLines) || i < len(expectedLines); i++ {
+		a, e := "", ""
+		if i < len(actualLines) {
+			a = actualLines[i]
+		}
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if a != e {
+			t.Errorf("%s differs at line %d:\n  expected: %s\n  actual:   %s",
+				filename, i+1, e, a)
+			return
+		}
+	}
+}
+
+func TestGoldenCompleteEntry(t *testing.T) {
+	_, tracker := newTestTrackers(goldenNow)
+	checkGolden(t, "complete_entry", NewCompleteEntry(tracker).Map())
+}
+
+func TestGoldenMonitorEntry(t *testing.T) {
+	_, tracker := newTestTrackers(goldenNow)
+	checkGolden(t, "monitor_entry", NewMonitorEntry(tracker).Map())
+}
+
+func TestGoldenFeedSchema(t *testing.T) {
+	for _, feed := range []string{"live", "monitor"} {
+		checkGolden(t, "schema_"+feed, FeedSchema(feed))
+	}
+}
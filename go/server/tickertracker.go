@@ -25,10 +25,12 @@
 package server
 
 import (
+	"fmt"
 	"github.com/crankykernel/binanceapi-go"
 	"gitlab.com/crankykernel/cryptoxscanner/log"
 	"gitlab.com/crankykernel/cryptoxscanner/metrics"
 	"math"
+	"sort"
 	"sync"
 	"time"
 )
@@ -46,29 +48,82 @@ type Aggregate struct {
 	QuoteVolume24 float64
 }
 
+// Volumes converted from the quote asset into a common currency.
+type NormalizedVolume struct {
+	Total float64 `msgpack:"total"`
+	Net   float64 `msgpack:"net"`
+	Buy   float64 `msgpack:"buy"`
+	Sell  float64 `msgpack:"sell"`
+}
+
 type TickerMetrics struct {
 	// Common metrics.
-	PriceChangePercent  float64
-	VolumeChangePercent float64
-	High                float64
-	Low                 float64
-	Range               float64
-	RangePercent        float64
+	PriceChangePercent  float64 `msgpack:"price_change_pct"`
+	VolumeChangePercent float64 `msgpack:"volume_change_pct"`
+	High                float64 `msgpack:"high"`
+	Low                 float64 `msgpack:"low"`
+	Range               float64 `msgpack:"range"`
+	RangePercent        float64 `msgpack:"range_pct"`
 
 	// Require trades.
-	Vwap        float64
-	TotalVolume float64
-	NetVolume   float64
-	BuyVolume   float64
-	SellVolume  float64
-	RSI         float64
-	TotalTrades uint64
-	SellTrades  uint64
-	BuyTrades   uint64
+	Vwap        float64 `msgpack:"vwap"`
+	VwapStdDev  float64 `msgpack:"vwap_sd"`
+	TotalVolume float64 `msgpack:"total_volume"`
+	NetVolume   float64 `msgpack:"nv"`
+	BuyVolume   float64 `msgpack:"bv"`
+	SellVolume  float64 `msgpack:"sv"`
+	TotalTrades uint64  `msgpack:"trades"`
+	SellTrades  uint64  `msgpack:"sell_trades"`
+	BuyTrades   uint64  `msgpack:"buy_trades"`
+
+	// The RSI, only known once the bucket has a full period of
+	// aggregates. See CalculateRSI.
+	RSI      float64 `msgpack:"rsi"`
+	RSIKnown bool    `msgpack:"rsi_known"`
+
+	// The volumes above in USD and BTC, zero if no conversion rate is
+	// known for the quote asset.
+	USD NormalizedVolume `msgpack:"usd"`
+	BTC NormalizedVolume `msgpack:"btc"`
+
+	// Anomaly scores of the price change, volume and trade count against
+	// the earlier windows of the bucket, NaN if unavailable. See
+	// CalculateAnomalies.
+	PriceZScore     float64 `msgpack:"z_price"`
+	VolumeZScore    float64 `msgpack:"z_volume"`
+	TradeRateZScore float64 `msgpack:"z_trades"`
+
+	// Beta, correlation and relative strength in percent against BTCUSDT
+	// and the market average, NaN if unavailable. See
+	// CalculateMarketMetrics.
+	BetaBTC                float64 `msgpack:"beta_btc"`
+	CorrelationBTC         float64 `msgpack:"corr_btc"`
+	RelativeStrengthBTC    float64 `msgpack:"rs_btc"`
+	BetaMarket             float64 `msgpack:"beta_market"`
+	CorrelationMarket      float64 `msgpack:"corr_market"`
+	RelativeStrengthMarket float64 `msgpack:"rs_market"`
+
+	// Volume and number of whale trades, see WhaleOptions.
+	WhaleBuyVolume  float64 `msgpack:"whale_bv"`
+	WhaleSellVolume float64 `msgpack:"whale_sv"`
+	WhaleTrades     uint64  `msgpack:"whale_trades"`
+}
+
+type NormalizedHistogram struct {
+	Volume     []float64
+	SellVolume []float64
+	BuyVolume  []float64
+	NetVolume  []float64
+	Volume24   []float64
 }
 
+// TickerTracker holds the state of one symbol. It is updated by the runner
+// goroutine, which holds lock while doing so. Readers on other goroutines
+// must hold a read lock.
 type TickerTracker struct {
 	Symbol     string
+	BaseAsset  string
+	QuoteAsset string
 	Ticks      []*binanceapi.TickerStreamMessage
 	Metrics    map[int]*TickerMetrics
 	LastUpdate time.Time
@@ -77,12 +132,41 @@ type TickerTracker struct {
 	// Trades, in Binance format.
 	Trades []*binanceapi.StreamAggTrade
 
+	// The trades classified as whale trades, and the percentile threshold
+	// in the quote asset.
+	WhaleTrades              []*binanceapi.StreamAggTrade
+	WhalePercentileThreshold float64
+	whaleThresholdTime       time.Time
+
 	Aggs map[int][]Aggregate
 
 	HaveVwap        bool
 	HaveTotalVolume bool
 	HaveNetVolume   bool
 
+	// Value of one unit of the quote asset in USD and BTC.
+	QuoteUSD float64
+	QuoteBTC float64
+
+	// Pump score of the latest recalculation.
+	Pump PumpScore
+
+	// Volume profiles keyed by window in minutes, see
+	// VolumeProfileOptions.
+	Profiles map[int]*VolumeProfile
+
+	// Per minute rollups of the last day and the rolling metrics
+	// calculated from them, keyed by window in minutes.
+	Rollups         []MinuteRollup
+	Rolling         map[int]*RollingMetrics
+	rollupsRestored time.Time
+
+	// Time of the first tick or trade, the start of the history the
+	// anomaly baselines can use.
+	trackedSince time.Time
+
+	lock sync.RWMutex
+
 	Histogram struct {
 		TradeCount     []uint64
 		SellTradeCount []uint64
@@ -92,21 +176,84 @@ type TickerTracker struct {
 		BuyVolume      []float64
 		NetVolume      []float64
 		Volume24       []float64
+
+		USD NormalizedHistogram
+		BTC NormalizedHistogram
 	}
 }
 
+// Buckets are the windows, in minutes, metrics are calculated over. They
+// are sorted and always include 1, the aggregates of the other buckets
+// are built from the 1 minute aggregates.
 var Buckets []int
 
+// Number of aggregates the RSI is smoothed over.
+var rsiPeriod int
+
+// The longest bucket, trades are only kept this long.
+const maxBucket = 210
+
+type MetricsOptions struct {
+	Buckets   []int
+	RSIPeriod int
+}
+
+var DefaultMetricsOptions = MetricsOptions{
+	Buckets:   []int{1, 2, 3, 5, 10, 15, 60},
+	RSIPeriod: 14,
+}
+
 func init() {
-	Buckets = []int{
-		1,
-		2,
-		3,
-		5,
-		10,
-		15,
-		60,
+	if err := ConfigureMetrics(DefaultMetricsOptions); err != nil {
+		panic(err)
+	}
+}
+
+// ConfigureMetrics sets the buckets and indicator periods. It must be
+// called before any trackers are created.
+func ConfigureMetrics(options MetricsOptions) error {
+	buckets := append([]int{}, options.Buckets...)
+	sort.Ints(buckets)
+	if len(buckets) == 0 || buckets[0] != 1 {
+		return fmt.Errorf("buckets must include 1")
+	}
+	// The pump score takes the acceleration from the bucket of twice its
+	// length, see CalculatePumpScore.
+	if i := sort.SearchInts(buckets, pumpBucket*2); i == len(buckets) || buckets[i] != pumpBucket*2 {
+		return fmt.Errorf("buckets must include %d for the pump score", pumpBucket*2)
+	}
+	for i, bucket := range buckets {
+		if bucket > maxBucket {
+			return fmt.Errorf("bucket %d is longer than %d minutes", bucket, maxBucket)
+		}
+		if i > 0 && bucket == buckets[i-1] {
+			return fmt.Errorf("duplicate bucket %d", bucket)
+		}
+	}
+	if options.RSIPeriod < 2 {
+		return fmt.Errorf("invalid RSI period %d", options.RSIPeriod)
+	}
+	Buckets = buckets
+	rsiPeriod = options.RSIPeriod
+	return nil
+}
+
+// bucketLabel names a bucket by its length, 5m or 1h.
+func bucketLabel(bucket int) string {
+	if bucket%60 == 0 {
+		return fmt.Sprintf("%dh", bucket/60)
 	}
+	return fmt.Sprintf("%dm", bucket)
+}
+
+// tickHistory is how long ticks are kept, enough for the longest bucket
+// and the hour of the histograms.
+func tickHistory() time.Duration {
+	minutes := Buckets[len(Buckets)-1]
+	if minutes < 60 {
+		minutes = 60
+	}
+	return time.Minute * time.Duration(minutes)
 }
 
 func NewTickerTracker(symbol string) *TickerTracker {
@@ -133,21 +280,34 @@ func (t *TickerTracker) LastTick() *binanceapi.TickerStreamMessage {
 }
 
 func (t *TickerTracker) Recalculate() {
-	t.CalculateTrades()
-	t.CalculateTicks()
+	t.RecalculateAt(time.Now())
+}
+
+// RecalculateAt recalculates the metrics as of now.
+func (t *TickerTracker) RecalculateAt(now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.CalculateTrades(now)
+	t.CalculateWhaleTrades(now)
+	t.CalculateVolumeProfiles(now)
+	t.CalculateRollingMetrics(now)
+	t.CalculateTicks(now)
+	t.CalculateNormalizedVolumes()
+	t.CalculateAnomalies(now)
+	t.CalculatePumpScore()
 
 	for _, bucket := range Buckets {
-		t.Metrics[bucket].RSI = t.CalculateRSI(t.Aggs[bucket])
+		t.Metrics[bucket].RSI, t.Metrics[bucket].RSIKnown = t.CalculateRSI(t.Aggs[bucket], rsiPeriod)
 	}
 }
 
-func (t *TickerTracker) CalculateRSI(aggs []Aggregate) float64 {
-	if aggs == nil {
-		return 0
+// CalculateRSI returns the RSI over period aggregates, and false if there
+// are not enough aggregates for a full period of changes.
+func (t *TickerTracker) CalculateRSI(aggs []Aggregate, period int) (float64, bool) {
+	if len(aggs) < period+1 {
+		return 0, false
 	}
 
-	period := 14
-
 	gains := float64(0)
 	losses := float64(0)
 
@@ -172,21 +332,26 @@ func (t *TickerTracker) CalculateRSI(aggs []Aggregate) float64 {
 			} else if cp.Close > prev.Close {
 				gain = cp.Close - prev.Close
 			}
-			losses = ((losses * 13) + loss) / 14
-			gains = ((gains * 13) + gain) / 14
+			losses = ((losses * float64(period-1)) + loss) / float64(period)
+			gains = ((gains * float64(period-1)) + gain) / float64(period)
 		}
 		prev = cp
 	}
 
+	if losses == 0 {
+		if gains == 0 {
+			return 50, true
+		}
+		return 100, true
+	}
 	rs := gains / losses
 	rsi := 100 - (100 / (1 + rs))
 
-	return rsi
+	return rsi, true
 }
 
-func (t *TickerTracker) CalculateTicks() {
+func (t *TickerTracker) CalculateTicks(now time.Time) {
 	last := t.LastTick()
-	now := time.Now()
 	count := len(t.Ticks)
 
 	if count < 2 {
@@ -273,32 +438,68 @@ func (t *TickerTracker) CalculateTicks() {
 // - VWAP
 // - Total volume
 // - Net volume
-func (t *TickerTracker) CalculateTrades() {
-	now := time.Now()
+func (t *TickerTracker) CalculateTrades(now time.Time) {
 	t.PruneTrades(now)
+	t.pruneWhaleTrades(now)
 
 	count := len(t.Trades)
 	if count < 1 {
 		return
 	}
 
-	volumeHistogram := metrics.NewVolumeHistogramCalculator()
+	volumeHistogram := metrics.NewVolumeHistogramCalculator(now)
 
 	t.HaveNetVolume = true
 	t.HaveTotalVolume = true
 	t.HaveVwap = true
 	vwapPrice := float64(0)
 	vwapVolume := float64(0)
+	vwapSquares := float64(0)
 	buyVolume := float64(0)
 	sellVolume := float64(0)
 	totalTrades := uint64(0)
 	sellTrades := uint64(0)
 	buyTrades := uint64(0)
 
+	// Set a bucket from the trades seen so far, which are those younger
+	// than the bucket.
+	next := 0
+	setBucket := func(bucket int) {
+		metrics := t.Metrics[bucket]
+		metrics.NetVolume = buyVolume - sellVolume
+		metrics.TotalVolume = buyVolume + sellVolume
+		metrics.BuyVolume = buyVolume
+		metrics.SellVolume = sellVolume
+		metrics.TotalTrades = totalTrades
+		metrics.BuyTrades = buyTrades
+		metrics.SellTrades = sellTrades
+		if vwapVolume > 0 {
+			metrics.Vwap = vwapPrice / vwapVolume
+			metrics.VwapStdDev = math.Sqrt(math.Max(0,
+				vwapSquares/vwapVolume-metrics.Vwap*metrics.Vwap))
+		} else {
+			metrics.Vwap = math.NaN()
+			metrics.VwapStdDev = math.NaN()
+		}
+	}
+
 	for i := count - 1; i >= 0; i-- {
 		trade := t.Trades[i]
 
 		age := now.Sub(trade.Timestamp())
+		for next < len(Buckets) && age >= time.Duration(Buckets[next])*time.Minute {
+			setBucket(Buckets[next])
+			next++
+		}
+
+		volumeHistogram.AddTrade(trade)
+
+		if next == len(Buckets) {
+			if age >= time.Duration(metrics.Buckets)*time.Minute {
+				break
+			}
+			continue
+		}
 
 		if trade.BuyerMaker {
 			sellVolume += trade.QuoteQuantity()
@@ -311,28 +512,10 @@ func (t *TickerTracker) CalculateTrades() {
 
 		vwapVolume += trade.Quantity
 		vwapPrice += trade.Quantity * trade.Price
-		vwap := vwapPrice / vwapVolume
-
-		totalVolume := buyVolume + sellVolume
-		netVolume := buyVolume - sellVolume
-
-		bucket := (int(age.Seconds()) / 60) + 1
-
-		volumeHistogram.AddTrade(trade)
-
-		metrics := t.Metrics[bucket]
-		if metrics == nil {
-			continue
-		}
-
-		metrics.NetVolume = netVolume
-		metrics.TotalVolume = totalVolume
-		metrics.BuyVolume = buyVolume
-		metrics.SellVolume = sellVolume
-		metrics.Vwap = vwap
-		metrics.TotalTrades = totalTrades
-		metrics.BuyTrades = buyTrades
-		metrics.SellTrades = sellTrades
+		vwapSquares += trade.Quantity * trade.Price * trade.Price
+	}
+	for ; next < len(Buckets); next++ {
+		setBucket(Buckets[next])
 	}
 
 	t.Histogram.TradeCount = volumeHistogram.TradeCount[:]
@@ -344,13 +527,59 @@ func (t *TickerTracker) CalculateTrades() {
 	t.Histogram.NetVolume = volumeHistogram.NetVolume[:]
 }
 
+func normalizeVolume(m *TickerMetrics, rate float64) NormalizedVolume {
+	return NormalizedVolume{
+		Total: m.TotalVolume * rate,
+		Net:   m.NetVolume * rate,
+		Buy:   m.BuyVolume * rate,
+		Sell:  m.SellVolume * rate,
+	}
+}
+
+func scaleHistogram(values []float64, rate float64) []float64 {
+	scaled := make([]float64, len(values))
+	for i, value := range values {
+		scaled[i] = value * rate
+	}
+	return scaled
+}
+
+func (t *TickerTracker) normalizeHistogram(rate float64) NormalizedHistogram {
+	return NormalizedHistogram{
+		Volume:     scaleHistogram(t.Histogram.Volume, rate),
+		SellVolume: scaleHistogram(t.Histogram.SellVolume, rate),
+		BuyVolume:  scaleHistogram(t.Histogram.BuyVolume, rate),
+		NetVolume:  scaleHistogram(t.Histogram.NetVolume, rate),
+		Volume24:   scaleHistogram(t.Histogram.Volume24, rate),
+	}
+}
+
+// Convert the quote asset volumes into USD and BTC using the current
+// conversion rates.
+func (t *TickerTracker) CalculateNormalizedVolumes() {
+	for _, metrics := range t.Metrics {
+		metrics.USD = normalizeVolume(metrics, t.QuoteUSD)
+		metrics.BTC = normalizeVolume(metrics, t.QuoteBTC)
+	}
+	if last := t.LastTick(); last != nil {
+		t.H24Metrics.TotalVolume = last.TotalQuoteVolume
+		t.H24Metrics.USD.Total = last.TotalQuoteVolume * t.QuoteUSD
+		t.H24Metrics.BTC.Total = last.TotalQuoteVolume * t.QuoteBTC
+	}
+	t.Histogram.USD = t.normalizeHistogram(t.QuoteUSD)
+	t.Histogram.BTC = t.normalizeHistogram(t.QuoteBTC)
+}
+
 func (t *TickerTracker) Update(ticker binanceapi.TickerStreamMessage) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
 	t.LastUpdate = time.Now()
 	t.Ticks = append(t.Ticks, &ticker)
+	t.track(ticker.Timestamp())
 	now := ticker.Timestamp()
 	for {
 		first := t.Ticks[0]
-		if now.Sub(first.Timestamp()) > (time.Minute*60)+1 {
+		if now.Sub(first.Timestamp()) > tickHistory()+1 {
 			t.Ticks = t.Ticks[1:]
 		} else {
 			break
@@ -358,12 +587,24 @@ func (t *TickerTracker) Update(ticker binanceapi.TickerStreamMessage) {
 	}
 }
 
-func (t *TickerTracker) AddTrade(trade binanceapi.StreamAggTrade) {
+// track notes the time of a tick or trade, keeping the earliest.
+func (t *TickerTracker) track(at time.Time) {
+	if t.trackedSince.IsZero() || at.Before(t.trackedSince) {
+		t.trackedSince = at
+	}
+}
+
+// AddTrade adds a trade to the tracker. If it is a whale trade it is
+// returned.
+func (t *TickerTracker) AddTrade(trade binanceapi.StreamAggTrade) *WhaleTrade {
 	if trade.Symbol == "" {
 		log.Printf("error: not adding trade with empty symbol")
-		return
+		return nil
 	}
 
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	if len(t.Trades) > 0 {
 		lastTrade := t.Trades[len(t.Trades)-1]
 		if trade.Timestamp().Before(lastTrade.Timestamp()) {
@@ -372,7 +613,10 @@ func (t *TickerTracker) AddTrade(trade binanceapi.StreamAggTrade) {
 		}
 	}
 
+	whale := t.classifyTrade(&trade)
 	t.Trades = append(t.Trades, &trade)
+	t.track(trade.Timestamp())
+	t.addRollup(&trade)
 
 	openTime := trade.Timestamp().Truncate(time.Minute)
 
@@ -422,7 +666,10 @@ func (t *TickerTracker) AddTrade(trade binanceapi.StreamAggTrade) {
 	}
 	m1Agg := t.Aggs[1][len(t.Aggs[1])-1]
 
-	for _, interval := range Buckets[1:] {
+	for _, interval := range Buckets {
+		if interval == 1 {
+			continue
+		}
 		openTime := m1Agg.Time.Truncate(time.Minute * time.Duration(interval))
 		aggs := t.Aggs[interval]
 		if aggs == nil {
@@ -471,6 +718,8 @@ func (t *TickerTracker) AddTrade(trade binanceapi.StreamAggTrade) {
 			}
 		}
 	}
+
+	return whale
 }
 
 func (t *TickerTracker) PruneTrades(now time.Time) {
@@ -516,6 +765,24 @@ func (t *TickerTrackerMap) GetTracker(symbol string) *TickerTracker {
 	return t.Trackers[symbol]
 }
 
+// Get returns the tracker of symbol, or nil if there is none.
+func (t *TickerTrackerMap) Get(symbol string) *TickerTracker {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.Trackers[symbol]
+}
+
+// Prune removes the trackers for which keep returns false.
+func (t *TickerTrackerMap) Prune(keep func(symbol string) bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for symbol := range t.Trackers {
+		if !keep(symbol) {
+			delete(t.Trackers, symbol)
+		}
+	}
+}
+
 func (t *TickerTrackerMap) GetLastForSymbol(symbol string) *binanceapi.TickerStreamMessage {
 	if tracker, ok := t.Trackers[symbol]; ok {
 		return tracker.LastTick()
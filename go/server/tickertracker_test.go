@@ -0,0 +1,83 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateRSI(t *testing.T) {
+	tests := []struct {
+		name   string
+		closes []float64
+		period int
+		rsi    float64
+		known  bool
+	}{
+		{"no history", nil, 2, 0, false},
+		{"short history", []float64{1, 2}, 2, 0, false},
+		{"flat price", []float64{1, 1, 1, 1}, 2, 50, true},
+		{"all gains", []float64{1, 2, 3, 4}, 2, 100, true},
+		{"all losses", []float64{4, 3, 2, 1}, 2, 0, true},
+		// Average gain 0.25 and loss 0.5 after smoothing.
+		{"mixed", []float64{1, 2, 1}, 2, 100 - 100/1.5, true},
+	}
+	tracker := &TickerTracker{}
+	for _, test := range tests {
+		aggs := []Aggregate{}
+		for _, close := range test.closes {
+			aggs = append(aggs, Aggregate{Close: close})
+		}
+		rsi, known := tracker.CalculateRSI(aggs, test.period)
+		if known != test.known || math.Abs(rsi-test.rsi) > 1e-9 {
+			t.Errorf("%s: expected %v %v, got %v %v", test.name, test.rsi, test.known, rsi, known)
+		}
+	}
+}
+
+func TestConfigureMetrics(t *testing.T) {
+	defer ConfigureMetrics(DefaultMetricsOptions)
+	tests := []struct {
+		buckets []int
+		period  int
+		valid   bool
+	}{
+		{[]int{1, 2}, 14, true},
+		{[]int{60, 2, 1}, 14, true},
+		{[]int{2, 5}, 14, false},
+		// The pump score needs the 2 minute bucket.
+		{[]int{1, 5, 15}, 14, false},
+		{[]int{1, 2, 2}, 14, false},
+		{[]int{1, 2, maxBucket + 1}, 14, false},
+		{[]int{1, 2}, 1, false},
+	}
+	for _, test := range tests {
+		err := ConfigureMetrics(MetricsOptions{Buckets: test.buckets, RSIPeriod: test.period})
+		if (err == nil) != test.valid {
+			t.Errorf("%v %d: expected valid %v, got %v", test.buckets, test.period, test.valid, err)
+		}
+	}
+}
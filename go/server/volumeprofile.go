@@ -0,0 +1,271 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Width of the VWAP bands in standard deviations.
+const vwapBandDeviations = 2
+
+// Longest window a volume profile can cover, trades are only kept this
+// long.
+const maxVolumeProfileWindow = 210
+
+type VolumeProfileOptions struct {
+	// Windows in minutes for which profiles are included in the live
+	// feed.
+	Windows []int
+
+	// Number of price levels.
+	Bins int
+
+	// Share of the volume in the value area.
+	ValueArea float64
+}
+
+var DefaultVolumeProfileOptions = VolumeProfileOptions{
+	Windows:   []int{15, 60},
+	Bins:      50,
+	ValueArea: 0.7,
+}
+
+var volumeProfileOptions = DefaultVolumeProfileOptions
+
+type VolumeProfileBin struct {
+	Low       float64 `json:"low"`
+	High      float64 `json:"high"`
+	Volume    float64 `json:"volume"`
+	BuyVolume float64 `json:"buy_volume"`
+}
+
+// VolumeProfile is the traded volume, in the quote asset, at each price
+// level over a window.
+type VolumeProfile struct {
+	Window int     `json:"window"`
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+	Volume float64 `json:"volume"`
+	Trades int     `json:"trades"`
+
+	// Middle of the price level with the most volume.
+	PointOfControl float64 `json:"poc"`
+
+	// Price range around the point of control holding the value area
+	// share of the volume.
+	ValueAreaHigh float64 `json:"vah"`
+	ValueAreaLow  float64 `json:"val"`
+
+	Vwap       float64 `json:"vwap"`
+	VwapStdDev float64 `json:"vwap_sd"`
+
+	Bins []VolumeProfileBin `json:"bins"`
+}
+
+// CalculateVolumeProfile returns the volume profile of the trades of the
+// last window minutes, nil if there were none.
+func (t *TickerTracker) CalculateVolumeProfile(now time.Time, window int, bins int, valueArea float64) *VolumeProfile {
+	since := now.Add(-time.Duration(window) * time.Minute)
+	first := len(t.Trades)
+	for first > 0 && !t.Trades[first-1].Timestamp().Before(since) {
+		first--
+	}
+	trades := t.Trades[first:]
+	if len(trades) == 0 || bins < 1 {
+		return nil
+	}
+
+	profile := &VolumeProfile{
+		Window: window,
+		Low:    trades[0].Price,
+		High:   trades[0].Price,
+		Trades: len(trades),
+	}
+	quantity := float64(0)
+	sum := float64(0)
+	sumSquares := float64(0)
+	for _, trade := range trades {
+		if trade.Price < profile.Low {
+			profile.Low = trade.Price
+		}
+		if trade.Price > profile.High {
+			profile.High = trade.Price
+		}
+		quantity += trade.Quantity
+		sum += trade.Quantity * trade.Price
+		sumSquares += trade.Quantity * trade.Price * trade.Price
+	}
+	if quantity > 0 {
+		profile.Vwap = Round8(sum / quantity)
+		profile.VwapStdDev = Round8(math.Sqrt(math.Max(0,
+			sumSquares/quantity-(sum/quantity)*(sum/quantity))))
+	}
+
+	if profile.High == profile.Low {
+		bins = 1
+	}
+	size := (profile.High - profile.Low) / float64(bins)
+	profile.Bins = make([]VolumeProfileBin, bins)
+	for i := range profile.Bins {
+		profile.Bins[i].Low = Round8(profile.Low + size*float64(i))
+		profile.Bins[i].High = Round8(profile.Low + size*float64(i+1))
+	}
+	for _, trade := range trades {
+		i := 0
+		if size > 0 {
+			i = int((trade.Price - profile.Low) / size)
+		}
+		if i >= bins {
+			i = bins - 1
+		}
+		profile.Bins[i].Volume += trade.QuoteQuantity()
+		if !trade.BuyerMaker {
+			profile.Bins[i].BuyVolume += trade.QuoteQuantity()
+		}
+		profile.Volume += trade.QuoteQuantity()
+	}
+
+	poc := 0
+	for i := range profile.Bins {
+		if profile.Bins[i].Volume > profile.Bins[poc].Volume {
+			poc = i
+		}
+	}
+
+	// Grow the value area from the point of control towards the side
+	// with more volume.
+	low, high := poc, poc
+	volume := profile.Bins[poc].Volume
+	for volume < profile.Volume*valueArea && (low > 0 || high < bins-1) {
+		below := -1.0
+		if low > 0 {
+			below = profile.Bins[low-1].Volume
+		}
+		above := -1.0
+		if high < bins-1 {
+			above = profile.Bins[high+1].Volume
+		}
+		if above >= below {
+			high++
+			volume += above
+		} else {
+			low--
+			volume += below
+		}
+	}
+
+	profile.PointOfControl = Round8((profile.Bins[poc].Low + profile.Bins[poc].High) / 2)
+	profile.ValueAreaLow = profile.Bins[low].Low
+	profile.ValueAreaHigh = profile.Bins[high].High
+	profile.Volume = Round8(profile.Volume)
+	for i := range profile.Bins {
+		profile.Bins[i].Volume = Round8(profile.Bins[i].Volume)
+		profile.Bins[i].BuyVolume = Round8(profile.Bins[i].BuyVolume)
+	}
+	return profile
+}
+
+// CalculateVolumeProfiles sets the profiles of the configured windows.
+func (t *TickerTracker) CalculateVolumeProfiles(now time.Time) {
+	profiles := map[int]*VolumeProfile{}
+	for _, window := range volumeProfileOptions.Windows {
+		profile := t.CalculateVolumeProfile(now, window,
+			volumeProfileOptions.Bins, volumeProfileOptions.ValueArea)
+		if profile != nil {
+			profiles[window] = profile
+		}
+	}
+	t.Profiles = profiles
+}
+
+// VolumeProfileHandler serves the volume profiles of a symbol. The window,
+// in minutes, and number of bins default to those of the configured
+// profiles.
+type VolumeProfileHandler struct {
+	binanceRunner *BinanceRunner
+}
+
+func NewVolumeProfileHandler(binanceRunner *BinanceRunner) *VolumeProfileHandler {
+	return &VolumeProfileHandler{
+		binanceRunner: binanceRunner,
+	}
+}
+
+func (h *VolumeProfileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(mux.Vars(r)["symbol"])
+	trackers := h.binanceRunner.GetCache()
+	tracker, ok := trackers.Trackers[symbol]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	windows := volumeProfileOptions.Windows
+	if value := r.FormValue("window"); value != "" {
+		window, err := strconv.Atoi(value)
+		if err != nil || window < 1 || window > maxVolumeProfileWindow {
+			http.Error(w, "invalid window", http.StatusBadRequest)
+			return
+		}
+		windows = []int{window}
+	}
+	bins := volumeProfileOptions.Bins
+	if value := r.FormValue("bins"); value != "" {
+		var err error
+		bins, err = strconv.Atoi(value)
+		if err != nil || bins < 1 || bins > 1000 {
+			http.Error(w, "invalid bins", http.StatusBadRequest)
+			return
+		}
+	}
+
+	profiles := []*VolumeProfile{}
+	now := time.Now()
+	for _, window := range windows {
+		profile := tracker.CalculateVolumeProfile(now, window, bins,
+			volumeProfileOptions.ValueArea)
+		if profile != nil {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	w.Header().Add("content-type", "application/json")
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(VolumeProfileResponse{
+		Symbol:   symbol,
+		Profiles: profiles,
+	}); err != nil {
+		log.WithError(err).WithField("handler", "volume-profile").
+			Errorf("Failed to encode response to JSON")
+	}
+}
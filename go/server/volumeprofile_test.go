@@ -0,0 +1,122 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalculateVolumeProfile(t *testing.T) {
+	now := time.Date(2019, 2, 15, 12, 0, 0, 0, time.UTC)
+	trade := func(age int, price float64, quantity float64, sell bool) *binanceapi.StreamAggTrade {
+		return &binanceapi.StreamAggTrade{
+			Price:      price,
+			Quantity:   quantity,
+			TradeTime:  milliseconds(now.Add(-time.Duration(age) * time.Minute)),
+			BuyerMaker: sell,
+		}
+	}
+	trades := []*binanceapi.StreamAggTrade{
+		trade(20, 100, 1, true),
+		trade(10, 10, 1, false),
+		trade(9, 11, 2, true),
+		trade(8, 12, 5, false),
+		trade(7, 12.5, 2, true),
+		trade(6, 13, 3, false),
+		trade(5, 15, 1, false),
+	}
+
+	tests := []struct {
+		name     string
+		trades   []*binanceapi.StreamAggTrade
+		window   int
+		bins     int
+		expected *VolumeProfile
+	}{
+		{
+			// The value area grows from 12-13 to the larger 13-14
+			// rather than 11-12, and then holds 124 of 171.
+			name:   "window",
+			trades: trades,
+			window: 15,
+			bins:   5,
+			expected: &VolumeProfile{
+				Window: 15, Low: 10, High: 15, Volume: 171, Trades: 6,
+				PointOfControl: 12.5, ValueAreaLow: 12, ValueAreaHigh: 14,
+				Vwap: 12.21428571, VwapStdDev: 1.12938488,
+				Bins: []VolumeProfileBin{
+					{Low: 10, High: 11, Volume: 10, BuyVolume: 10},
+					{Low: 11, High: 12, Volume: 22},
+					{Low: 12, High: 13, Volume: 85, BuyVolume: 60},
+					{Low: 13, High: 14, Volume: 39, BuyVolume: 39},
+					{Low: 14, High: 15, Volume: 15, BuyVolume: 15},
+				},
+			},
+		},
+		{
+			name:   "wider window",
+			trades: trades,
+			window: 30,
+			bins:   2,
+			expected: &VolumeProfile{
+				Window: 30, Low: 10, High: 100, Volume: 271, Trades: 7,
+				PointOfControl: 32.5, ValueAreaLow: 10, ValueAreaHigh: 100,
+				Vwap: 18.06666667, VwapStdDev: 21.92477036,
+				Bins: []VolumeProfileBin{
+					{Low: 10, High: 55, Volume: 171, BuyVolume: 124},
+					{Low: 55, High: 100, Volume: 100},
+				},
+			},
+		},
+		{
+			name:   "single price",
+			trades: []*binanceapi.StreamAggTrade{trade(2, 5, 1, false), trade(1, 5, 3, true)},
+			window: 15,
+			bins:   5,
+			expected: &VolumeProfile{
+				Window: 15, Low: 5, High: 5, Volume: 20, Trades: 2,
+				PointOfControl: 5, ValueAreaLow: 5, ValueAreaHigh: 5, Vwap: 5,
+				Bins: []VolumeProfileBin{{Low: 5, High: 5, Volume: 20, BuyVolume: 5}},
+			},
+		},
+		{
+			name:   "no trades",
+			trades: trades,
+			window: 4,
+			bins:   5,
+		},
+	}
+	for _, test := range tests {
+		tracker := NewTickerTracker("ETHBTC")
+		tracker.Trades = test.trades
+		profile := tracker.CalculateVolumeProfile(now, test.window, test.bins, 0.7)
+		if !reflect.DeepEqual(profile, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, profile)
+		}
+	}
+}
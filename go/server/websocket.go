@@ -25,15 +25,12 @@
 package server
 
 import (
-	"encoding/json"
-	"fmt"
 	"github.com/gorilla/websocket"
 	"gitlab.com/crankykernel/cryptoxscanner/log"
-	"math"
 	"net/http"
 	"strconv"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -46,7 +43,13 @@ func init() {
 type WsConnectionTracker struct {
 	Paths   map[string]map[*WebSocketClient]bool
 	Clients map[*WebSocketClient]map[string]bool
-	Lock    sync.RWMutex
+
+	// Updates dropped for the clients that have been removed, and how
+	// many of them were disconnected for falling behind.
+	Dropped      uint64
+	Disconnected uint64
+
+	Lock sync.RWMutex
 }
 
 func NewWsConnectionTracker() *WsConnectionTracker {
@@ -69,19 +72,38 @@ func (w *WsConnectionTracker) Add(path string, conn *WebSocketClient) {
 	defer w.Lock.Unlock()
 }
 
+// Del removes a path from a client. When the last path is removed the
+// client is forgotten and its queue closed.
 func (w *WsConnectionTracker) Del(path string, conn *WebSocketClient) {
 	w.Lock.Lock()
+	defer w.Lock.Unlock()
 
-	w.Paths[path][conn] = false
 	delete(w.Paths[path], conn)
+	if len(w.Paths[path]) == 0 {
+		delete(w.Paths, path)
+	}
 
-	w.Clients[conn][path] = false
 	delete(w.Clients[conn], path)
+	if len(w.Clients[conn]) == 0 {
+		delete(w.Clients, conn)
+		conn.queue.Close()
+		w.Dropped += conn.queue.Dropped()
+		if conn.queue.Overflowed() {
+			w.Disconnected++
+		}
+	}
+}
 
-	defer w.Lock.Unlock()
+// Clients are numbered as they connect to tell them apart in the status.
+var wsClientCount uint64
+
+func nextWsClientId() uint64 {
+	return atomic.AddUint64(&wsClientCount, 1)
 }
 
 type WebSocketClient struct {
+	id uint64
+
 	// The websocket connection.
 	conn *websocket.Conn
 
@@ -90,14 +112,33 @@ type WebSocketClient struct {
 
 	// Data written into this Channel will be sent to the client.
 	closeChannel chan bool
+
+	// The encoding negotiated for messages to the client.
+	encoding WsEncoding
+
+	// Feed updates waiting to be written to the client.
+	queue *WsSendQueue
 }
 
 func NewWebSocketClient(c *websocket.Conn, r *http.Request) *WebSocketClient {
-	return &WebSocketClient{
+	client := &WebSocketClient{
+		id:           nextWsClientId(),
 		conn:         c,
 		closeChannel: make(chan bool, 1),
 		r:            r,
+		encoding:     wsNegotiateEncoding(c, r),
 	}
+	client.queue = client.newQueue(wsQueueOptions)
+	return client
+}
+
+// newQueue returns a send queue for the client, in its encoding, that
+// disconnects it when it falls behind under the disconnect policy.
+func (c *WebSocketClient) newQueue(options WsQueueOptions) *WsSendQueue {
+	queue := NewWsSendQueue(c.GetRemoteAddr(), options)
+	queue.encoding = c.encoding
+	queue.disconnect = c.disconnect
+	return queue
 }
 
 func (c *WebSocketClient) GetRemoteAddr() string {
@@ -113,14 +154,33 @@ func (c *WebSocketClient) GetRemoteAddr() string {
 }
 
 func (c *WebSocketClient) GetRemoteHost() string {
-	remoteAddr := c.GetRemoteAddr()
-	return strings.Split(remoteAddr, ":")[0]
+	return requestRemoteHost(c.r)
+}
+
+// disconnect closes the connection of the client. Stream clients have no
+// connection of their own, their handler is signalled to end the stream
+// instead.
+func (c *WebSocketClient) disconnect() {
+	if c.conn != nil {
+		c.conn.Close()
+		return
+	}
+	select {
+	case c.closeChannel <- true:
+	default:
+	}
 }
 
 func (c *WebSocketClient) WriteTextMessage(msg []byte) error {
 	return c.conn.WriteMessage(websocket.TextMessage, msg)
 }
 
+func (c *WebSocketClient) setWriteDeadline() {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(time.Second * 6)); err != nil {
+		log.WithError(err).Warnf("Failed to send websocket write deadline")
+	}
+}
+
 type TickerWebSocketHandler struct {
 	upgrader      websocket.Upgrader
 	clientsLock   sync.RWMutex
@@ -135,6 +195,7 @@ func NewWebSocketHandler(binanceRunner *BinanceRunner, source *WsSourceCache) *T
 				return true
 			},
 			EnableCompression: true,
+			Subprotocols:      wsSubprotocols,
 		},
 		source:        source,
 		binanceRunner: binanceRunner,
@@ -171,53 +232,60 @@ func (h *TickerWebSocketHandler) Handle(w http.ResponseWriter, r *http.Request)
 	}
 	lastUpdate := time.Time{}
 
-	// The read loop just reads and discards message until an error is
-	// received.
-	go h.readLoop(client)
+	// Delta mode is only available on the JSON feed sockets.
+	var deltaState *wsDeltaState
+	if h.source != nil && client.encoding == WsEncodingJSON &&
+		r.FormValue("delta") != "" && r.FormValue("delta") != "0" {
+		deltaState = &wsDeltaState{}
+	}
+	resync := make(chan bool, 1)
 
-	if symbol != "" {
-		channel := h.binanceRunner.SubscribeSymbol(symbol)
-		defer h.binanceRunner.UnsubscribeSymbol(symbol, channel)
-		for {
-			select {
-			case filteredMessage := <-channel:
-				bytes, err := json.Marshal(filteredMessage)
-				if err != nil {
-					log.Infof("failed to marshal filtered ticker: %v", err)
-					continue
-				}
+	// The read loop discards everything except resync requests until an
+	// error is received.
+	go h.readLoop(client, resync)
 
-				if err := client.WriteTextMessage(bytes); err != nil {
-					log.Info("error: websocket write error to %s: %v", client.GetRemoteAddr(), err)
-					goto Done
-				}
-			case <-client.closeChannel:
-				goto Done
-			}
-		}
+	queue := client.queue
+	if symbol != "" {
+		h.binanceRunner.SubscribeSymbol(symbol, queue, "")
+		defer h.binanceRunner.UnsubscribeSymbol(symbol, queue, "")
 	} else {
-		channel := h.source.Subscribe()
-		defer h.source.Unsubscribe(channel)
-		for {
-			select {
-			case trackers := <-channel:
-				if trackers == nil {
-					goto Done
+		h.source.Subscribe(queue, "")
+		defer h.source.Unsubscribe(queue, "")
+	}
+
+	for {
+		select {
+		case <-queue.Ready():
+			for item, ok := queue.Pop(); ok; item, ok = queue.Pop() {
+				if item.entry != nil {
+					if err := h.writeSymbolUpdate(client, item.entry); err != nil {
+						log.WithError(err).Errorf("Failed to write websocket message to %s",
+							client.GetRemoteAddr())
+						goto Done
+					}
+					continue
 				}
 				if time.Now().Sub(lastUpdate) < time.Second*time.Duration(updateInterval) {
 					continue
 				}
-				if err := client.conn.SetWriteDeadline(time.Now().Add(time.Second * 6)); err != nil {
-					log.WithError(err).Warnf("Failed to send websocket write deadline")
-				}
-				if err := client.conn.WritePreparedMessage(trackers); err != nil {
+				if err := h.writeUpdate(client, item.update, deltaState); err != nil {
 					log.WithError(err).Errorf("Failed to write websocket prepared message")
 					goto Done
 				}
 				lastUpdate = time.Now()
-			case <-client.closeChannel:
+			}
+		case <-resync:
+			update := h.source.Last()
+			if deltaState == nil || update == nil {
+				continue
+			}
+			deltaState.resync = true
+			if err := h.writeUpdate(client, update, deltaState); err != nil {
+				log.WithError(err).Errorf("Failed to write websocket prepared message")
 				goto Done
 			}
+		case <-client.closeChannel:
+			goto Done
 		}
 	}
 Done:
@@ -226,11 +294,61 @@ Done:
 	log.Infof("WebSocket connection closed: %v", client.GetRemoteAddr())
 }
 
-func (h *TickerWebSocketHandler) readLoop(client *WebSocketClient) {
+func (h *TickerWebSocketHandler) writeSymbolUpdate(client *WebSocketClient, update *WsSymbolUpdate) error {
+	var message interface{} = update.Entry
+	if client.encoding == WsEncodingMsgpack {
+		message = update.Typed
+	}
+	bytes, err := client.encoding.Marshal(message)
+	if err != nil {
+		log.Infof("failed to marshal filtered ticker: %v", err)
+		return nil
+	}
+	client.setWriteDeadline()
+	return client.conn.WriteMessage(client.encoding.MessageType(), bytes)
+}
+
+func (h *TickerWebSocketHandler) writeUpdate(client *WebSocketClient, update *WsSourceUpdate, deltaState *wsDeltaState) error {
+	pm := update.Prepared
+	if client.encoding == WsEncodingMsgpack {
+		pm = update.Binary
+	} else if deltaState != nil {
+		delta, snapshot := deltaState.next(h.source, update)
+		switch {
+		case snapshot:
+			pm = update.Prepared
+		case delta == nil:
+			return nil
+		case delta == update.Delta:
+			pm = update.DeltaMessage
+		default:
+			var err error
+			if pm, err = prepareJsonMessage(delta); err != nil {
+				return err
+			}
+		}
+	}
+	if pm == nil {
+		// Built before this client subscribed.
+		return nil
+	}
+	client.setWriteDeadline()
+	return client.conn.WritePreparedMessage(pm)
+}
+
+func (h *TickerWebSocketHandler) readLoop(client *WebSocketClient, resync chan bool) {
 	for {
-		if _, _, err := client.conn.ReadMessage(); err != nil {
+		messageType, buf, err := client.conn.ReadMessage()
+		if err != nil {
 			break
 		}
+		var command WsMuxCommand
+		if err := wsDecodeCommand(messageType, buf, &command); err == nil && command.Cmd == "resync" {
+			select {
+			case resync <- true:
+			default:
+			}
+		}
 	}
 	select {
 	case client.closeChannel <- true:
@@ -238,196 +356,316 @@ func (h *TickerWebSocketHandler) readLoop(client *WebSocketClient) {
 	}
 }
 
+// TickerStream is the message sent by the single feed sockets. The format
+// of the entries is given by Version, see TickerSchemaVersion. Seq is the
+// update the message is for, which deltas in delta mode are based on.
 type TickerStream struct {
+	Version int            `json:"version"`
+	Seq     uint64         `json:"seq"`
 	Tickers *[]interface{} `json:"tickers"`
 }
 
+// WsSourceUpdate is a single update from a WsSourceCache. It is encoded
+// once and shared by every subscriber. Only the encodings that had
+// subscribers when the update was built are set.
+type WsSourceUpdate struct {
+	// The entries as returned by the JSON builder.
+	Entries []interface{}
+
+	// The entries wrapped in a TickerStream, for the single feed sockets
+	// and as their snapshot in delta mode.
+	Prepared *websocket.PreparedMessage
+
+	// The entries wrapped in a WsMuxChannelMessage, for the multiplexed
+	// socket.
+	Channel *websocket.PreparedMessage
+
+	// The JSON the Prepared and DeltaMessage messages were prepared from,
+	// for Server-Sent Events clients.
+	Payload      []byte
+	DeltaPayload []byte
+
+	// The typed entries wrapped in a WsMuxChannelMessage and encoded as
+	// MessagePack.
+	Binary *websocket.PreparedMessage
+
+	// The entries as Protocol Buffers messages, for gRPC clients.
+	Proto []interface{}
+
+	// Sequence number of this update, starting at 1.
+	Seq uint64
+
+	// The changes since the previous update, and the same encoded.
+	Delta        *WsDelta
+	DeltaMessage *websocket.PreparedMessage
+}
+
+// Snapshot returns the full channel message in the given encoding.
+func (u *WsSourceUpdate) Snapshot(encoding WsEncoding) *websocket.PreparedMessage {
+	if encoding == WsEncodingMsgpack {
+		return u.Binary
+	}
+	return u.Channel
+}
+
 type WsSourceCache struct {
-	subscribers map[chan *websocket.PreparedMessage]bool
-	source      chan *TickerTrackerMap
-	builder     func(trackerMap *TickerTrackerMap) []interface{}
+	name          string
+	subscribers   map[wsSubscriber]bool
+	source        chan *TickerTrackerMap
+	builder       func(trackerMap *TickerTrackerMap) []interface{}
+	binaryBuilder func(trackerMap *TickerTrackerMap) []interface{}
+	protoBuilder  func(trackerMap *TickerTrackerMap) []interface{}
+	lock          sync.RWMutex
+
+	seq      uint64
+	previous map[string]map[string]interface{}
+	history  []*WsDelta
+	last     *WsSourceUpdate
 }
 
-func NewWsSourceCache(source chan *TickerTrackerMap, builder func(trackerMap *TickerTrackerMap) []interface{}) *WsSourceCache {
+// NewWsSourceCache creates a feed from the entries returned by builder for
+// JSON clients, binaryBuilder for MessagePack clients and protoBuilder for
+// gRPC clients. The last two may be nil if the feed has no such clients.
+func NewWsSourceCache(name string, source chan *TickerTrackerMap,
+	builder func(trackerMap *TickerTrackerMap) []interface{},
+	binaryBuilder func(trackerMap *TickerTrackerMap) []interface{},
+	protoBuilder func(trackerMap *TickerTrackerMap) []interface{}) *WsSourceCache {
 	return &WsSourceCache{
-		subscribers: map[chan *websocket.PreparedMessage]bool{},
-		source:      source,
-		builder:     builder,
+		name:          name,
+		subscribers:   map[wsSubscriber]bool{},
+		source:        source,
+		builder:       builder,
+		binaryBuilder: binaryBuilder,
+		protoBuilder:  protoBuilder,
 	}
 }
 
-func (f *WsSourceCache) Subscribe() chan *websocket.PreparedMessage {
-	channel := make(chan *websocket.PreparedMessage, 1)
-	f.subscribers[channel] = true
-	return channel
+func (f *WsSourceCache) Name() string {
+	return f.name
 }
 
-func (f *WsSourceCache) Unsubscribe(channel chan *websocket.PreparedMessage) {
-	delete(f.subscribers, channel)
+// Subscribe queues updates for a client, tagged with key, until
+// unsubscribed.
+func (f *WsSourceCache) Subscribe(queue *WsSendQueue, key string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.subscribers[wsSubscriber{queue: queue, key: key}] = true
 }
 
-func (f *WsSourceCache) Run() {
-	for {
-		trackers := <-f.source
-		message := f.builder(trackers)
-		output := TickerStream{Tickers: &message}
-		buf, err := json.Marshal(output)
-		if err != nil {
-			log.Errorf("Failed to encode monitor message as JSON: %v", err)
-			continue
-		}
-		pm, err := websocket.NewPreparedMessage(websocket.TextMessage, buf)
-		if err != nil {
-			log.Errorf("Failed to prepare monitor websocket message: %v", err)
+func (f *WsSourceCache) Unsubscribe(queue *WsSendQueue, key string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.subscribers, wsSubscriber{queue: queue, key: key})
+}
+
+func prepareJsonMessage(v interface{}) (*websocket.PreparedMessage, error) {
+	return WsEncodingJSON.Prepare(v)
+}
+
+// encodeJsonMessage returns the JSON encoding of v along with the same
+// prepared as a WebSocket message.
+func encodeJsonMessage(v interface{}) ([]byte, *websocket.PreparedMessage, error) {
+	buf, err := WsEncodingJSON.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	pm, err := websocket.NewPreparedMessage(websocket.TextMessage, buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return buf, pm, nil
+}
+
+// Last returns the most recent update, or nil if there has been none.
+func (f *WsSourceCache) Last() *WsSourceUpdate {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.last
+}
+
+// DeltaSince returns the changes from update base up to, but not beyond,
+// update seq. Nil is returned if the history no longer goes back to base.
+func (f *WsSourceCache) DeltaSince(base uint64, seq uint64) *WsDelta {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	for i, delta := range f.history {
+		if delta.Base != base {
 			continue
 		}
-		for subscriber := range f.subscribers {
-			select {
-			case subscriber <- pm:
-			default:
+		deltas := []*WsDelta{}
+		for _, next := range f.history[i:] {
+			if next.Seq > seq {
+				break
 			}
+			deltas = append(deltas, next)
+		}
+		if len(deltas) == 0 {
+			return nil
 		}
+		return mergeWsDeltas(deltas)
 	}
+	return nil
 }
 
-func WsBuildCompleteMessage(trackers *TickerTrackerMap) []interface{} {
-	entries := []interface{}{}
-	for key := range trackers.Trackers {
-		tracker := trackers.Trackers[key]
-		m := WsBuildCompleteEntry(tracker)
-		if m != nil {
-			entries = append(entries, m)
+// encodings returns which encodings the current subscribers use.
+func (f *WsSourceCache) encodings() (json bool, binary bool, proto bool) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	for subscriber := range f.subscribers {
+		switch subscriber.queue.encoding {
+		case WsEncodingMsgpack:
+			binary = true
+		case WsEncodingProto:
+			proto = true
+		default:
+			json = true
 		}
 	}
-	return entries
+	return json, binary, proto
 }
 
-func WsBuildCompleteEntry(tracker *TickerTracker) map[string]interface{} {
-	last := tracker.LastTick()
-	if last == nil {
-		return nil
+func (f *WsSourceCache) buildJson(trackers *TickerTrackerMap, update *WsSourceUpdate) error {
+	message := f.builder(trackers)
+	payload, pm, err := encodeJsonMessage(TickerStream{
+		Version: TickerSchemaVersion,
+		Seq:     update.Seq,
+		Tickers: &message,
+	})
+	if err != nil {
+		return err
 	}
-	key := tracker.Symbol
-
-	message := map[string]interface{}{
-		"symbol": key,
-		"close":  last.CurrentDayClose,
-		"bid":    last.Bid,
-		"ask":    last.Ask,
-		"high":   last.HighPrice,
-		"low":    last.LowPrice,
-		"volume": last.TotalQuoteVolume,
-
-		"price_change_pct": map[string]float64{
-			"1m":  tracker.Metrics[1].PriceChangePercent,
-			"5m":  tracker.Metrics[5].PriceChangePercent,
-			"10m": tracker.Metrics[10].PriceChangePercent,
-			"15m": tracker.Metrics[15].PriceChangePercent,
-			"1h":  tracker.Metrics[60].PriceChangePercent,
-			"24h": tracker.LastTick().PriceChangePercent,
-		},
-
-		"volume_change_pct": map[string]float64{
-			"1m":  tracker.Metrics[1].VolumeChangePercent,
-			"2m":  tracker.Metrics[2].VolumeChangePercent,
-			"3m":  tracker.Metrics[3].VolumeChangePercent,
-			"5m":  tracker.Metrics[5].VolumeChangePercent,
-			"10m": tracker.Metrics[10].VolumeChangePercent,
-			"15m": tracker.Metrics[15].VolumeChangePercent,
-			"1h":  tracker.Metrics[60].VolumeChangePercent,
-		},
-
-		"timestamp": last.Timestamp(),
+	channel, err := prepareJsonMessage(WsMuxChannelMessage{
+		Type:    "channel",
+		Version: TickerSchemaVersion,
+		Channel: f.name,
+		Seq:     update.Seq,
+		Tickers: message,
+	})
+	if err != nil {
+		return err
 	}
-
-	for _, bucket := range Buckets {
-		metrics := tracker.Metrics[bucket]
-
-		message[fmt.Sprintf("l_%d", bucket)] = metrics.Low
-		message[fmt.Sprintf("h_%d", bucket)] = metrics.High
-
-		message[fmt.Sprintf("r_%d", bucket)] = metrics.Range
-		message[fmt.Sprintf("rp_%d", bucket)] = metrics.RangePercent
+	update.Entries = message
+	update.Prepared = pm
+	update.Payload = payload
+	update.Channel = channel
+
+	entries := wsEntryMap(message)
+	if f.previous != nil {
+		changed, removed := wsDiffEntries(f.previous, entries)
+		update.Delta = &WsDelta{
+			Type:    "delta",
+			Channel: f.name,
+			Seq:     update.Seq,
+			Base:    f.seq,
+			Changed: changed,
+			Removed: removed,
+		}
+		update.DeltaPayload, update.DeltaMessage, err = encodeJsonMessage(update.Delta)
+		if err != nil {
+			return err
+		}
 	}
+	f.previous = entries
+	return nil
+}
 
-	message["r_24"] = tracker.H24Metrics.Range
-	if math.IsNaN(tracker.H24Metrics.RangePercent) {
-		message["rp_24"] = 0
-	} else {
-		message["rp_24"] = tracker.H24Metrics.RangePercent
+func (f *WsSourceCache) buildBinary(trackers *TickerTrackerMap, update *WsSourceUpdate) error {
+	binary, err := WsEncodingMsgpack.Prepare(WsMuxChannelMessage{
+		Type:    "channel",
+		Version: TickerSchemaVersion,
+		Channel: f.name,
+		Seq:     update.Seq,
+		Tickers: f.binaryBuilder(trackers),
+	})
+	if err != nil {
+		return err
 	}
+	update.Binary = binary
+	return nil
+}
 
-	if tracker.HaveVwap {
-		for i, k := range tracker.Metrics {
-			message[fmt.Sprintf("vwap_%dm", i)] = Round8(k.Vwap)
+func (f *WsSourceCache) Run() {
+	for {
+		trackers := <-f.source
+		update := &WsSourceUpdate{
+			Seq: f.seq + 1,
 		}
-	}
 
-	if tracker.HaveTotalVolume {
-		for i, k := range tracker.Metrics {
-			message[fmt.Sprintf("total_volume_%d", i)] = Round8(k.TotalVolume)
+		// Only build the encodings that are in use. Without JSON
+		// subscribers the delta history is dropped as there is nobody
+		// to catch up.
+		wantJson, wantBinary, wantProto := f.encodings()
+		if wantJson {
+			if err := f.buildJson(trackers, update); err != nil {
+				log.Errorf("Failed to prepare %s websocket message: %v", f.name, err)
+				continue
+			}
+		} else {
+			f.previous = nil
+		}
+		if wantBinary && f.binaryBuilder != nil {
+			if err := f.buildBinary(trackers, update); err != nil {
+				log.Errorf("Failed to prepare %s binary websocket message: %v", f.name, err)
+				continue
+			}
+		}
+		if wantProto && f.protoBuilder != nil {
+			update.Proto = f.protoBuilder(trackers)
 		}
-	}
 
-	if tracker.HaveNetVolume {
-		for i, k := range tracker.Metrics {
-			message[fmt.Sprintf("nv_%d", i)] = Round8(k.NetVolume)
-			message[fmt.Sprintf("bv_%d", i)] = Round8(k.BuyVolume)
-			message[fmt.Sprintf("sv_%d", i)] = Round8(k.SellVolume)
+		f.lock.Lock()
+		f.seq = update.Seq
+		f.last = update
+		if update.Delta != nil {
+			f.history = append(f.history, update.Delta)
+			if len(f.history) > wsDeltaHistory {
+				f.history = f.history[1:]
+			}
+		} else if !wantJson {
+			f.history = nil
 		}
-	}
+		f.lock.Unlock()
 
-	for i, k := range tracker.Metrics {
-		if !math.IsNaN(k.RSI) {
-			message[fmt.Sprintf("rsi_%d", i*60)] = Round8(k.RSI)
+		f.lock.RLock()
+		for subscriber := range f.subscribers {
+			subscriber.queue.Push(wsQueueItem{
+				key:    subscriber.key,
+				update: update,
+			})
 		}
+		f.lock.RUnlock()
 	}
-
-	return message
 }
 
-func WsBuildMonitorMessage(trackers *TickerTrackerMap) []interface{} {
+func WsBuildCompleteMessage(trackers *TickerTrackerMap) []interface{} {
 	entries := []interface{}{}
 	for key := range trackers.Trackers {
 		tracker := trackers.Trackers[key]
-		last := tracker.LastTick()
-		if last == nil {
-			continue
+		m := WsBuildCompleteEntry(tracker)
+		if m != nil {
+			entries = append(entries, m)
 		}
-		key := tracker.Symbol
-
-		entry := map[string]interface{}{
-			"symbol": key,
-			"close":  last.CurrentDayClose,
-			"bid":    last.Bid,
-			"ask":    last.Ask,
-			"high":   last.HighPrice,
-			"low":    last.LowPrice,
-			"volume": last.TotalQuoteVolume,
-
-			"price_change_pct": map[string]float64{
-				"1m":  tracker.Metrics[1].PriceChangePercent,
-				"5m":  tracker.Metrics[5].PriceChangePercent,
-				"10m": tracker.Metrics[10].PriceChangePercent,
-				"15m": tracker.Metrics[15].PriceChangePercent,
-				"1h":  tracker.Metrics[60].PriceChangePercent,
-				"24h": tracker.LastTick().PriceChangePercent,
-			},
+	}
+	return entries
+}
 
-			"volume_change_pct": map[string]float64{
-				"1m":  tracker.Metrics[1].VolumeChangePercent,
-				"2m":  tracker.Metrics[2].VolumeChangePercent,
-				"3m":  tracker.Metrics[3].VolumeChangePercent,
-				"5m":  tracker.Metrics[5].VolumeChangePercent,
-				"10m": tracker.Metrics[10].VolumeChangePercent,
-				"15m": tracker.Metrics[15].VolumeChangePercent,
-				"1h":  tracker.Metrics[60].VolumeChangePercent,
-			},
+// WsBuildCompleteEntry returns the live feed entry of a tracker, or nil if
+// it has not received a ticker yet.
+func WsBuildCompleteEntry(tracker *TickerTracker) map[string]interface{} {
+	entry := NewCompleteEntry(tracker)
+	if entry == nil {
+		return nil
+	}
+	return entry.Map()
+}
 
-			"timestamp": last.Timestamp(),
+func WsBuildMonitorMessage(trackers *TickerTrackerMap) []interface{} {
+	entries := []interface{}{}
+	for key := range trackers.Trackers {
+		entry := NewMonitorEntry(trackers.Trackers[key])
+		if entry != nil {
+			entries = append(entries, entry.Map())
 		}
-		entries = append(entries, entry)
 	}
-
 	return entries
 }
@@ -0,0 +1,299 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestWsClient(remoteAddr string, options WsQueueOptions) *WebSocketClient {
+	client := NewStreamClient(&http.Request{RemoteAddr: remoteAddr, Header: http.Header{}}, WsEncodingJSON)
+	client.queue = client.newQueue(options)
+	return client
+}
+
+func TestWsConnectionTrackerDel(t *testing.T) {
+	tracker := NewWsConnectionTracker()
+	client := newTestWsClient("1.2.3.4:5000", WsQueueOptions{Depth: 1})
+	tracker.Add("/ws/binance", client)
+	tracker.Add("/ws/binance/live", client)
+	client.queue.Push(wsQueueItem{key: "live"})
+	client.queue.Push(wsQueueItem{key: "live"})
+
+	tracker.Del("/ws/binance/live", client)
+	if len(tracker.Clients) != 1 || tracker.Paths["/ws/binance/live"] != nil {
+		t.Fatalf("expected the client with one path, got %v %v", tracker.Clients, tracker.Paths)
+	}
+	if client.queue.Len() != 1 {
+		t.Errorf("expected the queue to be kept while the client has paths")
+	}
+
+	tracker.Del("/ws/binance", client)
+	if len(tracker.Clients) != 0 || len(tracker.Paths) != 0 {
+		t.Fatalf("expected no clients or paths, got %v %v", tracker.Clients, tracker.Paths)
+	}
+	if client.queue.Len() != 0 {
+		t.Errorf("expected the queue to be cleared")
+	}
+	client.queue.Push(wsQueueItem{key: "live"})
+	if client.queue.Len() != 0 || client.queue.Dropped() != 1 {
+		t.Errorf("expected pushes to a closed queue to be ignored")
+	}
+	if tracker.Dropped != 1 || tracker.Disconnected != 0 {
+		t.Errorf("expected 1 dropped and 0 disconnected, got %d and %d",
+			tracker.Dropped, tracker.Disconnected)
+	}
+}
+
+// TestWsSendQueueSubscriptions checks that the queue depth applies to each
+// subscription, as for a client multiplexing many symbols.
+func TestWsSendQueueSubscriptions(t *testing.T) {
+	for _, policy := range []WsQueuePolicy{WsQueueDropOldest, WsQueueDisconnect} {
+		client := newTestWsClient("1.2.3.4:5000", WsQueueOptions{Depth: 2, Policy: policy})
+		for i := 0; i < 2; i++ {
+			for _, symbol := range []string{"ETHBTC", "LTCBTC", "XRPBTC"} {
+				client.queue.Push(wsQueueItem{key: "symbol:" + symbol, symbol: symbol})
+			}
+		}
+		if client.queue.Len() != 6 || client.queue.Dropped() != 0 || client.queue.Overflowed() {
+			t.Fatalf("%v: expected 6 queued and none dropped, got %d and %d",
+				policy, client.queue.Len(), client.queue.Dropped())
+		}
+
+		client.queue.Push(wsQueueItem{key: "symbol:LTCBTC", symbol: "LTCBTC", message: 3})
+		if policy == WsQueueDisconnect {
+			if !client.queue.Overflowed() || client.queue.Len() != 0 {
+				t.Errorf("%v: expected the client to be disconnected", policy)
+			}
+			continue
+		}
+		expected := []string{"ETHBTC", "XRPBTC", "ETHBTC", "LTCBTC", "XRPBTC", "LTCBTC"}
+		for i, symbol := range expected {
+			item, ok := client.queue.Pop()
+			if !ok || item.symbol != symbol {
+				t.Fatalf("%v: expected %s at %d, got %+v", policy, symbol, i, item)
+			}
+			if i == len(expected)-1 && item.message != 3 {
+				t.Errorf("%v: expected the newest LTCBTC item last, got %+v", policy, item)
+			}
+		}
+		if client.queue.Dropped() != 1 || client.queue.Len() != 0 {
+			t.Errorf("%v: expected 1 dropped and an empty queue, got %d and %d",
+				policy, client.queue.Dropped(), client.queue.Len())
+		}
+	}
+}
+
+func TestWebSocketsStatus(t *testing.T) {
+	tracker := NewWsConnectionTracker()
+	defer func(saved *WsConnectionTracker) { wsConnectionTracker = saved }(wsConnectionTracker)
+	wsConnectionTracker = tracker
+
+	// Two connections from the same address, one dropping updates and one
+	// disconnected for falling behind, and one that has closed.
+	dropping := newTestWsClient("1.2.3.4:5000", WsQueueOptions{Depth: 1})
+	slow := newTestWsClient("1.2.3.4:5001", WsQueueOptions{Depth: 1, Policy: WsQueueDisconnect})
+	closed := newTestWsClient("5.6.7.8:5000", WsQueueOptions{Depth: 1, Policy: WsQueueDisconnect})
+	for _, client := range []*WebSocketClient{dropping, slow, closed} {
+		tracker.Add("/ws/binance/live", client)
+		for i := 0; i < 3; i++ {
+			client.queue.Push(wsQueueItem{key: "live"})
+		}
+	}
+	tracker.Del("/ws/binance/live", closed)
+
+	response := httptest.NewRecorder()
+	webSocketsStatusHandler(response, httptest.NewRequest("GET", "/api/1/status/websockets", nil))
+	status := WebSocketsStatusResponse{}
+	if err := json.Unmarshal(response.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(status.Connections) != 2 {
+		t.Fatalf("expected 2 connections, got %+v", status.Connections)
+	}
+	first, second := status.Connections[0], status.Connections[1]
+	if first.Id != dropping.id || first.Dropped != 2 || first.Queued != 1 || first.Disconnected {
+		t.Errorf("unexpected status of the dropping connection: %+v", first)
+	}
+	if second.Id != slow.id || second.Dropped != 1 || second.Queued != 0 || !second.Disconnected {
+		t.Errorf("unexpected status of the slow connection: %+v", second)
+	}
+	if first.Client != second.Client || len(status.Clients[first.Client]) != 2 {
+		t.Errorf("expected both connections under one client: %+v", status.Clients)
+	}
+	if status.Dropped != 4 || status.Disconnected != 2 {
+		t.Errorf("expected totals of 4 dropped and 2 disconnected, got %d and %d",
+			status.Dropped, status.Disconnected)
+	}
+}
+
+// newTestSourceUpdates returns two consecutive updates of the live feed,
+// the second with the delta from the first.
+func newTestSourceUpdates(t *testing.T) (*WsSourceCache, []*WsSourceUpdate) {
+	source := NewWsSourceCache("live", nil, WsBuildCompleteMessage, nil, nil)
+	trackers, _ := newTestTrackers(goldenNow)
+	updates := []*WsSourceUpdate{}
+	for seq := uint64(1); seq <= 2; seq++ {
+		update := &WsSourceUpdate{Seq: seq}
+		if err := source.buildJson(trackers, update); err != nil {
+			t.Fatal(err)
+		}
+		source.seq = seq
+		updates = append(updates, update)
+	}
+	return source, updates
+}
+
+// checkDeltaMessages checks that the first message is the single feed
+// snapshot and the second a delta based on it.
+func checkDeltaMessages(t *testing.T, messages [][]byte) {
+	snapshot := map[string]interface{}{}
+	if err := json.Unmarshal(messages[0], &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot["version"] != float64(TickerSchemaVersion) || snapshot["seq"] != float64(1) ||
+		snapshot["type"] != nil || snapshot["tickers"] == nil {
+		t.Errorf("expected a single feed snapshot of update 1, got %.100s", messages[0])
+	}
+	delta := WsDelta{}
+	if err := json.Unmarshal(messages[1], &delta); err != nil {
+		t.Fatal(err)
+	}
+	if delta.Type != "delta" || delta.Base != 1 || delta.Seq != 2 {
+		t.Errorf("expected the delta from update 1 to 2, got %.100s", messages[1])
+	}
+}
+
+func TestTickerWebSocketDeltaSnapshot(t *testing.T) {
+	source, updates := newTestSourceUpdates(t)
+	handler := &TickerWebSocketHandler{source: source}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, err := handler.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer client.conn.Close()
+		deltaState := &wsDeltaState{}
+		for _, update := range updates {
+			if err := handler.writeUpdate(client, update, deltaState); err != nil {
+				t.Error(err)
+			}
+		}
+		client.conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	messages := [][]byte{}
+	for range updates {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	checkDeltaMessages(t, messages)
+}
+
+func TestSseDeltaSnapshot(t *testing.T) {
+	source, updates := newTestSourceUpdates(t)
+	handler := NewSseHandler(source)
+	response := httptest.NewRecorder()
+	deltaState := &wsDeltaState{}
+	for _, update := range updates {
+		if err := handler.writeUpdate(response, update, deltaState); err != nil {
+			t.Fatal(err)
+		}
+	}
+	messages := [][]byte{}
+	for _, event := range strings.Split(strings.TrimSpace(response.Body.String()), "\n\n") {
+		lines := strings.Split(event, "\n")
+		messages = append(messages, []byte(strings.TrimPrefix(lines[1], "data: ")))
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(messages))
+	}
+	checkDeltaMessages(t, messages)
+}
+
+// sseStalledWriter is the response writer of a client that stopped
+// reading: writes fail once their deadline has passed.
+type sseStalledWriter struct {
+	*httptest.ResponseRecorder
+	deadlines int
+	writes    int
+}
+
+func (w *sseStalledWriter) SetWriteDeadline(deadline time.Time) error {
+	w.deadlines++
+	return nil
+}
+
+func (w *sseStalledWriter) Write(b []byte) (int, error) {
+	w.writes++
+	return 0, os.ErrDeadlineExceeded
+}
+
+// TestSseWriteTimeout checks that a client that stops reading is
+// disconnected when the write deadline passes.
+func TestSseWriteTimeout(t *testing.T) {
+	source, updates := newTestSourceUpdates(t)
+	source.last = updates[0]
+	handler := NewSseHandler(source)
+	writer := &sseStalledWriter{ResponseRecorder: httptest.NewRecorder()}
+
+	done := make(chan bool)
+	go func() {
+		handler.Handle(writer, httptest.NewRequest("GET", "/sse/binance/live", nil))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stalled client to be disconnected")
+	}
+	if writer.writes != 1 || writer.deadlines < 2 {
+		t.Errorf("expected a deadline before each write and flush, got %d writes and %d deadlines",
+			writer.writes, writer.deadlines)
+	}
+	source.lock.RLock()
+	defer source.lock.RUnlock()
+	if len(source.subscribers) != 0 {
+		t.Errorf("expected the client to be unsubscribed")
+	}
+}
@@ -0,0 +1,183 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"sort"
+	"time"
+)
+
+// WhaleOptions select the trades that are classified as whale trades. A
+// trade is a whale trade when its quote value is above the percentile of
+// the recent trade sizes of the symbol and, if MinUSD is set, worth more
+// than MinUSD. A percentile of 0 leaves only the USD
+// threshold.
+type WhaleOptions struct {
+	Percentile float64
+
+	// Minimum number of recent trades for the percentile to be used.
+	MinTrades int
+
+	MinUSD float64
+}
+
+var DefaultWhaleOptions = WhaleOptions{
+	Percentile: 99.5,
+	MinTrades:  200,
+	MinUSD:     0,
+}
+
+var whaleOptions = DefaultWhaleOptions
+
+// How often the percentile threshold of a symbol is recalculated.
+const whaleThresholdInterval = time.Minute
+
+// WhaleTrade is a trade classified as a whale trade.
+type WhaleTrade struct {
+	Symbol        string    `json:"symbol" msgpack:"symbol"`
+	Side          string    `json:"side" msgpack:"side"`
+	Price         float64   `json:"price" msgpack:"price"`
+	Quantity      float64   `json:"quantity" msgpack:"quantity"`
+	QuoteQuantity float64   `json:"quote_quantity" msgpack:"quote_quantity"`
+	USD           *float64  `json:"usd,omitempty" msgpack:"usd,omitempty"`
+	Threshold     float64   `json:"threshold" msgpack:"threshold"`
+	Timestamp     time.Time `json:"timestamp" msgpack:"timestamp"`
+
+	// Change in percent from the price of the previous trade.
+	ImpactPercent float64 `json:"impact_pct" msgpack:"impact_pct"`
+}
+
+// whaleThreshold returns the quote value above which a trade is a whale
+// trade, 0 if no threshold is known yet.
+func (t *TickerTracker) whaleThreshold(now time.Time) float64 {
+	if whaleOptions.Percentile > 0 && now.Sub(t.whaleThresholdTime) >= whaleThresholdInterval {
+		t.whaleThresholdTime = now
+		t.WhalePercentileThreshold = 0
+		if len(t.Trades) >= whaleOptions.MinTrades && len(t.Trades) > 0 {
+			sizes := make([]float64, len(t.Trades))
+			for i, trade := range t.Trades {
+				sizes[i] = trade.QuoteQuantity()
+			}
+			sort.Float64s(sizes)
+			index := int(float64(len(sizes)-1) * whaleOptions.Percentile / 100)
+			t.WhalePercentileThreshold = sizes[index]
+		}
+	}
+
+	threshold := t.WhalePercentileThreshold
+	if whaleOptions.MinUSD > 0 {
+		if t.QuoteUSD <= 0 {
+			return 0
+		}
+		if min := whaleOptions.MinUSD / t.QuoteUSD; min > threshold {
+			threshold = min
+		}
+	} else if whaleOptions.Percentile <= 0 {
+		return 0
+	}
+	return threshold
+}
+
+// classifyTrade records the trade as a whale trade if it is one. It must
+// be called before the trade is added to the trades.
+func (t *TickerTracker) classifyTrade(trade *binanceapi.StreamAggTrade) *WhaleTrade {
+	threshold := t.whaleThreshold(trade.Timestamp())
+	if threshold <= 0 || trade.QuoteQuantity() <= threshold {
+		return nil
+	}
+	t.WhaleTrades = append(t.WhaleTrades, trade)
+
+	whale := &WhaleTrade{
+		Symbol:        trade.Symbol,
+		Side:          "buy",
+		Price:         trade.Price,
+		Quantity:      trade.Quantity,
+		QuoteQuantity: Round8(trade.QuoteQuantity()),
+		Threshold:     Round8(threshold),
+		Timestamp:     trade.Timestamp(),
+	}
+	if trade.BuyerMaker {
+		whale.Side = "sell"
+	}
+	if t.QuoteUSD > 0 {
+		whale.USD = optionalFloat(Round8(trade.QuoteQuantity() * t.QuoteUSD))
+	}
+	if len(t.Trades) > 0 {
+		previous := t.Trades[len(t.Trades)-1].Price
+		if previous > 0 {
+			whale.ImpactPercent = Round3((trade.Price - previous) / previous * 100)
+		}
+	}
+	return whale
+}
+
+// CalculateWhaleTrades sets the whale trade metrics of each bucket.
+func (t *TickerTracker) CalculateWhaleTrades(now time.Time) {
+	for _, metrics := range t.Metrics {
+		metrics.WhaleBuyVolume = 0
+		metrics.WhaleSellVolume = 0
+		metrics.WhaleTrades = 0
+	}
+
+	buyVolume := float64(0)
+	sellVolume := float64(0)
+	trades := uint64(0)
+	for i := len(t.WhaleTrades) - 1; i >= 0; i-- {
+		trade := t.WhaleTrades[i]
+		if trade.BuyerMaker {
+			sellVolume += trade.QuoteQuantity()
+		} else {
+			buyVolume += trade.QuoteQuantity()
+		}
+		trades++
+
+		// Unlike the other trade metrics every bucket is set as buckets
+		// without a whale trade of their own still include those of the
+		// smaller buckets.
+		bucket := int(now.Sub(trade.Timestamp()).Minutes()) + 1
+		for _, b := range Buckets {
+			if b >= bucket {
+				metrics := t.Metrics[b]
+				metrics.WhaleBuyVolume = buyVolume
+				metrics.WhaleSellVolume = sellVolume
+				metrics.WhaleTrades = trades
+			}
+		}
+	}
+}
+
+func (t *TickerTracker) pruneWhaleTrades(now time.Time) {
+	chop := 0
+	for i, trade := range t.WhaleTrades {
+		if now.Sub(trade.Timestamp()) < time.Minute*210 {
+			break
+		}
+		chop = i + 1
+	}
+	if chop > 0 {
+		t.WhaleTrades = t.WhaleTrades[chop:]
+	}
+}
@@ -0,0 +1,184 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"github.com/crankykernel/binanceapi-go"
+	"testing"
+	"time"
+)
+
+var whaleStart = time.Date(2019, 2, 15, 12, 0, 0, 0, time.UTC)
+
+func whaleTrade(at time.Duration, price float64, quantity float64, sell bool) *binanceapi.StreamAggTrade {
+	return &binanceapi.StreamAggTrade{
+		Symbol:     "ETHBTC",
+		Price:      price,
+		Quantity:   quantity,
+		TradeTime:  milliseconds(whaleStart.Add(at)),
+		BuyerMaker: sell,
+	}
+}
+
+func TestWhaleThreshold(t *testing.T) {
+	defer func() { whaleOptions = DefaultWhaleOptions }()
+
+	// Trades worth 1 to 10.
+	trades := []*binanceapi.StreamAggTrade{}
+	for i := 1; i <= 10; i++ {
+		trades = append(trades, whaleTrade(0, 1, float64(i), false))
+	}
+
+	tests := []struct {
+		name      string
+		options   WhaleOptions
+		quoteUSD  float64
+		threshold float64
+	}{
+		{"percentile", WhaleOptions{Percentile: 90, MinTrades: 5}, 0, 9},
+		{"too few trades", WhaleOptions{Percentile: 90, MinTrades: 20}, 0, 0},
+		{"usd only", WhaleOptions{MinUSD: 100}, 50, 2},
+		{"usd over percentile", WhaleOptions{Percentile: 90, MinTrades: 5, MinUSD: 1000}, 50, 20},
+		{"percentile over usd", WhaleOptions{Percentile: 90, MinTrades: 5, MinUSD: 100}, 50, 9},
+		{"usd without rate", WhaleOptions{Percentile: 90, MinTrades: 5, MinUSD: 100}, 0, 0},
+		{"disabled", WhaleOptions{}, 50, 0},
+	}
+	for _, test := range tests {
+		whaleOptions = test.options
+		tracker := NewTickerTracker("ETHBTC")
+		tracker.Trades = trades
+		tracker.QuoteUSD = test.quoteUSD
+		if threshold := tracker.whaleThreshold(whaleStart); threshold != test.threshold {
+			t.Errorf("%s: expected %v, got %v", test.name, test.threshold, threshold)
+		}
+	}
+
+	// The percentile is only recalculated once a minute.
+	whaleOptions = WhaleOptions{Percentile: 90, MinTrades: 5}
+	tracker := NewTickerTracker("ETHBTC")
+	tracker.Trades = trades
+	tracker.whaleThreshold(whaleStart)
+	tracker.Trades = append(trades, whaleTrade(0, 1, 100, false), whaleTrade(0, 1, 100, false))
+	if threshold := tracker.whaleThreshold(whaleStart.Add(59 * time.Second)); threshold != 9 {
+		t.Errorf("expected the threshold kept within a minute, got %v", threshold)
+	}
+	if threshold := tracker.whaleThreshold(whaleStart.Add(time.Minute)); threshold != 10 {
+		t.Errorf("expected the threshold recalculated after a minute, got %v", threshold)
+	}
+}
+
+func TestClassifyTrade(t *testing.T) {
+	defer func() { whaleOptions = DefaultWhaleOptions }()
+	whaleOptions = WhaleOptions{MinUSD: 100}
+
+	usd := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		quoteUSD float64
+		trade    *binanceapi.StreamAggTrade
+		expected *WhaleTrade
+	}{
+		{"at threshold", 50, whaleTrade(0, 2, 1, false), nil},
+		{"buy", 50, whaleTrade(0, 2.2, 2, false), &WhaleTrade{
+			Symbol: "ETHBTC", Side: "buy", Price: 2.2, Quantity: 2,
+			QuoteQuantity: 4.4, USD: usd(220), Threshold: 2,
+			Timestamp: whaleStart, ImpactPercent: 10,
+		}},
+		{"sell", 50, whaleTrade(0, 1.5, 4, true), &WhaleTrade{
+			Symbol: "ETHBTC", Side: "sell", Price: 1.5, Quantity: 4,
+			QuoteQuantity: 6, USD: usd(300), Threshold: 2,
+			Timestamp: whaleStart, ImpactPercent: -25,
+		}},
+		{"no rate", 0, whaleTrade(0, 2, 100, false), nil},
+	}
+	for _, test := range tests {
+		tracker := NewTickerTracker("ETHBTC")
+		tracker.QuoteUSD = test.quoteUSD
+		tracker.Trades = []*binanceapi.StreamAggTrade{whaleTrade(-time.Second, 2, 1, false)}
+		whale := tracker.classifyTrade(test.trade)
+		if test.expected == nil {
+			if whale != nil || len(tracker.WhaleTrades) != 0 {
+				t.Errorf("%s: expected no whale trade, got %+v", test.name, whale)
+			}
+			continue
+		}
+		if whale == nil {
+			t.Errorf("%s: expected a whale trade", test.name)
+			continue
+		}
+		if *whale.USD != *test.expected.USD {
+			t.Errorf("%s: expected %v USD, got %v", test.name, *test.expected.USD, *whale.USD)
+		}
+		whale.USD, test.expected.USD = nil, nil
+		if !whale.Timestamp.Equal(test.expected.Timestamp) {
+			t.Errorf("%s: expected time %v, got %v", test.name, test.expected.Timestamp, whale.Timestamp)
+		}
+		whale.Timestamp = test.expected.Timestamp
+		if *whale != *test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.name, *test.expected, *whale)
+		}
+		if len(tracker.WhaleTrades) != 1 || tracker.WhaleTrades[0] != test.trade {
+			t.Errorf("%s: expected the trade recorded", test.name)
+		}
+	}
+}
+
+func TestCalculateWhaleTrades(t *testing.T) {
+	now := whaleStart.Add(4 * time.Hour)
+	tracker := NewTickerTracker("ETHBTC")
+	tracker.WhaleTrades = []*binanceapi.StreamAggTrade{
+		whaleTrade(0, 1, 8, false),
+		whaleTrade(3*time.Hour+40*time.Minute, 1, 1, false),
+		whaleTrade(4*time.Hour-90*time.Second, 1, 3, true),
+		whaleTrade(4*time.Hour-30*time.Second, 1, 5, false),
+	}
+	tracker.pruneWhaleTrades(now)
+	if len(tracker.WhaleTrades) != 3 {
+		t.Errorf("expected the whale trade older than 210 minutes pruned, got %d",
+			len(tracker.WhaleTrades))
+	}
+	tracker.CalculateWhaleTrades(now)
+
+	tests := []struct {
+		bucket int
+		buy    float64
+		sell   float64
+		trades uint64
+	}{
+		{1, 5, 0, 1},
+		{2, 5, 3, 2},
+		{15, 5, 3, 2},
+		{60, 6, 3, 3},
+	}
+	for _, test := range tests {
+		metrics := tracker.Metrics[test.bucket]
+		if metrics.WhaleBuyVolume != test.buy || metrics.WhaleSellVolume != test.sell ||
+			metrics.WhaleTrades != test.trades {
+			t.Errorf("%d: expected %v/%v/%d, got %v/%v/%d", test.bucket,
+				test.buy, test.sell, test.trades, metrics.WhaleBuyVolume,
+				metrics.WhaleSellVolume, metrics.WhaleTrades)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"reflect"
+)
+
+// Delta mode is opt-in. A client first receives a full "channel" message
+// carrying a seq number, followed by "delta" messages that only contain the
+// fields that changed per entry. A delta applies to the state at its base
+// seq; if that is not the seq the client last applied, it has missed an
+// update and should send a "resync" command to get a new snapshot.
+
+// The number of deltas a WsSourceCache keeps so clients that skipped
+// updates can catch up without a full snapshot.
+const wsDeltaHistory = 60
+
+// WsDelta holds the changes between two updates of a feed. Changed entries
+// only contain their identifying key and the fields that changed.
+type WsDelta struct {
+	Type    string                   `json:"type"`
+	Channel string                   `json:"channel"`
+	Seq     uint64                   `json:"seq"`
+	Base    uint64                   `json:"base"`
+	Changed []map[string]interface{} `json:"changed"`
+	Removed []string                 `json:"removed,omitempty"`
+}
+
+// wsEntryKey returns the identifying key and value of a feed entry.
+func wsEntryKey(entry map[string]interface{}) (string, string) {
+	for _, key := range []string{"symbol", "asset"} {
+		if value, ok := entry[key].(string); ok {
+			return key, value
+		}
+	}
+	return "", ""
+}
+
+func wsEntryMap(entries []interface{}) map[string]map[string]interface{} {
+	entryMap := map[string]map[string]interface{}{}
+	for _, entry := range entries {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, id := wsEntryKey(m); id != "" {
+			entryMap[id] = m
+		}
+	}
+	return entryMap
+}
+
+// wsDiffEntries returns the changes going from the previous entries to the
+// next.
+func wsDiffEntries(prev map[string]map[string]interface{}, next map[string]map[string]interface{}) ([]map[string]interface{}, []string) {
+	changed := []map[string]interface{}{}
+	removed := []string{}
+	for id, entry := range next {
+		key, _ := wsEntryKey(entry)
+		old, exists := prev[id]
+		delta := map[string]interface{}{}
+		for field, value := range entry {
+			if exists {
+				if oldValue, ok := old[field]; ok && reflect.DeepEqual(oldValue, value) {
+					continue
+				}
+			}
+			delta[field] = value
+		}
+		if len(delta) > 0 {
+			delta[key] = id
+			changed = append(changed, delta)
+		}
+	}
+	for id := range prev {
+		if _, ok := next[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	return changed, removed
+}
+
+// mergeWsDeltas combines consecutive deltas into one going from the base of
+// the first to the seq of the last.
+func mergeWsDeltas(deltas []*WsDelta) *WsDelta {
+	first := deltas[0]
+	last := deltas[len(deltas)-1]
+	merged := &WsDelta{
+		Type:    "delta",
+		Channel: first.Channel,
+		Seq:     last.Seq,
+		Base:    first.Base,
+		Changed: []map[string]interface{}{},
+	}
+	changed := map[string]map[string]interface{}{}
+	removed := map[string]bool{}
+	order := []string{}
+	for _, delta := range deltas {
+		for _, entry := range delta.Changed {
+			key, id := wsEntryKey(entry)
+			if _, ok := changed[id]; !ok {
+				changed[id] = map[string]interface{}{key: id}
+				order = append(order, id)
+			}
+			for field, value := range entry {
+				changed[id][field] = value
+			}
+			delete(removed, id)
+		}
+		for _, id := range delta.Removed {
+			delete(changed, id)
+			removed[id] = true
+		}
+	}
+	for _, id := range order {
+		if entry, ok := changed[id]; ok {
+			merged.Changed = append(merged.Changed, entry)
+		}
+	}
+	for id := range removed {
+		merged.Removed = append(merged.Removed, id)
+	}
+	return merged
+}
+
+// wsDeltaState tracks what a delta mode client has been sent for one feed.
+type wsDeltaState struct {
+	seq    uint64
+	resync bool
+}
+
+// next returns what brings the client up to date with update: the shared
+// update.Delta if the client is on the previous update, a delta merged for
+// this client if it skipped some, or nil and snapshot set if a full
+// snapshot is required. Nothing is returned if the client is already up to
+// date.
+func (s *wsDeltaState) next(source *WsSourceCache, update *WsSourceUpdate) (delta *WsDelta, snapshot bool) {
+	if s.seq == update.Seq && !s.resync {
+		return nil, false
+	}
+	if s.seq != 0 && !s.resync {
+		if update.Delta != nil && update.Delta.Base == s.seq {
+			s.seq = update.Seq
+			return update.Delta, false
+		}
+		if merged := source.DeltaSince(s.seq, update.Seq); merged != nil {
+			s.seq = merged.Seq
+			return merged, false
+		}
+	}
+	s.seq = update.Seq
+	s.resync = false
+	return nil, true
+}
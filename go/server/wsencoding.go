@@ -0,0 +1,164 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+)
+
+// WebSocket clients may ask for MessagePack instead of JSON, either with
+// the "msgpack" subprotocol or an "encoding=msgpack" query parameter.
+// MessagePack payloads are the MonitorEntry and CompleteEntry of the JSON
+// maps, encoded with their msgpack tags so the two encodings share the
+// fields and TickerSchemaVersion. The bucket, profile and rolling fields
+// are grouped by window rather than flattened into the keys. They are sent
+// as binary messages. Commands from the client may be sent in either
+// encoding.
+//
+// gRPC stream clients share the feed queues with the Protocol Buffers
+// encoding, their messages are sent by the gRPC stream itself.
+
+type WsEncoding int
+
+const (
+	WsEncodingJSON WsEncoding = iota
+	WsEncodingMsgpack
+	WsEncodingProto
+)
+
+var wsSubprotocols = []string{"msgpack", "json"}
+
+func (e WsEncoding) String() string {
+	switch e {
+	case WsEncodingMsgpack:
+		return "msgpack"
+	case WsEncodingProto:
+		return "proto"
+	}
+	return "json"
+}
+
+// wsNegotiateEncoding picks the encoding for an upgraded connection. The
+// subprotocol takes precedence over the query parameter.
+func wsNegotiateEncoding(conn *websocket.Conn, r *http.Request) WsEncoding {
+	switch conn.Subprotocol() {
+	case "msgpack":
+		return WsEncodingMsgpack
+	case "json":
+		return WsEncodingJSON
+	}
+	if r.FormValue("encoding") == "msgpack" {
+		return WsEncodingMsgpack
+	}
+	return WsEncodingJSON
+}
+
+func (e WsEncoding) Marshal(v interface{}) ([]byte, error) {
+	switch e {
+	case WsEncodingMsgpack:
+		return msgpack.Marshal(v)
+	case WsEncodingProto:
+		message, ok := v.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("not a protocol buffers message: %T", v)
+		}
+		return proto.Marshal(message)
+	}
+	return json.Marshal(v)
+}
+
+func (e WsEncoding) MessageType() int {
+	if e == WsEncodingJSON {
+		return websocket.TextMessage
+	}
+	return websocket.BinaryMessage
+}
+
+func (e WsEncoding) Prepare(v interface{}) (*websocket.PreparedMessage, error) {
+	buf, err := e.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return websocket.NewPreparedMessage(e.MessageType(), buf)
+}
+
+// wsDecodeCommand decodes a client command, using the message type to tell
+// the encodings apart.
+func wsDecodeCommand(messageType int, buf []byte, command *WsMuxCommand) error {
+	if messageType == websocket.BinaryMessage {
+		return msgpack.Unmarshal(buf, command)
+	}
+	return json.Unmarshal(buf, command)
+}
+
+// WsSymbolUpdate is sent to the subscribers of a single symbol.
+type WsSymbolUpdate struct {
+	Entry map[string]interface{}
+	Typed *CompleteEntry
+}
+
+func NewWsSymbolUpdate(tracker *TickerTracker) *WsSymbolUpdate {
+	entry := NewCompleteEntry(tracker)
+	if entry == nil {
+		return &WsSymbolUpdate{}
+	}
+	return &WsSymbolUpdate{
+		Entry: entry.Map(),
+		Typed: entry,
+	}
+}
+
+func WsBuildTickerBinaryMessage(trackers *TickerTrackerMap) []interface{} {
+	entries := []interface{}{}
+	for _, tracker := range trackers.Trackers {
+		if entry := NewCompleteEntry(tracker); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func WsBuildMonitorBinaryMessage(trackers *TickerTrackerMap) []interface{} {
+	entries := []interface{}{}
+	for _, tracker := range trackers.Trackers {
+		if entry := NewMonitorEntry(tracker); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func WsBuildAssetBinaryMessage(trackers *TickerTrackerMap) []interface{} {
+	entries := []interface{}{}
+	for _, asset := range AggregateAssets(trackers) {
+		entries = append(entries, asset)
+	}
+	return entries
+}
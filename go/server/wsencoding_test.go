@@ -0,0 +1,196 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/crankykernel/binanceapi-go"
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// msgpackFieldNames maps the msgpack names of the fields of struct type t
+// to their JSON names for the given bucket or window.
+func msgpackFieldNames(t reflect.Type, tag string, bucket int) map[string]string {
+	names := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("msgpack"), ",")[0]
+		if name == "" {
+			continue
+		}
+		names[name] = entryFieldName(field, tag, bucket)
+	}
+	return names
+}
+
+// msgpackNumber converts a decoded MessagePack number to a float64.
+func msgpackNumber(v interface{}) interface{} {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	case reflect.Map:
+		m := map[string]interface{}{}
+		for _, key := range value.MapKeys() {
+			m[fmt.Sprint(key.Interface())] = msgpackNumber(value.MapIndex(key).Interface())
+		}
+		return m
+	}
+	return v
+}
+
+// TestMsgpackCompleteEntry checks that the MessagePack encoding of an
+// entry has the same fields and values as the JSON encoding.
+func TestMsgpackCompleteEntry(t *testing.T) {
+	_, tracker := newTestTrackers(goldenNow)
+	entry := NewCompleteEntry(tracker)
+
+	expected := map[string]interface{}{}
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf, &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err = msgpack.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := map[string]interface{}{}
+	if err := msgpack.Unmarshal(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := map[string]interface{}{}
+	groups := map[string]struct {
+		t   reflect.Type
+		tag string
+	}{
+		"buckets":  {reflect.TypeOf(CompleteBucketEntry{}), "bucket"},
+		"profiles": {reflect.TypeOf(CompleteProfileEntry{}), "bucket"},
+		"rolling":  {reflect.TypeOf(CompleteRollingEntry{}), "window"},
+	}
+	for key, value := range decoded {
+		group, ok := groups[key]
+		if !ok {
+			if timestamp, ok := value.(*time.Time); ok {
+				value = timestamp.UTC().Format(time.RFC3339)
+			}
+			actual[key] = msgpackNumber(value)
+			continue
+		}
+		for window, fields := range msgpackNumber(value).(map[string]interface{}) {
+			minutes := 0
+			fmt.Sscan(window, &minutes)
+			names := msgpackFieldNames(group.t, group.tag, minutes)
+			for name, field := range fields.(map[string]interface{}) {
+				if names[name] == "" {
+					t.Errorf("%s %s: unknown field %s", key, window, name)
+				}
+				actual[names[name]] = field
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		for key, value := range expected {
+			if !reflect.DeepEqual(value, actual[key]) {
+				t.Errorf("%s: expected %v, got %v", key, value, actual[key])
+			}
+		}
+		for key := range actual {
+			if _, ok := expected[key]; !ok {
+				t.Errorf("%s: unexpected", key)
+			}
+		}
+	}
+}
+
+// TestMsgpackFeedMessage checks that the single feed sockets send
+// MessagePack clients the schema version with the entries.
+func TestMsgpackFeedMessage(t *testing.T) {
+	trackers := NewTickerTrackerMap()
+	trackers.GetTracker("ETHBTC").Update(binanceapi.TickerStreamMessage{
+		Symbol:          "ETHBTC",
+		EventTime:       milliseconds(goldenNow),
+		CurrentDayClose: 0.03,
+	})
+	source := NewWsSourceCache("live", nil, WsBuildCompleteMessage, WsBuildTickerBinaryMessage, nil)
+	update := &WsSourceUpdate{Seq: 1}
+	if err := source.buildBinary(trackers, update); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &TickerWebSocketHandler{source: source}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, err := handler.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer client.conn.Close()
+		if err := handler.writeUpdate(client, update, nil); err != nil {
+			t.Error(err)
+		}
+		client.conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?encoding=msgpack"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	messageType, buf, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messageType != websocket.BinaryMessage {
+		t.Errorf("expected a binary message, got %d", messageType)
+	}
+	message := WsMuxChannelMessage{}
+	if err := msgpack.Unmarshal(buf, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.Version != TickerSchemaVersion || message.Type != "channel" ||
+		message.Channel != "live" || message.Seq != 1 || len(message.Tickers) != 1 {
+		t.Errorf("unexpected message %+v", message)
+	}
+}
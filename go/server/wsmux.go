@@ -0,0 +1,560 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"fmt"
+	"github.com/gorilla/websocket"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The multiplexed WebSocket carries every feed over a single connection.
+// Clients send JSON commands:
+//
+//	{"id": 1, "cmd": "subscribe", "channels": ["live"], "symbols": ["BTCUSDT"]}
+//	{"id": 2, "cmd": "unsubscribe", "symbols": ["BTCUSDT"]}
+//	{"id": 3, "cmd": "set", "fields": ["symbol", "close", "nv_15"], "throttle": 5}
+//	{"id": 4, "cmd": "ping"}
+//	{"id": 5, "cmd": "subscribe", "channels": ["live"], "delta": true}
+//	{"id": 6, "cmd": "resync", "channels": ["live"]}
+//
+// Every command is answered with an "ack", "pong" or "error" message
+// carrying the same id. Channel updates are sent as WsMuxChannelMessage and
+// symbol updates as WsMuxSymbolMessage. With the MessagePack encoding the
+// entries are the typed entries and delta mode and field selection are not
+// available.
+
+type WsMuxCommand struct {
+	ID       interface{} `json:"id,omitempty" msgpack:"id,omitempty"`
+	Cmd      string      `json:"cmd" msgpack:"cmd"`
+	Channels []string    `json:"channels,omitempty" msgpack:"channels,omitempty"`
+	Symbols  []string    `json:"symbols,omitempty" msgpack:"symbols,omitempty"`
+
+	// Limit entries to these keys. An empty list restores all keys.
+	Fields []string `json:"fields,omitempty" msgpack:"fields,omitempty"`
+
+	// Minimum seconds between updates of each subscription.
+	Throttle *int64 `json:"throttle,omitempty" msgpack:"throttle,omitempty"`
+
+	// Subscribe to the channels in delta mode.
+	Delta bool `json:"delta,omitempty" msgpack:"delta,omitempty"`
+}
+
+type WsMuxReply struct {
+	Type     string      `json:"type" msgpack:"type"`
+	ID       interface{} `json:"id,omitempty" msgpack:"id,omitempty"`
+	Cmd      string      `json:"cmd,omitempty" msgpack:"cmd,omitempty"`
+	Error    string      `json:"error,omitempty" msgpack:"error,omitempty"`
+	Channels []string    `json:"channels,omitempty" msgpack:"channels,omitempty"`
+	Symbols  []string    `json:"symbols,omitempty" msgpack:"symbols,omitempty"`
+}
+
+// WsMuxChannelMessage is an update of a channel. It is also the message
+// of the single feed sockets with the MessagePack encoding. The format of
+// the entries is given by Version, see TickerSchemaVersion.
+type WsMuxChannelMessage struct {
+	Type    string        `json:"type" msgpack:"type"`
+	Version int           `json:"version" msgpack:"version"`
+	Channel string        `json:"channel" msgpack:"channel"`
+	Seq     uint64        `json:"seq" msgpack:"seq"`
+	Tickers []interface{} `json:"tickers" msgpack:"tickers"`
+}
+
+type WsMuxSymbolMessage struct {
+	Type   string      `json:"type" msgpack:"type"`
+	Symbol string      `json:"symbol" msgpack:"symbol"`
+	Ticker interface{} `json:"ticker" msgpack:"ticker"`
+}
+
+type WsMuxHandler struct {
+	upgrader      websocket.Upgrader
+	binanceRunner *BinanceRunner
+	sources       map[string]*WsSourceCache
+}
+
+func NewWsMuxHandler(binanceRunner *BinanceRunner, sources ...*WsSourceCache) *WsMuxHandler {
+	handler := &WsMuxHandler{
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+			EnableCompression: true,
+			Subprotocols:      wsSubprotocols,
+		},
+		binanceRunner: binanceRunner,
+		sources:       map[string]*WsSourceCache{},
+	}
+	for _, source := range sources {
+		handler.sources[source.Name()] = source
+	}
+	return handler
+}
+
+type wsMuxSubscription struct {
+	path     string
+	lastSent time.Time
+
+	// The feed for a channel subscription, or the symbol for a symbol
+	// subscription.
+	source *WsSourceCache
+	symbol string
+
+	// Set for channels subscribed to in delta mode.
+	delta *wsDeltaState
+}
+
+type wsMuxSession struct {
+	handler       *WsMuxHandler
+	client        *WebSocketClient
+	path          string
+	commands      chan WsMuxCommand
+	done          chan bool
+	subscriptions map[string]*wsMuxSubscription
+	fields        map[string]bool
+	throttle      time.Duration
+}
+
+func (h *WsMuxHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Infof("Failed to upgrade websocket connection: %v", err)
+		return
+	}
+	client := NewWebSocketClient(conn, r)
+	log.Infof("WebSocket connnected to %s: RemoteAddr=%v; Origin=%s",
+		r.URL.String(),
+		client.GetRemoteAddr(),
+		r.Header.Get("origin"))
+
+	wsConnectionTracker.Add(r.URL.Path, client)
+	defer wsConnectionTracker.Del(r.URL.Path, client)
+
+	session := &wsMuxSession{
+		handler:       h,
+		client:        client,
+		path:          r.URL.Path,
+		commands:      make(chan WsMuxCommand),
+		done:          make(chan bool),
+		subscriptions: map[string]*wsMuxSubscription{},
+	}
+	session.run()
+}
+
+// readLoop decodes commands from the client. Messages that can not be
+// decoded are passed on as a command with an empty name so they can be
+// answered with an error in order. The commands channel is closed when
+// the connection is.
+func (s *wsMuxSession) readLoop() {
+	defer close(s.commands)
+	for {
+		messageType, buf, err := s.client.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var command WsMuxCommand
+		if err := wsDecodeCommand(messageType, buf, &command); err != nil {
+			command = WsMuxCommand{}
+		}
+		select {
+		case s.commands <- command:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// run owns the session state and is the only writer to the connection.
+func (s *wsMuxSession) run() {
+	go s.readLoop()
+
+	defer func() {
+		for key := range s.subscriptions {
+			s.unsubscribe(key)
+		}
+		close(s.done)
+		s.client.conn.Close()
+		log.Infof("WebSocket connection closed: %v", s.client.GetRemoteAddr())
+	}()
+
+	for {
+		select {
+		case command, ok := <-s.commands:
+			if !ok {
+				return
+			}
+			if err := s.write(s.handleCommand(command)); err != nil {
+				return
+			}
+			if command.Cmd == "resync" {
+				if err := s.sendResync(command); err != nil {
+					return
+				}
+			}
+		case <-s.client.queue.Ready():
+			for item, ok := s.client.queue.Pop(); ok; item, ok = s.client.queue.Pop() {
+				if err := s.writeEvent(item, false); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *wsMuxSession) write(v interface{}) error {
+	buf, err := s.client.encoding.Marshal(v)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to encode websocket message")
+		return nil
+	}
+	return s.writeMessage(s.client.encoding.MessageType(), buf, nil)
+}
+
+func (s *wsMuxSession) writeMessage(messageType int, buf []byte, pm *websocket.PreparedMessage) error {
+	if err := s.client.conn.SetWriteDeadline(time.Now().Add(time.Second * 6)); err != nil {
+		log.WithError(err).Warnf("Failed to send websocket write deadline")
+	}
+	var err error
+	if pm != nil {
+		err = s.client.conn.WritePreparedMessage(pm)
+	} else {
+		err = s.client.conn.WriteMessage(messageType, buf)
+	}
+	if err != nil {
+		log.WithError(err).Errorf("Failed to write websocket message to %s",
+			s.client.GetRemoteAddr())
+	}
+	return err
+}
+
+func (s *wsMuxSession) filterEntry(entry interface{}) interface{} {
+	m, ok := entry.(map[string]interface{})
+	if !ok || len(s.fields) == 0 {
+		return entry
+	}
+	filtered := map[string]interface{}{}
+	for key := range s.fields {
+		if value, ok := m[key]; ok {
+			filtered[key] = value
+		}
+	}
+	// Always keep the key identifying the entry.
+	if key, id := wsEntryKey(m); key != "" {
+		filtered[key] = id
+	}
+	return filtered
+}
+
+func (s *wsMuxSession) filterDelta(delta *WsDelta) *WsDelta {
+	filtered := *delta
+	filtered.Changed = []map[string]interface{}{}
+	for _, entry := range delta.Changed {
+		changed := s.filterEntry(entry).(map[string]interface{})
+		if len(changed) > 1 {
+			filtered.Changed = append(filtered.Changed, changed)
+		}
+	}
+	return &filtered
+}
+
+func (s *wsMuxSession) writeChannelUpdate(subscription *wsMuxSubscription, update *WsSourceUpdate) error {
+	if s.client.encoding != WsEncodingJSON {
+		if update.Binary == nil {
+			return nil
+		}
+		return s.writeMessage(websocket.BinaryMessage, nil, update.Binary)
+	}
+	if update.Channel == nil {
+		// Built before this client subscribed.
+		return nil
+	}
+
+	snapshot := true
+	var delta *WsDelta
+	if subscription.delta != nil {
+		delta, snapshot = subscription.delta.next(subscription.source, update)
+		if delta == nil && !snapshot {
+			return nil
+		}
+	}
+
+	if delta != nil {
+		if len(s.fields) > 0 {
+			return s.write(s.filterDelta(delta))
+		}
+		if delta == update.Delta {
+			return s.writeMessage(websocket.TextMessage, nil, update.DeltaMessage)
+		}
+		return s.write(delta)
+	}
+
+	if len(s.fields) == 0 {
+		return s.writeMessage(websocket.TextMessage, nil, update.Channel)
+	}
+	entries := make([]interface{}, 0, len(update.Entries))
+	for _, entry := range update.Entries {
+		entries = append(entries, s.filterEntry(entry))
+	}
+	return s.write(WsMuxChannelMessage{
+		Type:    "channel",
+		Version: TickerSchemaVersion,
+		Channel: subscription.source.Name(),
+		Seq:     update.Seq,
+		Tickers: entries,
+	})
+}
+
+// writeEvent sends an update to the client. Unless forced, updates arriving
+// within the throttle interval of the previous one are skipped.
+func (s *wsMuxSession) writeEvent(event wsQueueItem, force bool) error {
+	subscription, ok := s.subscriptions[event.key]
+	if !ok {
+		// Unsubscribed while the event was queued.
+		return nil
+	}
+	if !force && time.Now().Sub(subscription.lastSent) < s.throttle {
+		return nil
+	}
+	subscription.lastSent = time.Now()
+
+	if event.update != nil {
+		return s.writeChannelUpdate(subscription, event.update)
+	}
+
+	var ticker interface{} = event.entry.Typed
+	if s.client.encoding == WsEncodingJSON {
+		ticker = s.filterEntry(event.entry.Entry)
+	}
+	return s.write(WsMuxSymbolMessage{
+		Type:   "symbol",
+		Symbol: event.symbol,
+		Ticker: ticker,
+	})
+}
+
+// sendResync sends a snapshot of the latest update to each channel in a
+// resync command that was acknowledged.
+func (s *wsMuxSession) sendResync(command WsMuxCommand) error {
+	for _, name := range command.Channels {
+		key := "channel:" + name
+		subscription, ok := s.subscriptions[key]
+		if !ok || subscription.delta == nil || !subscription.delta.resync {
+			continue
+		}
+		update := subscription.source.Last()
+		if update == nil {
+			continue
+		}
+		if err := s.writeEvent(wsQueueItem{key: key, update: update}, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *wsMuxSession) handleCommand(command WsMuxCommand) WsMuxReply {
+	reply := WsMuxReply{
+		Type: "ack",
+		ID:   command.ID,
+		Cmd:  command.Cmd,
+	}
+	var err error
+	switch command.Cmd {
+	case "ping":
+		reply.Type = "pong"
+	case "subscribe":
+		err = s.handleSubscribe(command)
+	case "unsubscribe":
+		err = s.handleUnsubscribe(command)
+	case "set":
+		err = s.handleSet(command)
+	case "resync":
+		err = s.handleResync(command)
+	case "":
+		err = fmt.Errorf("invalid command")
+	default:
+		err = fmt.Errorf("unknown command: %s", command.Cmd)
+	}
+	if err != nil {
+		reply.Type = "error"
+		reply.Error = err.Error()
+		return reply
+	}
+	if command.Cmd == "subscribe" || command.Cmd == "unsubscribe" {
+		reply.Channels, reply.Symbols = s.subscribed()
+	}
+	return reply
+}
+
+// Validate the whole command before subscribing to anything so a failed
+// command has no effect.
+func (s *wsMuxSession) handleSubscribe(command WsMuxCommand) error {
+	if len(command.Channels) == 0 && len(command.Symbols) == 0 {
+		return fmt.Errorf("no channels or symbols")
+	}
+	if command.Delta && s.client.encoding != WsEncodingJSON {
+		return fmt.Errorf("delta mode requires json encoding")
+	}
+	for _, name := range command.Channels {
+		if _, ok := s.handler.sources[name]; !ok {
+			return fmt.Errorf("unknown channel: %s", name)
+		}
+	}
+	for _, symbol := range command.Symbols {
+		if !s.handler.binanceRunner.universe.Contains(symbol) {
+			return fmt.Errorf("unknown symbol: %s", symbol)
+		}
+	}
+	for _, name := range command.Channels {
+		s.subscribeChannel(name, command.Delta)
+	}
+	for _, symbol := range command.Symbols {
+		s.subscribeSymbol(strings.ToUpper(symbol))
+	}
+	return nil
+}
+
+func (s *wsMuxSession) handleUnsubscribe(command WsMuxCommand) error {
+	if len(command.Channels) == 0 && len(command.Symbols) == 0 {
+		return fmt.Errorf("no channels or symbols")
+	}
+	for _, name := range command.Channels {
+		s.unsubscribe("channel:" + name)
+	}
+	for _, symbol := range command.Symbols {
+		s.unsubscribe("symbol:" + strings.ToUpper(symbol))
+	}
+	return nil
+}
+
+func (s *wsMuxSession) handleSet(command WsMuxCommand) error {
+	if command.Fields == nil && command.Throttle == nil {
+		return fmt.Errorf("nothing to set")
+	}
+
+	// Nothing is changed unless all of the settings are valid.
+	if command.Throttle != nil && *command.Throttle < 0 {
+		return fmt.Errorf("invalid throttle: %d", *command.Throttle)
+	}
+	if len(command.Fields) > 0 && s.client.encoding != WsEncodingJSON {
+		return fmt.Errorf("field selection requires json encoding")
+	}
+
+	if command.Throttle != nil {
+		s.throttle = time.Second * time.Duration(*command.Throttle)
+	}
+	if command.Fields != nil {
+		s.fields = map[string]bool{}
+		for _, field := range command.Fields {
+			s.fields[field] = true
+		}
+	}
+	return nil
+}
+
+func (s *wsMuxSession) handleResync(command WsMuxCommand) error {
+	if len(command.Channels) == 0 {
+		return fmt.Errorf("no channels")
+	}
+	for _, name := range command.Channels {
+		subscription, ok := s.subscriptions["channel:"+name]
+		if !ok || subscription.delta == nil {
+			return fmt.Errorf("not subscribed in delta mode: %s", name)
+		}
+	}
+	for _, name := range command.Channels {
+		s.subscriptions["channel:"+name].delta.resync = true
+	}
+	return nil
+}
+
+func (s *wsMuxSession) subscribed() ([]string, []string) {
+	channels := []string{}
+	symbols := []string{}
+	for key := range s.subscriptions {
+		if strings.HasPrefix(key, "channel:") {
+			channels = append(channels, strings.TrimPrefix(key, "channel:"))
+		} else {
+			symbols = append(symbols, strings.TrimPrefix(key, "symbol:"))
+		}
+	}
+	return channels, symbols
+}
+
+func (s *wsMuxSession) addSubscription(key string, path string) *wsMuxSubscription {
+	subscription := &wsMuxSubscription{
+		path: path,
+	}
+	s.subscriptions[key] = subscription
+	wsConnectionTracker.Add(path, s.client)
+	return subscription
+}
+
+func (s *wsMuxSession) subscribeChannel(name string, delta bool) {
+	key := "channel:" + name
+	if subscription, exists := s.subscriptions[key]; exists {
+		if delta && subscription.delta == nil {
+			subscription.delta = &wsDeltaState{}
+		} else if !delta {
+			subscription.delta = nil
+		}
+		return
+	}
+	source := s.handler.sources[name]
+	subscription := s.addSubscription(key, fmt.Sprintf("%s#%s", s.path, name))
+	subscription.source = source
+	if delta {
+		subscription.delta = &wsDeltaState{}
+	}
+	source.Subscribe(s.client.queue, key)
+}
+
+func (s *wsMuxSession) subscribeSymbol(symbol string) {
+	key := "symbol:" + symbol
+	if _, exists := s.subscriptions[key]; exists {
+		return
+	}
+	subscription := s.addSubscription(key, fmt.Sprintf("%s#symbol=%s", s.path, symbol))
+	subscription.symbol = symbol
+	s.handler.binanceRunner.SubscribeSymbol(symbol, s.client.queue, key)
+}
+
+// unsubscribe stops a subscription. Updates for it that are still queued
+// are skipped by writeEvent.
+func (s *wsMuxSession) unsubscribe(key string) {
+	subscription, ok := s.subscriptions[key]
+	if !ok {
+		return
+	}
+	if subscription.source != nil {
+		subscription.source.Unsubscribe(s.client.queue, key)
+	} else {
+		s.handler.binanceRunner.UnsubscribeSymbol(subscription.symbol, s.client.queue, key)
+	}
+	delete(s.subscriptions, key)
+	wsConnectionTracker.Del(subscription.path, s.client)
+}
@@ -0,0 +1,71 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWsMuxSet checks that a set command changes nothing unless all of its
+// settings are valid.
+func TestWsMuxSet(t *testing.T) {
+	throttle := func(seconds int64) *int64 {
+		return &seconds
+	}
+	tests := []struct {
+		encoding WsEncoding
+		command  WsMuxCommand
+		valid    bool
+	}{
+		{WsEncodingMsgpack, WsMuxCommand{Throttle: throttle(5), Fields: []string{"symbol"}}, false},
+		{WsEncodingJSON, WsMuxCommand{Throttle: throttle(-1), Fields: []string{"symbol"}}, false},
+		{WsEncodingJSON, WsMuxCommand{}, false},
+		{WsEncodingJSON, WsMuxCommand{Throttle: throttle(5), Fields: []string{"symbol"}}, true},
+		{WsEncodingMsgpack, WsMuxCommand{Throttle: throttle(5)}, true},
+	}
+	for _, test := range tests {
+		session := &wsMuxSession{
+			client:   &WebSocketClient{encoding: test.encoding},
+			throttle: time.Second,
+		}
+		err := session.handleSet(test.command)
+		if test.valid {
+			if err != nil {
+				t.Errorf("%+v: unexpected error: %v", test.command, err)
+			}
+			if session.throttle != 5*time.Second {
+				t.Errorf("%+v: throttle not set", test.command)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%+v: expected an error", test.command)
+		}
+		if session.throttle != time.Second || session.fields != nil {
+			t.Errorf("%+v: settings changed despite the error", test.command)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018-2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package server
+
+import (
+	"fmt"
+	"gitlab.com/crankykernel/cryptoxscanner/log"
+	"sync"
+	"sync/atomic"
+)
+
+// Messages for a WebSocket client are queued per client so a slow client
+// does not hold up the feeds. The depth is per subscription, so a client
+// multiplexing many symbols has room for as many updates of each as a
+// client of one feed. When a subscription has a full queue either its
+// oldest message is dropped, or the client is disconnected.
+
+type WsQueuePolicy int
+
+const (
+	WsQueueDropOldest WsQueuePolicy = iota
+	WsQueueDisconnect
+)
+
+func (p WsQueuePolicy) String() string {
+	if p == WsQueueDisconnect {
+		return "disconnect"
+	}
+	return "drop-oldest"
+}
+
+func ParseWsQueuePolicy(value string) (WsQueuePolicy, error) {
+	switch value {
+	case "", "drop-oldest":
+		return WsQueueDropOldest, nil
+	case "disconnect":
+		return WsQueueDisconnect, nil
+	}
+	return WsQueueDropOldest, fmt.Errorf("invalid queue policy: %s", value)
+}
+
+type WsQueueOptions struct {
+	Depth  int
+	Policy WsQueuePolicy
+}
+
+var DefaultWsQueueOptions = WsQueueOptions{
+	Depth:  8,
+	Policy: WsQueueDropOldest,
+}
+
+// The queue options for new clients, set from the server options.
+var wsQueueOptions = DefaultWsQueueOptions
+
+// wsQueueItem is a message waiting to be written to a client, tagged with
+// the key of the subscription it is for.
+type wsQueueItem struct {
+	key    string
+	update *WsSourceUpdate
+	symbol string
+	entry  *WsSymbolUpdate
+
+	// A message that is encoded and sent as is.
+	message interface{}
+}
+
+// wsSubscriber identifies a subscription of a client to a feed.
+type wsSubscriber struct {
+	queue *WsSendQueue
+	key   string
+}
+
+// WsSendQueue holds the messages of the feeds a receiver has subscribed
+// to until it sends them on. The receiver is usually a WebSocketClient, see
+// WebSocketClient.newQueue.
+type WsSendQueue struct {
+	options    WsQueueOptions
+	lock       sync.Mutex
+	items      []wsQueueItem
+	queued     map[string]int
+	ready      chan bool
+	overflowed bool
+	closed     bool
+
+	// Number of messages dropped, updated atomically.
+	dropped uint64
+
+	// Name of the receiver in log messages.
+	name string
+
+	// The encoding the receiver sends, which decides the encodings the
+	// feeds build.
+	encoding WsEncoding
+
+	// Disconnects the receiver when the queue overflows under the
+	// disconnect policy, nil if there is nothing to disconnect.
+	disconnect func()
+}
+
+func NewWsSendQueue(name string, options WsQueueOptions) *WsSendQueue {
+	if options.Depth < 1 {
+		options.Depth = 1
+	}
+	return &WsSendQueue{
+		name:    name,
+		options: options,
+		queued:  map[string]int{},
+		ready:   make(chan bool, 1),
+	}
+}
+
+// Push adds an item to the queue. If the queue of its subscription is full
+// the oldest item of the subscription is dropped or, depending on the
+// policy, the client is disconnected.
+func (q *WsSendQueue) Push(item wsQueueItem) {
+	q.lock.Lock()
+	if q.overflowed || q.closed {
+		q.lock.Unlock()
+		return
+	}
+	if q.queued[item.key] >= q.options.Depth {
+		atomic.AddUint64(&q.dropped, 1)
+		if q.options.Policy == WsQueueDisconnect {
+			q.overflowed = true
+			q.items = nil
+			q.queued = map[string]int{}
+			q.lock.Unlock()
+			if q.disconnect != nil {
+				log.Warnf("Disconnecting slow websocket client %s", q.name)
+				q.disconnect()
+			}
+			return
+		}
+		for i := range q.items {
+			if q.items[i].key == item.key {
+				q.items = append(q.items[:i], q.items[i+1:]...)
+				break
+			}
+		}
+		q.queued[item.key]--
+	}
+	q.items = append(q.items, item)
+	q.queued[item.key]++
+	q.lock.Unlock()
+
+	select {
+	case q.ready <- true:
+	default:
+	}
+}
+
+// Pop removes the oldest item from the queue.
+func (q *WsSendQueue) Pop() (wsQueueItem, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.items) == 0 {
+		return wsQueueItem{}, false
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	if q.queued[item.key]--; q.queued[item.key] == 0 {
+		delete(q.queued, item.key)
+	}
+	return item, true
+}
+
+// Ready receives a value after items have been pushed. All items should be
+// popped before waiting on it again.
+func (q *WsSendQueue) Ready() <-chan bool {
+	return q.ready
+}
+
+func (q *WsSendQueue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.items)
+}
+
+func (q *WsSendQueue) Dropped() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
+
+// Overflowed returns true if the client is disconnected for falling
+// behind.
+func (q *WsSendQueue) Overflowed() bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.overflowed
+}
+
+// Close empties the queue once the client has gone, later pushes are
+// ignored.
+func (q *WsSendQueue) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.closed = true
+	q.items = nil
+	q.queued = map[string]int{}
+}
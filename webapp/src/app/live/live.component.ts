@@ -438,7 +438,7 @@ export class BinanceLiveComponent implements OnInit, OnDestroy {
             },
             {
                 title: "RSI 1m",
-                name: "rsi_60",
+                name: "rsi_1m",
                 type: "number",
                 format: ".2-2",
                 display: true,
@@ -446,7 +446,7 @@ export class BinanceLiveComponent implements OnInit, OnDestroy {
             },
             {
                 title: "RSI 3m",
-                name: "rsi_180",
+                name: "rsi_3m",
                 type: "number",
                 format: ".2-2",
                 display: true,
@@ -454,7 +454,7 @@ export class BinanceLiveComponent implements OnInit, OnDestroy {
             },
             {
                 title: "RSI 5m",
-                name: "rsi_300",
+                name: "rsi_5m",
                 type: "number",
                 format: ".2-2",
                 display: true,
@@ -462,7 +462,7 @@ export class BinanceLiveComponent implements OnInit, OnDestroy {
             },
             {
                 title: "RSI 15m",
-                name: "rsi_900",
+                name: "rsi_15m",
                 type: "number",
                 format: ".2-2",
                 display: true,
@@ -781,7 +781,7 @@ export class BinanceLiveComponent implements OnInit, OnDestroy {
             }
 
             if (maxRsi60) {
-                if (ticker.rsi_60 && ticker.rsi_60 > maxRsi60) {
+                if (ticker.rsi_1m && ticker.rsi_1m > maxRsi60) {
                     return false;
                 }
             }
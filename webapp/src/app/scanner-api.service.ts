@@ -160,5 +160,5 @@ export interface SymbolUpdate {
     timestamp: string;
     volume: number;
 
-    rsi_60?: number;
+    rsi_1m?: number;
 }